@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type auditReport struct {
+	Flavor      client.Flavor     `json:"flavor"`
+	Version     string            `json:"version"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Findings    []*client.Finding `json:"findings"`
+}
+
+// newAuditCommand returns the audit subcommand, which prints the security findings for every account as JSON.
+func newAuditCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit",
+		Short: "Report anonymous users, wildcard hosts, empty passwords and other risky accounts as JSON",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newCommandClient(ctx, cmd, v)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			findings, err := c.Audit(ctx)
+			if err != nil {
+				return err
+			}
+			if findings == nil {
+				findings = []*client.Finding{}
+			}
+
+			caps := c.Capabilities()
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(&auditReport{
+				Flavor:      caps.Flavor,
+				Version:     caps.Version,
+				GeneratedAt: time.Now().UTC(),
+				Findings:    findings,
+			})
+		},
+	}
+}
+
+// newCommandClient connects to the server for a subcommand, reading the connection string from the flags or
+// the environment.
+func newCommandClient(ctx context.Context, cmd *cobra.Command, v *viper.Viper) (*client.Client, error) {
+	err := v.BindPFlags(cmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := v.GetString(ConnectionString.FieldName)
+	if dsn == "" {
+		return nil, fmt.Errorf("%s is required", ConnectionString.FieldName)
+	}
+
+	c, err := client.New(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	c.SetRetryPolicy(retryPolicy(v))
+
+	guards, err := client.NewSafeguards(v.GetStringSlice(ProtectedAccounts.FieldName), v.GetStringSlice(DeniedPrivileges.FieldName))
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	c.SetSafeguards(guards)
+	return c, nil
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
@@ -27,13 +31,136 @@ var (
 		field.WithDefaultValue(false),
 		field.WithRequired(false),
 	)
+	UsageStats = field.BoolField(
+		"usage-stats",
+		field.WithDescription("Annotate user grants with statement usage from performance_schema $(BATON_USAGE_STATS)"),
+		field.WithDefaultValue(false),
+		field.WithRequired(false),
+	)
+	AccountActivity = field.BoolField(
+		"account-activity",
+		field.WithDescription("Report user connections and last login from performance_schema and flag stale accounts $(BATON_ACCOUNT_ACTIVITY)"),
+		field.WithDefaultValue(false),
+		field.WithRequired(false),
+	)
+	StaleAfterDays = field.IntField(
+		"stale-after-days",
+		field.WithDescription("Flag accounts as stale when they have not logged in for this many days $(BATON_STALE_AFTER_DAYS)"),
+		field.WithDefaultValue(90),
+		field.WithRequired(false),
+	)
+	BinlogSource = field.StringField(
+		"binlog-source",
+		field.WithDescription(`Read privilege change events from the binary log: "server", or a directory of binary log files $(BATON_BINLOG_SOURCE)`),
+		field.WithRequired(false),
+	)
+	BinlogStart = field.StringField(
+		"binlog-start",
+		field.WithDescription("The binary log position to start reading events from, like mysql-bin.000042:1234 $(BATON_BINLOG_START)"),
+		field.WithRequired(false),
+	)
+	AuditLogFile = field.StringField(
+		"audit-log-file",
+		field.WithDescription("Read login and privilege change events from this JSON audit log file $(BATON_AUDIT_LOG_FILE)"),
+		field.WithRequired(false),
+	)
+	ConsistentSnapshot = field.BoolField(
+		"consistent-snapshot",
+		field.WithDescription("Read each sync from a consistent snapshot of the grant tables $(BATON_CONSISTENT_SNAPSHOT)"),
+		field.WithDefaultValue(false),
+		field.WithRequired(false),
+	)
+	DryRun = field.BoolField(
+		"dry-run",
+		field.WithDescription("Log the SQL of grants, revokes, account creation and deletion instead of running it $(BATON_DRY_RUN)"),
+		field.WithDefaultValue(false),
+		field.WithRequired(false),
+	)
+	ProtectedAccounts = field.StringSliceField(
+		"protected-accounts",
+		field.WithDescription("Never change or delete accounts matching these user@host patterns $(BATON_PROTECTED_ACCOUNTS)"),
+		field.WithDefaultValue(client.DefaultProtectedAccounts),
+		field.WithRequired(false),
+	)
+	DeniedPrivileges = field.StringSliceField(
+		"denied-privileges",
+		field.WithDescription("Never grant these privileges, like SUPER or GRANT OPTION $(BATON_DENIED_PRIVILEGES)"),
+		field.WithRequired(false),
+	)
+	ReadOnly = field.BoolField(
+		"read-only",
+		field.WithDescription("Only sync, without the capabilities to grant, revoke, create or delete accounts $(BATON_READ_ONLY)"),
+		field.WithDefaultValue(false),
+		field.WithRequired(false),
+	)
+	SkipGrantSources = field.StringSliceField(
+		"skip-grant-sources",
+		field.WithDescription("Skip reading these optional grant sources: dynamic, column, routine, proxy or role $(BATON_SKIP_GRANT_SOURCES)"),
+		field.WithRequired(false),
+	)
+	RetryMaxAttempts = field.IntField(
+		"retry-max-attempts",
+		field.WithDescription("Attempt queries and statements that fail with a transient error up to this many times $(BATON_RETRY_MAX_ATTEMPTS)"),
+		field.WithDefaultValue(client.DefaultRetryPolicy.MaxAttempts),
+		field.WithRequired(false),
+	)
+	RetryBackoffMs = field.IntField(
+		"retry-backoff-ms",
+		field.WithDescription("Wait this many milliseconds before the first retry, doubling for each retry after it $(BATON_RETRY_BACKOFF_MS)"),
+		field.WithDefaultValue(int(client.DefaultRetryPolicy.InitialBackoff/time.Millisecond)),
+		field.WithRequired(false),
+	)
+	RetryMaxBackoffMs = field.IntField(
+		"retry-max-backoff-ms",
+		field.WithDescription("Wait at most this many milliseconds between retries $(BATON_RETRY_MAX_BACKOFF_MS)"),
+		field.WithDefaultValue(int(client.DefaultRetryPolicy.MaxBackoff/time.Millisecond)),
+		field.WithRequired(false),
+	)
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
-	ConfigurationFields = []field.SchemaField{ConnectionString, SkipDatabases, ExpandColumns, CollapseUsers}
+	ConfigurationFields = []field.SchemaField{
+		ConnectionString,
+		SkipDatabases,
+		ExpandColumns,
+		CollapseUsers,
+		UsageStats,
+		AccountActivity,
+		StaleAfterDays,
+		BinlogSource,
+		BinlogStart,
+		AuditLogFile,
+		ConsistentSnapshot,
+		DryRun,
+		ProtectedAccounts,
+		DeniedPrivileges,
+		ReadOnly,
+		SkipGrantSources,
+		RetryMaxAttempts,
+		RetryBackoffMs,
+		RetryMaxBackoffMs,
+	}
 )
 
 // validateConfig is run after the configuration is loaded, and should return an error if it isn't valid.
 func ValidateConfig(v *viper.Viper) error {
+	if v.GetInt(StaleAfterDays.FieldName) < 0 {
+		return fmt.Errorf("stale-after-days must not be negative")
+	}
+	for _, f := range []field.SchemaField{RetryMaxAttempts, RetryBackoffMs, RetryMaxBackoffMs} {
+		if v.GetInt(f.FieldName) < 0 {
+			return fmt.Errorf("%s must not be negative", f.FieldName)
+		}
+	}
+
 	return nil
 }
+
+// retryPolicy returns the retry policy configured by the retry fields.
+func retryPolicy(v *viper.Viper) client.RetryPolicy {
+	return client.RetryPolicy{
+		MaxAttempts:    v.GetInt(RetryMaxAttempts.FieldName),
+		InitialBackoff: time.Duration(v.GetInt(RetryBackoffMs.FieldName)) * time.Millisecond,
+		MaxBackoff:     time.Duration(v.GetInt(RetryMaxBackoffMs.FieldName)) * time.Millisecond,
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newExportGrantsCommand returns the export-grants subcommand, which prints the CREATE USER, CREATE ROLE and GRANT
+// statements that rebuild the accounts on the server and their privileges.
+func newExportGrantsCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-grants",
+		Short: "Print the SQL statements that recreate every account and its privileges",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			databases, err := cmd.Flags().GetStringSlice("database")
+			if err != nil {
+				return err
+			}
+			users, err := cmd.Flags().GetStringSlice("user")
+			if err != nil {
+				return err
+			}
+			passwords, err := cmd.Flags().GetBool("include-passwords")
+			if err != nil {
+				return err
+			}
+
+			c, err := newCommandClient(ctx, cmd, v)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			exports, err := c.ExportGrants(ctx, &client.ExportFilter{
+				Databases: databases,
+				Users:     users,
+				Passwords: passwords,
+			})
+			if err != nil {
+				return err
+			}
+
+			caps := c.Capabilities()
+			return writeExport(cmd.OutOrStdout(), exports, caps.Flavor, caps.Version, time.Now().UTC())
+		},
+	}
+	cmd.Flags().StringSlice("database", nil, "Only export privileges in these databases")
+	cmd.Flags().StringSlice("user", nil, "Only export these accounts, given as user or user@host")
+	cmd.Flags().Bool("include-passwords", false, "Include password hashes; otherwise password accounts are created locked")
+
+	return cmd
+}
+
+// writeExport writes the accounts as an SQL script: every account is created before any privileges are granted,
+// so grants of roles to other accounts can be replayed in order.
+func writeExport(w io.Writer, exports []*client.AccountExport, flavor client.Flavor, version string, at time.Time) error {
+	_, err := fmt.Fprintf(w, "-- Exported from %s %s at %s\n", flavor, version, at.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	if len(exports) > 0 {
+		_, err = fmt.Fprintln(w)
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range exports {
+		_, err = fmt.Fprintf(w, "%s;\n", e.Create)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range exports {
+		if len(e.Grants) == 0 {
+			continue
+		}
+		_, err = fmt.Fprintf(w, "\n-- %s@%s\n", e.User, e.Host)
+		if err != nil {
+			return err
+		}
+		for _, g := range e.Grants {
+			_, err = fmt.Fprintf(w, "%s;\n", g)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/conductorone/baton-mysql/pkg/client"
 	"github.com/conductorone/baton-mysql/pkg/connector"
 	"github.com/conductorone/baton-sdk/pkg/config"
-	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	sdkTypes "github.com/conductorone/baton-sdk/pkg/types"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
@@ -20,7 +21,7 @@ var version = "dev"
 func main() {
 	ctx := context.Background()
 
-	_, cmd, err := config.DefineConfiguration(
+	v, cmd, err := config.DefineConfiguration(
 		ctx,
 		"baton-mysql",
 		getConnector,
@@ -41,6 +42,77 @@ func main() {
 		`Provide a table like db.table to expand the column privileges into their own entitlements. $(BATON_EXPAND_COLUMNS)`,
 	)
 	cmd.PersistentFlags().Bool("collapse-users", false, "Combine user@host pairs into a single user@[hosts...] identity $(BATON_COLLAPSE_USERS)")
+	cmd.PersistentFlags().Bool("usage-stats", false, "Annotate user grants with statement usage from performance_schema $(BATON_USAGE_STATS)")
+	cmd.PersistentFlags().Bool(
+		"account-activity",
+		false,
+		"Report user connections and last login from performance_schema and flag stale accounts $(BATON_ACCOUNT_ACTIVITY)",
+	)
+	cmd.PersistentFlags().Int("stale-after-days", 90, "Flag accounts as stale when they have not logged in for this many days $(BATON_STALE_AFTER_DAYS)")
+	cmd.PersistentFlags().String(
+		"binlog-source",
+		"",
+		`Read privilege change events from the binary log: "server", or a directory of binary log files $(BATON_BINLOG_SOURCE)`,
+	)
+	cmd.PersistentFlags().String(
+		"binlog-start",
+		"",
+		"The binary log position to start reading events from, like mysql-bin.000042:1234 $(BATON_BINLOG_START)",
+	)
+	cmd.PersistentFlags().String(
+		"audit-log-file",
+		"",
+		"Read login and privilege change events from this JSON audit log file $(BATON_AUDIT_LOG_FILE)",
+	)
+	cmd.PersistentFlags().Bool(
+		"consistent-snapshot",
+		false,
+		"Read each sync from a consistent snapshot of the grant tables $(BATON_CONSISTENT_SNAPSHOT)",
+	)
+	cmd.PersistentFlags().Bool(
+		"dry-run",
+		false,
+		"Log the SQL of grants, revokes, account creation and deletion instead of running it $(BATON_DRY_RUN)",
+	)
+	cmd.PersistentFlags().StringSlice(
+		"protected-accounts",
+		client.DefaultProtectedAccounts,
+		"Never change or delete accounts matching these user@host patterns $(BATON_PROTECTED_ACCOUNTS)",
+	)
+	cmd.PersistentFlags().StringSlice(
+		"denied-privileges",
+		nil,
+		"Never grant these privileges, like SUPER or GRANT OPTION $(BATON_DENIED_PRIVILEGES)",
+	)
+	cmd.PersistentFlags().Bool(
+		"read-only",
+		false,
+		"Only sync, without the capabilities to grant, revoke, create or delete accounts $(BATON_READ_ONLY)",
+	)
+	cmd.PersistentFlags().StringSlice(
+		"skip-grant-sources",
+		nil,
+		"Skip reading these optional grant sources: dynamic, column, routine, proxy or role $(BATON_SKIP_GRANT_SOURCES)",
+	)
+	cmd.PersistentFlags().Int(
+		"retry-max-attempts",
+		client.DefaultRetryPolicy.MaxAttempts,
+		"Attempt queries and statements that fail with a transient error up to this many times $(BATON_RETRY_MAX_ATTEMPTS)",
+	)
+	cmd.PersistentFlags().Int(
+		"retry-backoff-ms",
+		int(client.DefaultRetryPolicy.InitialBackoff/time.Millisecond),
+		"Wait this many milliseconds before the first retry, doubling for each retry after it $(BATON_RETRY_BACKOFF_MS)",
+	)
+	cmd.PersistentFlags().Int(
+		"retry-max-backoff-ms",
+		int(client.DefaultRetryPolicy.MaxBackoff/time.Millisecond),
+		"Wait at most this many milliseconds between retries $(BATON_RETRY_MAX_BACKOFF_MS)",
+	)
+	cmd.AddCommand(newAuditCommand(ctx, v))
+	cmd.AddCommand(newWhichAccountCommand(ctx, v))
+	cmd.AddCommand(newExportGrantsCommand(ctx, v))
+	cmd.AddCommand(newReconcileCommand(ctx, v))
 	err = cmd.Execute()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -54,13 +126,32 @@ func getConnector(ctx context.Context, v *viper.Viper) (sdkTypes.ConnectorServer
 		return nil, err
 	}
 
-	cb, err := connector.New(ctx, v.GetString(ConnectionString.FieldName), v.GetStringSlice(SkipDatabases.FieldName), v.GetStringSlice(ExpandColumns.FieldName), v.GetBool(CollapseUsers.FieldName))
+	cb, err := connector.New(
+		ctx,
+		v.GetString(ConnectionString.FieldName),
+		v.GetStringSlice(SkipDatabases.FieldName),
+		v.GetStringSlice(ExpandColumns.FieldName),
+		v.GetBool(CollapseUsers.FieldName),
+		v.GetBool(UsageStats.FieldName),
+		v.GetBool(AccountActivity.FieldName),
+		time.Duration(v.GetInt(StaleAfterDays.FieldName))*24*time.Hour,
+		v.GetString(BinlogSource.FieldName),
+		v.GetString(BinlogStart.FieldName),
+		v.GetString(AuditLogFile.FieldName),
+		v.GetBool(ConsistentSnapshot.FieldName),
+		v.GetBool(DryRun.FieldName),
+		v.GetStringSlice(ProtectedAccounts.FieldName),
+		v.GetStringSlice(DeniedPrivileges.FieldName),
+		v.GetBool(ReadOnly.FieldName),
+		v.GetStringSlice(SkipGrantSources.FieldName),
+		retryPolicy(v),
+	)
 	if err != nil {
 		l.Error("error creating connector builder", zap.Error(err))
 		return nil, err
 	}
 
-	c, err := connectorbuilder.NewConnector(ctx, cb)
+	c, err := connector.NewServer(ctx, cb)
 	if err != nil {
 		l.Error("error creating connector from connector builder", zap.Error(err))
 		return nil, err
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newReconcileCommand returns the reconcile subcommand, which prints the statements that bring the server to the
+// desired state in a YAML file, and runs them with --apply.
+func newReconcileCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Plan, and with --apply make, the changes that bring users, roles and privileges to a desired state",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := cmd.Flags().GetString("desired-state")
+			if err != nil {
+				return err
+			}
+			if file == "" {
+				return fmt.Errorf("--desired-state is required")
+			}
+			apply, err := cmd.Flags().GetBool("apply")
+			if err != nil {
+				return err
+			}
+
+			desired, err := client.LoadDesiredState(file)
+			if err != nil {
+				return err
+			}
+
+			c, err := newCommandClient(ctx, cmd, v)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			plan, err := c.PlanReconcile(ctx, desired)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			err = writePlan(out, plan)
+			if err != nil {
+				return err
+			}
+			if !apply || len(plan.Changes) == 0 {
+				return nil
+			}
+
+			err = plan.Apply(ctx)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(out, "\n-- Applied %d changes.\n", len(plan.Changes))
+			if err != nil {
+				return err
+			}
+			for _, ch := range plan.Changes {
+				if ch.Password == "" {
+					continue
+				}
+				_, err = fmt.Fprintf(out, "-- %s: generated password %s\n", ch.Account, ch.Password)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("desired-state", "", "The YAML file with the users, roles and privileges the server should have")
+	cmd.Flags().Bool("apply", false, "Make the planned changes instead of only printing them")
+
+	return cmd
+}
+
+// writePlan writes each change of the plan as a comment followed by its statements.
+func writePlan(w io.Writer, plan *client.ReconcilePlan) error {
+	if len(plan.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "-- No changes: the server matches the desired state.")
+		return err
+	}
+
+	for _, ch := range plan.Changes {
+		_, err := fmt.Fprintf(w, "-- %s: %s\n", ch.Account, ch.Description)
+		if err != nil {
+			return err
+		}
+		for _, s := range ch.Statements {
+			_, err = fmt.Fprintf(w, "%s;\n", s)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newWhichAccountCommand returns the which-account subcommand, which shows the user@host row the server
+// authenticates a client as, along with the rows it tries before it.
+func newWhichAccountCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which-account",
+		Short: "Show which user@host account a client authenticates as",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			user, err := cmd.Flags().GetString("user")
+			if err != nil {
+				return err
+			}
+			from, err := cmd.Flags().GetString("from")
+			if err != nil {
+				return err
+			}
+			if from == "" {
+				return fmt.Errorf("--from is required")
+			}
+
+			c, err := newCommandClient(ctx, cmd, v)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = c.Close() }()
+
+			match, err := c.WhichAccount(ctx, user, from)
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(match)
+		},
+	}
+	cmd.Flags().String("user", "", "The user name the client connects with")
+	cmd.Flags().String("from", "", "The IP address or host name the client connects from")
+
+	return cmd
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// AccountActivity is the login activity of an account. The server reports connections by the client's user name
+// and host, so they are attributed to the account the client authenticated as.
+type AccountActivity struct {
+	User               string
+	Host               string
+	CurrentConnections int64
+	TotalConnections   int64
+	// LastConnection is the most recent connection recorded in mysql.general_log, or nil when unknown.
+	LastConnection *time.Time
+	// FailedLogins is the number of consecutive failed logins tracked by the connection_control plugin.
+	FailedLogins int64
+}
+
+// ID returns the user@host of the account.
+func (a *AccountActivity) ID() string {
+	return fmt.Sprintf("%s@%s", a.User, a.Host)
+}
+
+type connectionStats struct {
+	User               string `db:"USER"`
+	Host               string `db:"HOST"`
+	CurrentConnections int64  `db:"CURRENT_CONNECTIONS"`
+	TotalConnections   int64  `db:"TOTAL_CONNECTIONS"`
+}
+
+type connectLogEntry struct {
+	UserHost string `db:"user_host"`
+	// EventTime is a Unix timestamp, so it does not depend on the session time zone or the parseTime DSN option.
+	EventTime int64 `db:"event_time"`
+}
+
+type failedLogins struct {
+	UserHost string `db:"USERHOST"`
+	Attempts int64  `db:"FAILED_ATTEMPTS"`
+}
+
+// activityIndex attributes connections from a client user name and host to the account the server matches.
+type activityIndex struct {
+	accounts []*User
+	activity map[string]*AccountActivity
+}
+
+func newActivityIndex(accounts []*User) *activityIndex {
+	return &activityIndex{
+		accounts: accounts,
+		activity: make(map[string]*AccountActivity),
+	}
+}
+
+// account returns the activity of the account matched by user connecting from the client's host name or IP
+// address. The server tries both against each account, so the most specific account matching either wins. It
+// returns nil when no account matches.
+func (ai *activityIndex) account(user string, hosts ...string) *AccountActivity {
+	var match *AccountCandidate
+	for _, from := range hosts {
+		if from == "" {
+			continue
+		}
+		m := MatchAccount(ai.accounts, user, from)
+		if m.Account == nil {
+			continue
+		}
+		if match == nil || CompareAccounts(m.Account.User, m.Account.Host, match.User, match.Host) < 0 {
+			match = m.Account
+		}
+	}
+	if match == nil {
+		return nil
+	}
+
+	a := &AccountActivity{User: match.User, Host: match.Host}
+	if existing, ok := ai.activity[a.ID()]; ok {
+		return existing
+	}
+	ai.activity[a.ID()] = a
+	return a
+}
+
+// generalLogUserHost matches the user_host column of mysql.general_log, like "app[app] @ web1 [10.0.0.5]".
+var generalLogUserHost = regexp.MustCompile(`^[^\[]*\[([^\]]*)\] @ ([^ \[]*) ?\[([^\]]*)\]`)
+
+// parseGeneralLogUserHost returns the user name the client sent and its host name and IP address.
+func parseGeneralLogUserHost(userHost string) (string, string, string, bool) {
+	m := generalLogUserHost.FindStringSubmatch(strings.TrimSpace(userHost))
+	if m == nil {
+		return "", "", "", false
+	}
+
+	return m[1], m[2], m[3], true
+}
+
+// parseQuotedUserHost parses a user and host written as 'user'@'host'.
+func parseQuotedUserHost(userHost string) (string, string, bool) {
+	i := strings.LastIndex(userHost, "@")
+	if i < 0 {
+		return "", "", false
+	}
+
+	return strings.Trim(userHost[:i], "'`"), strings.Trim(userHost[i+1:], "'`"), true
+}
+
+// ListAccountActivity returns the login activity of each account that has connected since the server started,
+// keyed by user@host. Connection counts come from performance_schema.accounts. The last connection time is read
+// from mysql.general_log and failed logins from the connection_control plugin when they are available; failing
+// to read them is not an error.
+// Required MySQL grant for connector:
+//
+//	GRANT SELECT ON performance_schema.accounts TO user@host;
+//
+// Optional MySQL grants for connector:
+//
+//	GRANT SELECT (user_host, event_time, command_type) ON mysql.general_log TO user@host;
+//	GRANT CONNECTION_ADMIN ON *.* TO user@host;
+func (c *Client) ListAccountActivity(ctx context.Context) (map[string]*AccountActivity, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing account activity")
+
+	sb, err := c.getUsersQuery()
+	if err != nil {
+		return nil, err
+	}
+	var accounts []*User
+	err = c.pool().SelectContext(ctx, &accounts, sb.String())
+	if err != nil {
+		return nil, err
+	}
+	index := newActivityIndex(accounts)
+
+	var stats []*connectionStats
+	err = c.pool().SelectContext(
+		ctx,
+		&stats,
+		`SELECT USER, HOST, CURRENT_CONNECTIONS, TOTAL_CONNECTIONS FROM performance_schema.accounts
+			WHERE USER IS NOT NULL AND HOST IS NOT NULL`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stats {
+		a := index.account(s.User, s.Host)
+		if a == nil {
+			continue
+		}
+		a.CurrentConnections += s.CurrentConnections
+		a.TotalConnections += s.TotalConnections
+	}
+
+	var connects []*connectLogEntry
+	err = c.pool().SelectContext(
+		ctx,
+		&connects,
+		`SELECT user_host, CAST(UNIX_TIMESTAMP(MAX(event_time)) AS SIGNED) event_time FROM mysql.general_log
+			WHERE command_type = 'Connect' GROUP BY user_host`,
+	)
+	if err != nil {
+		l.Debug("unable to read connections from mysql.general_log", zap.Error(err))
+	}
+	for _, e := range connects {
+		user, host, ip, ok := parseGeneralLogUserHost(e.UserHost)
+		if !ok {
+			continue
+		}
+		a := index.account(user, host, ip)
+		if a == nil {
+			continue
+		}
+		t := time.Unix(e.EventTime, 0).UTC()
+		if a.LastConnection == nil || t.After(*a.LastConnection) {
+			a.LastConnection = &t
+		}
+	}
+
+	var failed []*failedLogins
+	err = c.pool().SelectContext(
+		ctx,
+		&failed,
+		`SELECT USERHOST, FAILED_ATTEMPTS FROM information_schema.CONNECTION_CONTROL_FAILED_LOGIN_ATTEMPTS`,
+	)
+	if err != nil {
+		l.Debug("unable to read failed logins from connection_control", zap.Error(err))
+	}
+	for _, f := range failed {
+		user, host, ok := parseQuotedUserHost(f.UserHost)
+		if !ok {
+			continue
+		}
+		a := index.account(user, host)
+		if a == nil {
+			continue
+		}
+		a.FailedLogins += f.Attempts
+	}
+
+	return index.activity, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseGeneralLogUserHost(t *testing.T) {
+	user, host, ip, ok := parseGeneralLogUserHost("app[app] @ web1.example.com [10.0.0.5]")
+	require.True(t, ok)
+	require.Equal(t, "app", user)
+	require.Equal(t, "web1.example.com", host)
+	require.Equal(t, "10.0.0.5", ip)
+
+	user, host, ip, ok = parseGeneralLogUserHost("root[root] @ localhost []")
+	require.True(t, ok)
+	require.Equal(t, "root", user)
+	require.Equal(t, "localhost", host)
+	require.Equal(t, "", ip)
+
+	user, host, ip, ok = parseGeneralLogUserHost("[bob] @  [10.1.2.3]")
+	require.True(t, ok)
+	require.Equal(t, "bob", user)
+	require.Equal(t, "", host)
+	require.Equal(t, "10.1.2.3", ip)
+
+	_, _, _, ok = parseGeneralLogUserHost("garbage")
+	require.False(t, ok)
+}
+
+func Test_parseQuotedUserHost(t *testing.T) {
+	user, host, ok := parseQuotedUserHost("'app'@'10.0.0.5'")
+	require.True(t, ok)
+	require.Equal(t, "app", user)
+	require.Equal(t, "10.0.0.5", host)
+
+	_, _, ok = parseQuotedUserHost("app")
+	require.False(t, ok)
+}
+
+func Test_activityIndex_account(t *testing.T) {
+	index := newActivityIndex([]*User{
+		{User: "app", Host: "%"},
+		{User: "app", Host: "10.0.0.%"},
+		{User: "", Host: "localhost"},
+	})
+
+	a := index.account("app", "10.0.0.5")
+	require.NotNil(t, a)
+	require.Equal(t, "app@10.0.0.%", a.ID())
+	a.TotalConnections += 2
+
+	b := index.account("app", "web1", "10.0.0.6")
+	require.Same(t, a, b)
+
+	require.Equal(t, "app@%", index.account("app", "192.168.1.1").ID())
+	require.Equal(t, "@localhost", index.account("bob", "localhost").ID())
+	require.Nil(t, index.account("bob", "192.168.1.1"))
+	require.Len(t, index.activity, 3)
+}
@@ -0,0 +1,328 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit log record events.
+const (
+	AuditEventConnect    = "connect"
+	AuditEventDisconnect = "disconnect"
+	AuditEventQuery      = "query"
+)
+
+// AuditRecord is a connection or statement record from an audit log, normalized across the MySQL Enterprise
+// audit_log and Percona audit log JSON formats.
+type AuditRecord struct {
+	// Offset is where the record starts in the file.
+	Offset    int64
+	Timestamp time.Time
+	// Event is connect, disconnect or query.
+	Event string
+	// Status is the server error code, or 0 when the connection or statement succeeded.
+	Status int64
+	// Account is the account the client authenticated as. MySQL logs the account's host pattern; Percona only
+	// logs the client host, so Account.Host is empty and Host or IP identify the client.
+	Account AccountName
+	Host    string
+	IP      string
+	// SQLCommand is the statement type, like grant or create_user, for query records.
+	SQLCommand string
+	Query      string
+	Database   string
+}
+
+// mysqlAuditRecord is a record in the MySQL Enterprise audit_log JSON format.
+type mysqlAuditRecord struct {
+	Timestamp string `json:"timestamp"`
+	Class     string `json:"class"`
+	Event     string `json:"event"`
+	Account   *struct {
+		User string `json:"user"`
+		Host string `json:"host"`
+	} `json:"account"`
+	Login *struct {
+		IP string `json:"ip"`
+	} `json:"login"`
+	ConnectionData *struct {
+		Status int64  `json:"status"`
+		DB     string `json:"db"`
+	} `json:"connection_data"`
+	GeneralData *struct {
+		Command    string `json:"command"`
+		SQLCommand string `json:"sql_command"`
+		Query      string `json:"query"`
+		Status     int64  `json:"status"`
+	} `json:"general_data"`
+}
+
+// perconaAuditRecord is a record in the Percona audit log JSON format.
+type perconaAuditRecord struct {
+	AuditRecord *struct {
+		Name         string          `json:"name"`
+		Timestamp    string          `json:"timestamp"`
+		CommandClass string          `json:"command_class"`
+		Status       json.RawMessage `json:"status"`
+		User         string          `json:"user"`
+		PrivUser     string          `json:"priv_user"`
+		Host         string          `json:"host"`
+		IP           string          `json:"ip"`
+		DB           string          `json:"db"`
+		SQLText      string          `json:"sqltext"`
+	} `json:"audit_record"`
+}
+
+var auditTimestampLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05 MST",
+	"2006-01-02T15:04:05",
+}
+
+func parseAuditTimestamp(s string) time.Time {
+	for _, layout := range auditTimestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// parseAuditRecord parses one JSON record of an audit log. It returns nil for records that are not connection
+// or statement records.
+func parseAuditRecord(line []byte) (*AuditRecord, error) {
+	var percona perconaAuditRecord
+	if err := json.Unmarshal(line, &percona); err != nil {
+		return nil, err
+	}
+	if r := percona.AuditRecord; r != nil {
+		ret := &AuditRecord{
+			Timestamp: parseAuditTimestamp(r.Timestamp),
+			Account:   AccountName{User: r.PrivUser},
+			Host:      r.Host,
+			IP:        r.IP,
+			Database:  r.DB,
+		}
+		// Percona writes the status as a number in older releases and as a string in newer ones.
+		_ = json.Unmarshal(bytes.Trim(r.Status, `"`), &ret.Status)
+
+		switch strings.ToLower(r.Name) {
+		case "connect":
+			ret.Event = AuditEventConnect
+			if ret.Account.User == "" {
+				ret.Account.User = r.User
+			}
+		case "quit":
+			ret.Event = AuditEventDisconnect
+			if ret.Account.User == "" {
+				ret.Account.User = r.User
+			}
+		case "query":
+			ret.Event = AuditEventQuery
+			ret.SQLCommand = strings.ToLower(r.CommandClass)
+			ret.Query = r.SQLText
+		default:
+			return nil, nil
+		}
+		return ret, nil
+	}
+
+	var mysql mysqlAuditRecord
+	if err := json.Unmarshal(line, &mysql); err != nil {
+		return nil, err
+	}
+	ret := &AuditRecord{
+		Timestamp: parseAuditTimestamp(mysql.Timestamp),
+	}
+	if mysql.Account != nil {
+		ret.Account = AccountName{User: mysql.Account.User, Host: mysql.Account.Host}
+	}
+	if mysql.Login != nil {
+		ret.IP = mysql.Login.IP
+	}
+
+	switch {
+	case mysql.Class == "connection" && (mysql.Event == "connect" || mysql.Event == "disconnect"):
+		ret.Event = AuditEventConnect
+		if mysql.Event == "disconnect" {
+			ret.Event = AuditEventDisconnect
+		}
+		if mysql.ConnectionData != nil {
+			ret.Status = mysql.ConnectionData.Status
+			ret.Database = mysql.ConnectionData.DB
+		}
+	case mysql.Class == "general" && mysql.GeneralData != nil && mysql.GeneralData.Command == "Query":
+		ret.Event = AuditEventQuery
+		ret.SQLCommand = strings.ToLower(mysql.GeneralData.SQLCommand)
+		ret.Query = mysql.GeneralData.Query
+		ret.Status = mysql.GeneralData.Status
+	default:
+		return nil, nil
+	}
+
+	return ret, nil
+}
+
+// AuditLogFile reads records from an audit log file written in JSON.
+type AuditLogFile struct {
+	Path string
+}
+
+// recordScanner splits an audit log into JSON objects. The MySQL format writes the records as a JSON array,
+// pretty-printed over several lines in some releases, while the Percona format writes one object per line, so
+// objects are found by matching braces outside of strings.
+type recordScanner struct {
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// scan feeds a line to the scanner and returns the position just after the object that ends in it, or -1.
+func (s *recordScanner) scan(line []byte) int {
+	for i, c := range line {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString && c == '\\':
+			s.escaped = true
+		case c == '"':
+			s.inString = !s.inString
+		case s.inString:
+		case c == '{':
+			s.depth++
+		case c == '}':
+			s.depth--
+			if s.depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// AuditLogPosition is a position in an audit log file.
+type AuditLogPosition struct {
+	// Inode identifies the file the offset is in, so a log that has been rotated is read from its start. It is
+	// zero when unknown.
+	Inode  uint64
+	Offset int64
+}
+
+func (p AuditLogPosition) String() string {
+	return fmt.Sprintf("%d:%d", p.Inode, p.Offset)
+}
+
+// ParseAuditLogPosition parses a position written as inode:offset. A bare offset is in the current file.
+func ParseAuditLogPosition(s string) (AuditLogPosition, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return AuditLogPosition{}, nil
+	}
+
+	var ret AuditLogPosition
+	inode, offset, found := strings.Cut(s, ":")
+	if found {
+		i, err := strconv.ParseUint(inode, 10, 64)
+		if err != nil {
+			return AuditLogPosition{}, fmt.Errorf("invalid audit log position %q, expected inode:offset", s)
+		}
+		ret.Inode = i
+	} else {
+		offset = inode
+	}
+	o, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil || o < 0 {
+		return AuditLogPosition{}, fmt.Errorf("invalid audit log position %q, expected inode:offset", s)
+	}
+	ret.Offset = o
+
+	return ret, nil
+}
+
+// ReadRecords returns up to limit connection and statement records starting at from, along with the position
+// to continue from. A record that is still being written is left for the next read. When the file at the path
+// is no longer the one from is in, or it is smaller than the offset, the log has been rotated and reading starts
+// over from the beginning of the new file.
+func (a *AuditLogFile) ReadRecords(from AuditLogPosition, limit int) ([]*AuditRecord, AuditLogPosition, error) {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return nil, from, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, from, err
+	}
+	inode := fileInode(info)
+	offset := from.Offset
+	if (from.Inode != 0 && inode != 0 && from.Inode != inode) || info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, from, err
+	}
+
+	r := bufio.NewReader(f)
+	var ret []*AuditRecord
+	var scanner recordScanner
+	var record []byte
+	// offset is where the record being read starts, or where the next read continues when there is none, and
+	// pos is the end of the lines read so far.
+	pos := offset
+	for len(ret) < limit {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, from, err
+		}
+		pos += int64(len(line))
+
+		// A line may hold the end of one record and more records after it.
+		rest := line
+		for len(rest) > 0 && len(ret) < limit {
+			if scanner.depth == 0 {
+				start := bytes.IndexByte(rest, '{')
+				if start < 0 {
+					// Array brackets and separators between records.
+					offset = pos
+					break
+				}
+				rest = rest[start:]
+				offset = pos - int64(len(rest))
+			}
+			end := scanner.scan(rest)
+			if end < 0 {
+				record = append(record, rest...)
+				break
+			}
+			record = append(record, rest[:end]...)
+			rest = rest[end:]
+
+			rec, err := parseAuditRecord(record)
+			if err != nil {
+				return nil, from, fmt.Errorf("%s: invalid audit record at offset %d: %w", a.Path, offset, err)
+			}
+			if rec != nil {
+				rec.Offset = offset
+				ret = append(ret, rec)
+			}
+			record = nil
+			scanner = recordScanner{}
+			offset = pos - int64(len(rest))
+		}
+	}
+
+	return ret, AuditLogPosition{Inode: inode, Offset: offset}, nil
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mysqlAuditLog = `[
+{
+  "timestamp": "2024-05-01 10:00:00",
+  "id": 0,
+  "class": "audit",
+  "event": "startup",
+  "connection_id": 0
+},
+{
+  "timestamp": "2024-05-01 10:00:01",
+  "id": 1,
+  "class": "connection",
+  "event": "connect",
+  "connection_id": 12,
+  "account": { "user": "alice", "host": "10.0.%" },
+  "login": { "user": "alice", "os": "", "ip": "10.0.0.5", "proxy": "" },
+  "connection_data": { "connection_type": "tcp/ip", "status": 0, "db": "app" }
+},
+{
+  "timestamp": "2024-05-01 10:00:02",
+  "id": 2,
+  "class": "general",
+  "event": "status",
+  "connection_id": 12,
+  "account": { "user": "alice", "host": "10.0.%" },
+  "login": { "user": "alice", "os": "", "ip": "10.0.0.5", "proxy": "" },
+  "general_data": { "command": "Query", "sql_command": "grant", "query": "GRANT SELECT ON app.* TO 'bob'@'%'", "status": 0 }
+},
+{ "timestamp": "2024-05-01 10:00:03", "id": 3, "class": "connection", "event": "connect", "account": { "user": "eve", "host": "%" }, "login": { "ip": "10.0.0.9" }, "connection_data": { "status": 1045 } }
+`
+
+const perconaAuditLog = `{"audit_record":{"name":"Connect","record":"1_2024-05-01T10:00:00","timestamp":"2024-05-01T10:00:00Z","connection_id":"5","status":0,"user":"carol","priv_user":"carol","os_login":"","proxy_user":"","host":"localhost","ip":"","db":""}}
+{"audit_record":{"name":"Query","record":"2_2024-05-01T10:00:01","timestamp":"2024-05-01T10:00:01Z","command_class":"create_user","connection_id":"5","status":"0","sqltext":"CREATE USER 'dave'@'%'","user":"carol[carol] @ localhost []","host":"localhost","os_user":"","ip":"","db":"app"}}
+{"audit_record":{"name":"Quit","record":"3_2024-05-01T10:00:02","timestamp":"2024-05-01T10:00:02Z","connection_id":"5","status":0,"user":"carol","priv_user":"carol","os_login":"","proxy_user":"","host":"localhost","ip":"","db":""}}
+`
+
+func writeAuditLog(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestAuditLogFile_ReadRecords_MySQL(t *testing.T) {
+	a := &AuditLogFile{Path: writeAuditLog(t, mysqlAuditLog)}
+
+	records, next, err := a.ReadRecords(AuditLogPosition{}, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	require.Equal(t, AuditEventConnect, records[0].Event)
+	require.Equal(t, AccountName{User: "alice", Host: "10.0.%"}, records[0].Account)
+	require.Equal(t, "10.0.0.5", records[0].IP)
+	require.Equal(t, "app", records[0].Database)
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC), records[0].Timestamp)
+
+	require.Equal(t, AuditEventQuery, records[1].Event)
+	require.Equal(t, "grant", records[1].SQLCommand)
+	require.Equal(t, "GRANT SELECT ON app.* TO 'bob'@'%'", records[1].Query)
+
+	require.Equal(t, int64(1045), records[2].Status)
+
+	// The array is still open, so the reader waits at the end of the last record.
+	require.Equal(t, int64(len(mysqlAuditLog)), next.Offset)
+	records, again, err := a.ReadRecords(next, 10)
+	require.NoError(t, err)
+	require.Empty(t, records)
+	require.Equal(t, next, again)
+}
+
+func TestAuditLogFile_ReadRecords_Percona(t *testing.T) {
+	a := &AuditLogFile{Path: writeAuditLog(t, perconaAuditLog)}
+
+	records, next, err := a.ReadRecords(AuditLogPosition{}, 2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, AuditEventConnect, records[0].Event)
+	require.Equal(t, AccountName{User: "carol"}, records[0].Account)
+	require.Equal(t, "localhost", records[0].Host)
+	require.Equal(t, AuditEventQuery, records[1].Event)
+	require.Equal(t, "create_user", records[1].SQLCommand)
+	require.Equal(t, "CREATE USER 'dave'@'%'", records[1].Query)
+	require.Equal(t, int64(0), records[1].Status)
+
+	records, next, err = a.ReadRecords(next, 2)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, AuditEventDisconnect, records[0].Event)
+	require.Equal(t, int64(len(perconaAuditLog)), next.Offset)
+}
+
+func TestAuditLogFile_ReadRecords_Partial(t *testing.T) {
+	partial := `{"audit_record":{"name":"Connect","timestamp":"2024-05-01T10:00:00Z","status":0,"priv_user":"carol"`
+	path := writeAuditLog(t, perconaAuditLog+partial)
+	a := &AuditLogFile{Path: path}
+
+	records, next, err := a.ReadRecords(AuditLogPosition{}, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, int64(len(perconaAuditLog)), next.Offset)
+
+	// Once the record is finished it is read from where it starts.
+	require.NoError(t, os.WriteFile(path, []byte(perconaAuditLog+partial+"}}\n"), 0600))
+	records, _, err = a.ReadRecords(next, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, next.Offset, records[0].Offset)
+}
+
+func TestAuditLogFile_ReadRecords_Rotated(t *testing.T) {
+	a := &AuditLogFile{Path: writeAuditLog(t, perconaAuditLog)}
+
+	records, _, err := a.ReadRecords(AuditLogPosition{Offset: int64(len(perconaAuditLog)) + 100}, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, int64(0), records[0].Offset)
+}
+
+func TestAuditLogFile_ReadRecords_Replaced(t *testing.T) {
+	path := writeAuditLog(t, perconaAuditLog)
+	a := &AuditLogFile{Path: path}
+
+	_, next, err := a.ReadRecords(AuditLogPosition{}, 2)
+	require.NoError(t, err)
+	require.NotZero(t, next.Inode)
+
+	// A new log renamed into place is read from its start, even when it is already larger than the offset.
+	rotated := filepath.Join(filepath.Dir(path), "audit.log.new")
+	require.NoError(t, os.WriteFile(rotated, []byte(perconaAuditLog+perconaAuditLog), 0600))
+	require.NoError(t, os.Rename(rotated, path))
+
+	records, again, err := a.ReadRecords(next, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	require.Equal(t, int64(0), records[0].Offset)
+	require.NotEqual(t, next.Inode, again.Inode)
+}
+
+func TestAuditLogFile_ReadRecords_SameLine(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(perconaAuditLog), "\n")
+	content := lines[0] + lines[1] + " " + lines[2] + "\n"
+	a := &AuditLogFile{Path: writeAuditLog(t, content)}
+
+	records, next, err := a.ReadRecords(AuditLogPosition{}, 2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, int64(len(lines[0])), records[1].Offset)
+	require.Equal(t, int64(len(lines[0])+len(lines[1])), next.Offset)
+
+	records, next, err = a.ReadRecords(next, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, AuditEventDisconnect, records[0].Event)
+	require.Equal(t, int64(len(lines[0])+len(lines[1])+1), records[0].Offset)
+	require.Equal(t, int64(len(content)), next.Offset)
+}
+
+func TestParseAuditLogPosition(t *testing.T) {
+	p, err := ParseAuditLogPosition("1234:56")
+	require.NoError(t, err)
+	require.Equal(t, AuditLogPosition{Inode: 1234, Offset: 56}, p)
+	require.Equal(t, "1234:56", p.String())
+
+	p, err = ParseAuditLogPosition("56")
+	require.NoError(t, err)
+	require.Equal(t, AuditLogPosition{Offset: 56}, p)
+
+	p, err = ParseAuditLogPosition("")
+	require.NoError(t, err)
+	require.Equal(t, AuditLogPosition{}, p)
+
+	_, err = ParseAuditLogPosition("abc:1")
+	require.Error(t, err)
+	_, err = ParseAuditLogPosition("1:-1")
+	require.Error(t, err)
+}
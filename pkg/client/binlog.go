@@ -0,0 +1,352 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// Binary log event types and layout, from the MySQL binary log v4 format.
+const (
+	binlogMagic             = "\xfebin"
+	binlogHeaderLen         = 19
+	binlogFirstEventPos     = 4
+	binlogQueryEvent        = 2
+	binlogRotateEvent       = 4
+	binlogFormatDescription = 15
+	binlogChecksumCRC32     = 1
+	binlogChecksumLen       = 4
+	// binlogQueryPostHeaderLen is the size of the fixed part of a query event: thread ID, execution time,
+	// database name length, error code and status variables length.
+	binlogQueryPostHeaderLen = 13
+)
+
+// BinlogPosition is a position in the binary log.
+type BinlogPosition struct {
+	File string
+	Pos  uint32
+}
+
+func (p BinlogPosition) String() string {
+	return fmt.Sprintf("%s:%d", p.File, p.Pos)
+}
+
+// IsZero reports whether the position is unset.
+func (p BinlogPosition) IsZero() bool {
+	return p.File == ""
+}
+
+// ParseBinlogPosition parses a position written as file:pos, like mysql-bin.000042:1234. The offset defaults to
+// the first event of the file.
+func ParseBinlogPosition(s string) (BinlogPosition, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return BinlogPosition{}, nil
+	}
+
+	file, pos, found := strings.Cut(s, ":")
+	if !found {
+		return BinlogPosition{File: file, Pos: binlogFirstEventPos}, nil
+	}
+	p, err := strconv.ParseUint(pos, 10, 32)
+	if err != nil || file == "" {
+		return BinlogPosition{}, fmt.Errorf("invalid binary log position %q, expected file:pos", s)
+	}
+	if p < binlogFirstEventPos {
+		p = binlogFirstEventPos
+	}
+
+	return BinlogPosition{File: file, Pos: uint32(p)}, nil
+}
+
+// BinlogStatement is a statement logged in the binary log.
+type BinlogStatement struct {
+	Position BinlogPosition
+	// Next is the position of the following event.
+	Next BinlogPosition
+	// Timestamp is when the statement ran.
+	Timestamp time.Time
+	Database  string
+	Statement string
+}
+
+// validBinlogName matches the binary log names that can be asked for in a binary log dump.
+var validBinlogName = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+// binlogFilePattern matches binary log file names, which end with a sequence number.
+var binlogFilePattern = regexp.MustCompile(`\.\d{6,}$`)
+
+// BinlogDir reads binary log files copied from a server, for offline processing.
+type BinlogDir struct {
+	Path string
+}
+
+// files returns the binary log files in the directory in sequence order.
+func (d *BinlogDir) files() ([]string, error) {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && binlogFilePattern.MatchString(e.Name()) {
+			ret = append(ret, e.Name())
+		}
+	}
+	sort.Strings(ret)
+
+	return ret, nil
+}
+
+// ReadStatements returns up to limit query statements starting at from, moving on to the following files when a
+// file ends, along with the position to continue from. A zero position starts at the first file. An event that
+// is only partly written is left for the next read.
+func (d *BinlogDir) ReadStatements(from BinlogPosition, limit int) ([]*BinlogStatement, BinlogPosition, error) {
+	files, err := d.files()
+	if err != nil {
+		return nil, from, err
+	}
+	if len(files) == 0 {
+		return nil, from, nil
+	}
+	if from.IsZero() {
+		from = BinlogPosition{File: files[0], Pos: binlogFirstEventPos}
+	}
+
+	var ret []*BinlogStatement
+	for i := sort.SearchStrings(files, from.File); i < len(files); i++ {
+		if files[i] != from.File {
+			from = BinlogPosition{File: files[i], Pos: binlogFirstEventPos}
+		}
+
+		stmts, next, err := readBinlogFile(filepath.Join(d.Path, from.File), from, limit-len(ret))
+		if err != nil {
+			return nil, from, err
+		}
+		ret = append(ret, stmts...)
+		from = next
+		if len(ret) >= limit {
+			break
+		}
+	}
+
+	return ret, from, nil
+}
+
+// binlogFormat is what the format description event says about how the rest of a file is written.
+type binlogFormat struct {
+	queryPostHeaderLen int
+	checksum           bool
+}
+
+// readBinlogFile reads query events from a binary log file starting at from.Pos.
+func readBinlogFile(path string, from BinlogPosition, limit int) ([]*BinlogStatement, BinlogPosition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, from, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(binlogMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != binlogMagic {
+		return nil, from, fmt.Errorf("%s is not a binary log file", path)
+	}
+
+	format := binlogFormat{queryPostHeaderLen: binlogQueryPostHeaderLen}
+	pos := uint32(binlogFirstEventPos)
+	var ret []*BinlogStatement
+	for len(ret) < limit {
+		header := make([]byte, binlogHeaderLen)
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, from, err
+		}
+		eventType := header[4]
+		size := binary.LittleEndian.Uint32(header[9:13])
+		if size < binlogHeaderLen {
+			return nil, from, fmt.Errorf("%s: invalid event size %d at %d", path, size, pos)
+		}
+		body := make([]byte, size-binlogHeaderLen)
+		if _, err := io.ReadFull(r, body); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, from, err
+		}
+
+		start := pos
+		pos += size
+		switch {
+		case eventType == binlogFormatDescription:
+			format = parseFormatDescription(body)
+			// Skip straight to the requested position once the format is known.
+			if from.Pos > pos {
+				if _, err := f.Seek(int64(from.Pos), io.SeekStart); err != nil {
+					return nil, from, err
+				}
+				r.Reset(f)
+				pos = from.Pos
+			}
+		case eventType == binlogQueryEvent && start >= from.Pos:
+			stmt, err := parseQueryEvent(body, format)
+			if err != nil {
+				return nil, from, fmt.Errorf("%s: %w at %d", path, err, start)
+			}
+			stmt.Position = BinlogPosition{File: from.File, Pos: start}
+			stmt.Next = BinlogPosition{File: from.File, Pos: pos}
+			stmt.Timestamp = time.Unix(int64(binary.LittleEndian.Uint32(header[0:4])), 0).UTC()
+			ret = append(ret, stmt)
+		}
+	}
+
+	if pos < from.Pos {
+		pos = from.Pos
+	}
+	return ret, BinlogPosition{File: from.File, Pos: pos}, nil
+}
+
+// parseFormatDescription reads the post-header length of query events and whether events end with a CRC32
+// checksum. Servers since MySQL 5.6.1 and MariaDB 5.3 write the checksum algorithm in the last byte before the
+// event's own checksum.
+func parseFormatDescription(body []byte) binlogFormat {
+	format := binlogFormat{queryPostHeaderLen: binlogQueryPostHeaderLen}
+
+	// binlog version (2), server version (50), create timestamp (4), common header length (1), post-header lengths
+	const postHeaderLensOffset = 2 + 50 + 4 + 1
+	if len(body) <= postHeaderLensOffset+binlogQueryEvent-1 {
+		return format
+	}
+	format.queryPostHeaderLen = int(body[postHeaderLensOffset+binlogQueryEvent-1])
+
+	serverVersion := string(bytes.TrimRight(body[2:52], "\x00"))
+	_, sv, err := parseServerVersion(serverVersion, "")
+	if err == nil && (sv.atLeast(5, 6, 1) || strings.Contains(strings.ToLower(serverVersion), "mariadb")) &&
+		len(body) >= binlogChecksumLen+1 {
+		format.checksum = body[len(body)-binlogChecksumLen-1] == binlogChecksumCRC32
+	}
+
+	return format
+}
+
+// parseQueryEvent reads the default database and statement text of a query event.
+func parseQueryEvent(body []byte, format binlogFormat) (*BinlogStatement, error) {
+	if format.checksum {
+		if len(body) < binlogChecksumLen {
+			return nil, fmt.Errorf("truncated query event")
+		}
+		body = body[:len(body)-binlogChecksumLen]
+	}
+	if len(body) < format.queryPostHeaderLen || format.queryPostHeaderLen < binlogQueryPostHeaderLen {
+		return nil, fmt.Errorf("truncated query event")
+	}
+
+	dbLen := int(body[8])
+	statusVarsLen := int(binary.LittleEndian.Uint16(body[11:13]))
+	offset := format.queryPostHeaderLen + statusVarsLen
+	if len(body) < offset+dbLen+1 {
+		return nil, fmt.Errorf("truncated query event")
+	}
+
+	return &BinlogStatement{
+		Database:  string(body[offset : offset+dbLen]),
+		Statement: string(body[offset+dbLen+1:]),
+	}, nil
+}
+
+// errUnknownSystemVariable is returned for @@global.binlog_checksum by servers older than MySQL 5.6.2.
+const errUnknownSystemVariable = 1193
+
+func isUnknownVariable(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == errUnknownSystemVariable
+}
+
+// ListBinlogStatements reads up to limit query statements from the server's binary log starting at from, moving
+// on to the following log when one ends, along with the position to continue from. A zero position starts at
+// the oldest binary log. The log is read with its own connection, as a replica reads it, so statements have the
+// timestamps of their events.
+// Required MySQL grant for connector:
+//
+//	GRANT REPLICATION SLAVE ON *.* TO user@host;
+func (c *Client) ListBinlogStatements(ctx context.Context, from BinlogPosition, limit int) ([]*BinlogStatement, BinlogPosition, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing binary log statements", zap.String("from", from.String()))
+
+	if !from.IsZero() && !validBinlogName.MatchString(from.File) {
+		return nil, from, fmt.Errorf("invalid binary log name: %s", from.File)
+	}
+	if c.dsn == nil {
+		return nil, from, fmt.Errorf("baton-mysql: no connection settings to read the binary log with")
+	}
+
+	var checksum string
+	err := c.reader().GetContext(ctx, &checksum, "SELECT @@global.binlog_checksum")
+	if err != nil && !isUnknownVariable(err) {
+		return nil, from, err
+	}
+
+	// Reading the binary log changes nothing, so a dump that fails with a transient error is started again.
+	var ret []*BinlogStatement
+	next := from
+	err = c.withRetry(ctx, fmt.Sprintf("binary log dump from %s", from), true, func() error {
+		var err error
+		ret, next, err = c.dumpBinlog(ctx, from, limit, checksum)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, from, ctx.Err()
+		}
+		return nil, from, err
+	}
+	return ret, next, nil
+}
+
+// dumpBinlog connects to the server as a replica and reads up to limit statements from a binary log position.
+// checksum is the server's binlog_checksum, or empty when the server has none.
+func (c *Client) dumpBinlog(ctx context.Context, from BinlogPosition, limit int, checksum string) ([]*BinlogStatement, BinlogPosition, error) {
+	s, err := dialBinlogStream(ctx, c.dsn)
+	if err != nil {
+		return nil, from, err
+	}
+	defer s.Close()
+	stop := context.AfterFunc(ctx, func() { s.Close() })
+	defer stop()
+
+	// The server only sends checksums, and MariaDB only sends its own events, to replicas that say they
+	// understand them.
+	if checksum != "" {
+		if err := s.exec("SET @master_binlog_checksum = @@global.binlog_checksum"); err != nil {
+			return nil, from, err
+		}
+	}
+	if c.capabilities.Flavor == FlavorMariaDB {
+		if err := s.exec("SET @mariadb_slave_capability = 4"); err != nil {
+			return nil, from, err
+		}
+	}
+	if err := s.dump(from); err != nil {
+		return nil, from, err
+	}
+
+	return s.readStatements(from, limit, strings.EqualFold(checksum, "CRC32"))
+}
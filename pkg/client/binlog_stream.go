@@ -0,0 +1,487 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // mysql_native_password and the RSA padding of caching_sha2_password use SHA1.
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Client/server protocol constants used to read the binary log as a replica does.
+const (
+	maxPacketSize = 1<<24 - 1
+
+	packetOK           = 0x00
+	packetAuthMoreData = 0x01
+	packetEOF          = 0xfe
+	packetErr          = 0xff
+
+	comQuery      = 0x03
+	comBinlogDump = 0x12
+
+	clientLongPassword     = 0x00000001
+	clientLongFlag         = 0x00000004
+	clientProtocol41       = 0x00000200
+	clientSSL              = 0x00000800
+	clientTransactions     = 0x00002000
+	clientSecureConnection = 0x00008000
+	clientPluginAuth       = 0x00080000
+
+	// collationUTF8MB4 is utf8mb4_general_ci.
+	collationUTF8MB4 = 45
+
+	authNativePassword  = "mysql_native_password"
+	authCachingSHA2     = "caching_sha2_password"
+	authClearPassword   = "mysql_clear_password"
+	cachingSHA2FastAuth = 0x03
+	cachingSHA2FullAuth = 0x04
+	cachingSHA2PubKey   = 0x02
+
+	// binlogDumpNonBlock makes the server end the dump at the end of the last binary log instead of waiting for
+	// new events.
+	binlogDumpNonBlock = 0x01
+	// binlogArtificialEvent flags events the server makes up for the dump, which have no position of their own.
+	binlogArtificialEvent = 0x20
+)
+
+var errMalformedPacket = errors.New("malformed packet")
+
+// binlogStream is a connection reading the binary log with COM_BINLOG_DUMP, as a replica does, so statements come
+// with the timestamps of their events. It speaks just enough of the client/server protocol to authenticate.
+type binlogStream struct {
+	conn net.Conn
+	seq  byte
+	// secure is set on TLS and unix socket connections, where passwords can be sent in the clear.
+	secure   bool
+	scramble []byte
+}
+
+// dialBinlogStream connects and authenticates to the server of a DSN.
+func dialBinlogStream(ctx context.Context, cfg *mysql.Config) (*binlogStream, error) {
+	d := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := d.DialContext(ctx, cfg.Net, cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &binlogStream{conn: conn, secure: cfg.Net == "unix"}
+	if err := s.handshake(ctx, cfg); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *binlogStream) Close() error {
+	return s.conn.Close()
+}
+
+func (s *binlogStream) readPacket() ([]byte, error) {
+	var ret []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(s.conn, header[:]); err != nil {
+			return nil, err
+		}
+		size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+		s.seq = header[3] + 1
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(s.conn, payload); err != nil {
+			return nil, err
+		}
+		ret = append(ret, payload...)
+		// A payload of the maximum size continues in the next packet.
+		if size < maxPacketSize {
+			if len(ret) == 0 {
+				return nil, errMalformedPacket
+			}
+			return ret, nil
+		}
+	}
+}
+
+func (s *binlogStream) writePacket(payload []byte) error {
+	if len(payload) >= maxPacketSize {
+		return fmt.Errorf("packet of %d bytes is too large", len(payload))
+	}
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), s.seq}
+	s.seq++
+	_, err := s.conn.Write(append(header, payload...))
+	return err
+}
+
+// parseErrPacket returns the error sent by the server.
+func parseErrPacket(p []byte) error {
+	if len(p) < 3 {
+		return errMalformedPacket
+	}
+	ret := &mysql.MySQLError{Number: binary.LittleEndian.Uint16(p[1:3])}
+	msg := p[3:]
+	if len(msg) >= 6 && msg[0] == '#' {
+		copy(ret.SQLState[:], msg[1:6])
+		msg = msg[6:]
+	}
+	ret.Message = string(msg)
+	return ret
+}
+
+// handshake reads the server's greeting, switches to TLS when the DSN asks for it and authenticates.
+func (s *binlogStream) handshake(ctx context.Context, cfg *mysql.Config) error {
+	p, err := s.readPacket()
+	if err != nil {
+		return err
+	}
+	if p[0] == packetErr {
+		return parseErrPacket(p)
+	}
+	if p[0] != 10 {
+		return fmt.Errorf("unsupported protocol version %d", p[0])
+	}
+
+	// Server version, connection ID, the first 8 bytes of the scramble, a filler and the lower capability flags.
+	versionEnd := bytes.IndexByte(p[1:], 0)
+	if versionEnd < 0 {
+		return errMalformedPacket
+	}
+	pos := 1 + versionEnd + 1 + 4
+	if len(p) < pos+8+1+2 {
+		return errMalformedPacket
+	}
+	s.scramble = append([]byte{}, p[pos:pos+8]...)
+	pos += 8 + 1
+	caps := uint32(binary.LittleEndian.Uint16(p[pos:]))
+	pos += 2
+
+	plugin := authNativePassword
+	if len(p) >= pos+16 {
+		// Character set, status flags, upper capability flags, scramble length and 10 reserved bytes, followed by
+		// the rest of the scramble and the authentication plugin.
+		caps |= uint32(binary.LittleEndian.Uint16(p[pos+3:])) << 16
+		pos += 16
+		if len(p) >= pos+13 {
+			s.scramble = append(s.scramble, p[pos:pos+12]...)
+			pos += 13
+		}
+		if caps&clientPluginAuth != 0 && pos < len(p) {
+			name := p[pos:]
+			if i := bytes.IndexByte(name, 0); i >= 0 {
+				name = name[:i]
+			}
+			plugin = string(name)
+		}
+	}
+	if plugin != authCachingSHA2 {
+		// The server switches plugins when the account uses another one.
+		plugin = authNativePassword
+	}
+
+	flags := uint32(clientLongPassword | clientLongFlag | clientProtocol41 | clientTransactions |
+		clientSecureConnection | clientPluginAuth)
+	if cfg.TLS != nil {
+		switch {
+		case caps&clientSSL != 0:
+			flags |= clientSSL
+		case !cfg.AllowFallbackToPlaintext:
+			return fmt.Errorf("the server does not support TLS")
+		}
+	}
+
+	resp := binary.LittleEndian.AppendUint32(nil, flags)
+	resp = binary.LittleEndian.AppendUint32(resp, 0)
+	resp = append(resp, collationUTF8MB4)
+	resp = append(resp, make([]byte, 23)...)
+
+	if flags&clientSSL != 0 {
+		if err := s.writePacket(resp); err != nil {
+			return err
+		}
+		tlsConn := tls.Client(s.conn, cfg.TLS)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return err
+		}
+		s.conn = tlsConn
+		s.secure = true
+	}
+
+	authResp, err := s.authResponse(cfg, plugin)
+	if err != nil {
+		return err
+	}
+	resp = append(resp, cfg.User...)
+	resp = append(resp, 0, byte(len(authResp)))
+	resp = append(resp, authResp...)
+	resp = append(resp, plugin...)
+	resp = append(resp, 0)
+	if err := s.writePacket(resp); err != nil {
+		return err
+	}
+
+	return s.authenticate(cfg, plugin)
+}
+
+// authResponse returns the password of the DSN scrambled for an authentication plugin.
+func (s *binlogStream) authResponse(cfg *mysql.Config, plugin string) ([]byte, error) {
+	switch plugin {
+	case authNativePassword:
+		return scrambleNativePassword(s.scramble, cfg.Passwd), nil
+	case authCachingSHA2:
+		return scrambleSHA256Password(s.scramble, cfg.Passwd), nil
+	case authClearPassword:
+		if !cfg.AllowCleartextPasswords {
+			return nil, fmt.Errorf("the server asked for the password in the clear, which the DSN does not allow")
+		}
+		return append([]byte(cfg.Passwd), 0), nil
+	default:
+		return nil, fmt.Errorf("unsupported authentication plugin %s for reading the binary log", plugin)
+	}
+}
+
+// authenticate reads the result of the handshake response, answering plugin switches and the extra rounds of
+// caching_sha2_password.
+func (s *binlogStream) authenticate(cfg *mysql.Config, plugin string) error {
+	for {
+		p, err := s.readPacket()
+		if err != nil {
+			return err
+		}
+
+		switch p[0] {
+		case packetOK:
+			return nil
+
+		case packetErr:
+			return parseErrPacket(p)
+
+		case packetEOF:
+			// Authentication switch request: the plugin name and its scramble.
+			name, data, _ := bytes.Cut(p[1:], []byte{0})
+			plugin = string(name)
+			s.scramble = bytes.TrimSuffix(data, []byte{0})
+			resp, err := s.authResponse(cfg, plugin)
+			if err != nil {
+				return err
+			}
+			if err := s.writePacket(resp); err != nil {
+				return err
+			}
+
+		case packetAuthMoreData:
+			if plugin != authCachingSHA2 || len(p) < 2 {
+				return errMalformedPacket
+			}
+			switch p[1] {
+			case cachingSHA2FastAuth:
+				// The OK packet follows.
+			case cachingSHA2FullAuth:
+				if err := s.sendFullAuth(cfg); err != nil {
+					return err
+				}
+			default:
+				return errMalformedPacket
+			}
+
+		default:
+			return errMalformedPacket
+		}
+	}
+}
+
+// sendFullAuth sends the password for a full caching_sha2_password authentication: in the clear over a secure
+// connection, or encrypted with the server's public key.
+func (s *binlogStream) sendFullAuth(cfg *mysql.Config) error {
+	if s.secure {
+		return s.writePacket(append([]byte(cfg.Passwd), 0))
+	}
+
+	if err := s.writePacket([]byte{cachingSHA2PubKey}); err != nil {
+		return err
+	}
+	p, err := s.readPacket()
+	if err != nil {
+		return err
+	}
+	if p[0] == packetErr {
+		return parseErrPacket(p)
+	}
+	if p[0] != packetAuthMoreData {
+		return errMalformedPacket
+	}
+	enc, err := encryptPassword(cfg.Passwd, s.scramble, p[1:])
+	if err != nil {
+		return err
+	}
+	return s.writePacket(enc)
+}
+
+// encryptPassword encrypts a password XORed with the scramble with a PEM encoded RSA public key.
+func encryptPassword(password string, scramble []byte, key []byte) ([]byte, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key from the server")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("the server's public key is not an RSA key")
+	}
+
+	plain := append([]byte(password), 0)
+	for i := range plain {
+		plain[i] ^= scramble[i%len(scramble)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaPub, plain, nil) //nolint:gosec // Required by the protocol.
+}
+
+// scrambleNativePassword returns SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password))).
+func scrambleNativePassword(scramble []byte, password string) []byte {
+	if password == "" || len(scramble) < 20 {
+		return nil
+	}
+	stage1 := sha1.Sum([]byte(password)) //nolint:gosec // Required by the protocol.
+	stage2 := sha1.Sum(stage1[:])        //nolint:gosec // Required by the protocol.
+	h := sha1.New()                      //nolint:gosec // Required by the protocol.
+	h.Write(scramble[:20])
+	h.Write(stage2[:])
+	ret := h.Sum(nil)
+	for i := range ret {
+		ret[i] ^= stage1[i]
+	}
+	return ret
+}
+
+// scrambleSHA256Password returns SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble).
+func scrambleSHA256Password(scramble []byte, password string) []byte {
+	if password == "" {
+		return nil
+	}
+	m1 := sha256.Sum256([]byte(password))
+	m2 := sha256.Sum256(m1[:])
+	h := sha256.New()
+	h.Write(m2[:])
+	h.Write(scramble)
+	ret := h.Sum(nil)
+	for i := range ret {
+		ret[i] ^= m1[i]
+	}
+	return ret
+}
+
+// exec runs a statement that returns no rows.
+func (s *binlogStream) exec(query string) error {
+	s.seq = 0
+	if err := s.writePacket(append([]byte{comQuery}, query...)); err != nil {
+		return err
+	}
+	p, err := s.readPacket()
+	if err != nil {
+		return err
+	}
+	switch p[0] {
+	case packetOK:
+		return nil
+	case packetErr:
+		return parseErrPacket(p)
+	default:
+		return errMalformedPacket
+	}
+}
+
+// dump asks the server to send the binary log from a position, or from the oldest binary log when the position
+// is zero, until the end of the last one.
+func (s *binlogStream) dump(from BinlogPosition) error {
+	pos := from.Pos
+	if from.IsZero() {
+		pos = binlogFirstEventPos
+	}
+
+	s.seq = 0
+	req := []byte{comBinlogDump}
+	req = binary.LittleEndian.AppendUint32(req, pos)
+	req = binary.LittleEndian.AppendUint16(req, binlogDumpNonBlock)
+	// A server ID of zero, like mysqlbinlog, as the connection isn't a replica.
+	req = binary.LittleEndian.AppendUint32(req, 0)
+	req = append(req, from.File...)
+	return s.writePacket(req)
+}
+
+// readStatements reads up to limit query statements from the dump, along with the position to continue from.
+// checksum is whether the server ends events with a checksum, which is needed for the rotate event sent before
+// the format description.
+func (s *binlogStream) readStatements(from BinlogPosition, limit int, checksum bool) ([]*BinlogStatement, BinlogPosition, error) {
+	format := binlogFormat{queryPostHeaderLen: binlogQueryPostHeaderLen, checksum: checksum}
+	pos := from
+	var ret []*BinlogStatement
+	for len(ret) < limit {
+		p, err := s.readPacket()
+		if err != nil {
+			return nil, from, err
+		}
+		switch {
+		case p[0] == packetErr:
+			return nil, from, parseErrPacket(p)
+		case p[0] == packetEOF && len(p) < 9:
+			return ret, pos, nil
+		case p[0] != packetOK:
+			return nil, from, errMalformedPacket
+		}
+
+		event := p[1:]
+		if len(event) < binlogHeaderLen {
+			return nil, from, errMalformedPacket
+		}
+		header, body := event[:binlogHeaderLen], event[binlogHeaderLen:]
+		eventType := header[4]
+		size := binary.LittleEndian.Uint32(header[9:13])
+		logPos := binary.LittleEndian.Uint32(header[13:17])
+		flags := binary.LittleEndian.Uint16(header[17:19])
+
+		switch eventType {
+		case binlogRotateEvent:
+			if format.checksum && len(body) >= binlogChecksumLen {
+				body = body[:len(body)-binlogChecksumLen]
+			}
+			if len(body) < 8 {
+				return nil, from, errMalformedPacket
+			}
+			pos = BinlogPosition{File: string(body[8:]), Pos: uint32(binary.LittleEndian.Uint64(body[:8]))}
+			continue
+		case binlogFormatDescription:
+			format = parseFormatDescription(body)
+		case binlogQueryEvent:
+			if logPos < size {
+				return nil, from, fmt.Errorf("%s: invalid query event at %d", pos.File, logPos)
+			}
+			stmt, err := parseQueryEvent(body, format)
+			if err != nil {
+				return nil, from, fmt.Errorf("%s: %w at %d", pos.File, err, logPos-size)
+			}
+			stmt.Position = BinlogPosition{File: pos.File, Pos: logPos - size}
+			stmt.Next = BinlogPosition{File: pos.File, Pos: logPos}
+			stmt.Timestamp = time.Unix(int64(binary.LittleEndian.Uint32(header[0:4])), 0).UTC()
+			ret = append(ret, stmt)
+		}
+
+		if logPos > 0 && flags&binlogArtificialEvent == 0 {
+			pos.Pos = logPos
+		}
+	}
+
+	return ret, pos, nil
+}
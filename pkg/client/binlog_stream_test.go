@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBinlogServer plays the server side of a binary log dump over a connection.
+type fakeBinlogServer struct {
+	conn net.Conn
+	seq  byte
+}
+
+func (f *fakeBinlogServer) write(payload []byte) {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), f.seq}
+	f.seq++
+	_, _ = f.conn.Write(append(header, payload...))
+}
+
+func (f *fakeBinlogServer) read() []byte {
+	s := &binlogStream{conn: f.conn}
+	p, _ := s.readPacket()
+	f.seq = s.seq
+	return p
+}
+
+// events sends the events of a binary log file, after the rotate event the server starts a dump with.
+func (f *fakeBinlogServer) events(file string, w *binlogWriter) {
+	rotate := make([]byte, binlogHeaderLen)
+	rotate[4] = binlogRotateEvent
+	binary.LittleEndian.PutUint16(rotate[17:19], binlogArtificialEvent)
+	rotate = binary.LittleEndian.AppendUint64(rotate, binlogFirstEventPos)
+	rotate = append(rotate, file...)
+	binary.LittleEndian.PutUint32(rotate[9:13], uint32(len(rotate)+binlogChecksumLen))
+	rotate = binary.LittleEndian.AppendUint32(rotate, crc32.ChecksumIEEE(rotate))
+	f.write(append([]byte{packetOK}, rotate...))
+
+	data := w.buf.Bytes()[len(binlogMagic):]
+	for len(data) > 0 {
+		size := binary.LittleEndian.Uint32(data[9:13])
+		f.write(append([]byte{packetOK}, data[:size]...))
+		data = data[size:]
+	}
+	f.write([]byte{packetEOF, 0, 0, 0, 0})
+}
+
+func writeTempBinlog(t *testing.T, w *binlogWriter) string {
+	path := filepath.Join(t.TempDir(), "binlog.000001")
+	require.NoError(t, os.WriteFile(path, w.buf.Bytes(), 0o600))
+	return path
+}
+
+func TestBinlogStream(t *testing.T) {
+	ctx := context.Background()
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	scramble := []byte("abcdefghijklmnopqrst")
+
+	w := newBinlogWriter("8.0.36", true)
+	w.query(ts, "shop", "GRANT SELECT ON orders TO 'app'@'%'")
+	w.query(ts.Add(time.Minute), "", "CREATE USER 'bob'@'%' IDENTIFIED BY 'x'")
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	responses := make(chan []byte, 2)
+	go func() {
+		f := &fakeBinlogServer{conn: serverConn}
+
+		greeting := append([]byte{10}, "8.0.36\x00"...)
+		greeting = append(greeting, 1, 0, 0, 0)
+		greeting = append(greeting, scramble[:8]...)
+		greeting = append(greeting, 0)
+		greeting = binary.LittleEndian.AppendUint16(greeting, clientProtocol41|clientSecureConnection)
+		greeting = append(greeting, collationUTF8MB4, 0, 0)
+		greeting = binary.LittleEndian.AppendUint16(greeting, clientPluginAuth>>16)
+		greeting = append(greeting, 21)
+		greeting = append(greeting, make([]byte, 10)...)
+		greeting = append(greeting, scramble[8:]...)
+		greeting = append(greeting, 0)
+		greeting = append(greeting, authNativePassword+"\x00"...)
+		f.write(greeting)
+
+		responses <- f.read()
+		f.write([]byte{packetOK, 0, 0, 0, 0, 0, 0})
+
+		responses <- f.read()
+		f.events("binlog.000001", w)
+	}()
+
+	s := &binlogStream{conn: clientConn}
+	require.NoError(t, s.handshake(ctx, &mysql.Config{User: "conductorone", Passwd: "secret"}))
+	resp := <-responses
+	require.Contains(t, string(resp), "conductorone\x00\x14"+string(scrambleNativePassword(scramble, "secret")))
+
+	require.NoError(t, s.dump(BinlogPosition{}))
+	req := <-responses
+	require.Equal(t, byte(comBinlogDump), req[0])
+	require.Equal(t, uint32(binlogFirstEventPos), binary.LittleEndian.Uint32(req[1:5]))
+
+	stmts, next, err := s.readStatements(BinlogPosition{}, 10, true)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+
+	// The dump reads the same statements as the file.
+	want, wantNext, err := readBinlogFile(writeTempBinlog(t, w), BinlogPosition{File: "binlog.000001"}, 10)
+	require.NoError(t, err)
+	require.Equal(t, want, stmts)
+	require.Equal(t, wantNext, next)
+	require.Equal(t, ts, stmts[0].Timestamp)
+	require.Equal(t, ts.Add(time.Minute), stmts[1].Timestamp)
+}
+
+func TestParseErrPacket(t *testing.T) {
+	err := parseErrPacket(append([]byte{packetErr, 0xd4, 0x04}, "#HY000Could not find first log file name in binary log index file"...))
+	var myErr *mysql.MySQLError
+	require.ErrorAs(t, err, &myErr)
+	require.Equal(t, uint16(1236), myErr.Number)
+	require.Equal(t, "HY000", string(myErr.SQLState[:]))
+	require.Equal(t, "Could not find first log file name in binary log index file", myErr.Message)
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// binlogWriter builds a binary log file with a format description event and query events.
+type binlogWriter struct {
+	buf      bytes.Buffer
+	checksum bool
+}
+
+func newBinlogWriter(serverVersion string, checksum bool) *binlogWriter {
+	w := &binlogWriter{checksum: checksum}
+	w.buf.WriteString(binlogMagic)
+
+	body := make([]byte, 2+50+4+1)
+	binary.LittleEndian.PutUint16(body, 4)
+	copy(body[2:52], serverVersion)
+	body[56] = binlogHeaderLen
+	postHeaderLens := make([]byte, 40)
+	postHeaderLens[binlogQueryEvent-1] = binlogQueryPostHeaderLen
+	body = append(body, postHeaderLens...)
+	alg := byte(0)
+	if checksum {
+		alg = binlogChecksumCRC32
+	}
+	body = append(body, alg)
+	w.event(binlogFormatDescription, time.Unix(1700000000, 0), body, true)
+
+	return w
+}
+
+func (w *binlogWriter) event(eventType byte, ts time.Time, body []byte, fde bool) {
+	size := binlogHeaderLen + len(body)
+	if w.checksum || fde {
+		size += binlogChecksumLen
+	}
+	header := make([]byte, binlogHeaderLen)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(ts.Unix()))
+	header[4] = eventType
+	binary.LittleEndian.PutUint32(header[9:13], uint32(size))
+	binary.LittleEndian.PutUint32(header[13:17], uint32(w.buf.Len()+size))
+
+	event := append(header, body...)
+	if w.checksum || fde {
+		event = binary.LittleEndian.AppendUint32(event, crc32.ChecksumIEEE(event))
+	}
+	w.buf.Write(event)
+}
+
+func (w *binlogWriter) query(ts time.Time, db string, stmt string) {
+	body := make([]byte, binlogQueryPostHeaderLen)
+	body[8] = byte(len(db))
+	statusVars := []byte{0x00, 0x00, 0x00, 0x00, 0x00}
+	binary.LittleEndian.PutUint16(body[11:13], uint16(len(statusVars)))
+	body = append(body, statusVars...)
+	body = append(body, db...)
+	body = append(body, 0)
+	body = append(body, stmt...)
+	w.event(binlogQueryEvent, ts, body, false)
+}
+
+func TestBinlogDir_ReadStatements(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first := newBinlogWriter("8.0.36", true)
+	first.query(ts, "shop", "GRANT SELECT ON orders TO 'app'@'%'")
+	first.event(16, ts, make([]byte, 8), false) // XID event
+	first.query(ts.Add(time.Minute), "", "CREATE USER 'bob'@'%' IDENTIFIED BY 'x'")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "binlog.000001"), first.buf.Bytes(), 0o600))
+
+	second := newBinlogWriter("5.7.44-log", false)
+	second.query(ts.Add(time.Hour), "", "DROP USER 'bob'@'%'")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "binlog.000002"), second.buf.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "binlog.index"), []byte("./binlog.000001\n"), 0o600))
+
+	d := &BinlogDir{Path: dir}
+	stmts, next, err := d.ReadStatements(BinlogPosition{}, 1)
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	require.Equal(t, "shop", stmts[0].Database)
+	require.Equal(t, "GRANT SELECT ON orders TO 'app'@'%'", stmts[0].Statement)
+	require.Equal(t, ts, stmts[0].Timestamp)
+	require.Equal(t, stmts[0].Next, next)
+
+	stmts, next, err = d.ReadStatements(next, 10)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "CREATE USER 'bob'@'%' IDENTIFIED BY 'x'", stmts[0].Statement)
+	require.Equal(t, "DROP USER 'bob'@'%'", stmts[1].Statement)
+	require.Equal(t, "binlog.000002", stmts[1].Position.File)
+	require.Equal(t, BinlogPosition{File: "binlog.000002", Pos: uint32(second.buf.Len())}, next)
+
+	stmts, again, err := d.ReadStatements(next, 10)
+	require.NoError(t, err)
+	require.Empty(t, stmts)
+	require.Equal(t, next, again)
+
+	// A partly written event is left for the next read.
+	second.query(ts.Add(2*time.Hour), "", "DROP USER 'app'@'%'")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "binlog.000002"), second.buf.Bytes()[:second.buf.Len()-3], 0o600))
+	stmts, again, err = d.ReadStatements(next, 10)
+	require.NoError(t, err)
+	require.Empty(t, stmts)
+	require.Equal(t, next, again)
+}
+
+func TestParseBinlogPosition(t *testing.T) {
+	p, err := ParseBinlogPosition("mysql-bin.000042:1234")
+	require.NoError(t, err)
+	require.Equal(t, BinlogPosition{File: "mysql-bin.000042", Pos: 1234}, p)
+	require.Equal(t, "mysql-bin.000042:1234", p.String())
+
+	p, err = ParseBinlogPosition("mysql-bin.000042")
+	require.NoError(t, err)
+	require.Equal(t, BinlogPosition{File: "mysql-bin.000042", Pos: 4}, p)
+
+	p, err = ParseBinlogPosition("")
+	require.NoError(t, err)
+	require.True(t, p.IsZero())
+
+	_, err = ParseBinlogPosition("mysql-bin.000042:abc")
+	require.Error(t, err)
+}
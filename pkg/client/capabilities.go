@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+type Flavor string
+
+const (
+	FlavorMySQL   Flavor = "mysql"
+	FlavorMariaDB Flavor = "mariadb"
+)
+
+// Capability names a server feature that entitlements and syncers can depend on.
+type Capability string
+
+const (
+	CapabilityRoles        Capability = "roles"
+	CapabilityGlobalGrants Capability = "global_grants"
+)
+
+// ServerCapabilities describes the access control features of the connected server. It is probed once from
+// the server version and the grant tables visible in information_schema.
+type ServerCapabilities struct {
+	Flavor  Flavor
+	Version string
+	// Roles is set when the server supports CREATE ROLE and role grants.
+	Roles bool
+	// DefaultRoles is set when default roles are stored in mysql.default_roles.
+	DefaultRoles bool
+	// GlobalGrants is set when dynamic privileges are stored in mysql.global_grants.
+	GlobalGrants bool
+	// GlobalPriv is set when MariaDB stores account privileges as JSON in mysql.global_priv and mysql.user is a
+	// view over it.
+	GlobalPriv bool
+	// FoldsNameCase is set when lower_case_table_names is 1 or 2, so database and table names are compared
+	// without regard to case.
+	FoldsNameCase bool
+}
+
+// Has reports whether the server supports the capability. The empty capability is always supported.
+func (s ServerCapabilities) Has(capability Capability) bool {
+	switch capability {
+	case "":
+		return true
+	case CapabilityRoles:
+		return s.Roles
+	case CapabilityGlobalGrants:
+		return s.GlobalGrants
+	default:
+		return false
+	}
+}
+
+type serverVersion struct {
+	major int
+	minor int
+	patch int
+}
+
+func (v serverVersion) atLeast(major, minor, patch int) bool {
+	if v.major != major {
+		return v.major > major
+	}
+	if v.minor != minor {
+		return v.minor > minor
+	}
+	return v.patch >= patch
+}
+
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseServerVersion parses @@version into its flavor and numeric version. MariaDB may report a 5.5.5- prefix
+// for compatibility with old replication clients.
+func parseServerVersion(version string, versionComment string) (Flavor, serverVersion, error) {
+	flavor := FlavorMySQL
+	if strings.Contains(strings.ToLower(version), "mariadb") || strings.Contains(strings.ToLower(versionComment), "mariadb") {
+		flavor = FlavorMariaDB
+		version = strings.TrimPrefix(version, "5.5.5-")
+	}
+
+	m := versionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return "", serverVersion{}, fmt.Errorf("unable to parse server version %q", version)
+	}
+
+	var sv serverVersion
+	sv.major, _ = strconv.Atoi(m[1])
+	sv.minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		sv.patch, _ = strconv.Atoi(m[3])
+	}
+
+	return flavor, sv, nil
+}
+
+// newServerCapabilities derives the capabilities from the server version and the set of mysql schema tables
+// found in information_schema. When the tables could not be inspected, tables is nil and the version decides.
+func newServerCapabilities(version string, versionComment string, tables map[string]bool) (ServerCapabilities, error) {
+	flavor, sv, err := parseServerVersion(version, versionComment)
+	if err != nil {
+		return ServerCapabilities{}, err
+	}
+
+	hasTable := func(name string) bool {
+		if tables == nil {
+			return true
+		}
+		return tables[name]
+	}
+
+	caps := ServerCapabilities{
+		Flavor:  flavor,
+		Version: version,
+	}
+
+	switch flavor {
+	case FlavorMySQL:
+		if !sv.atLeast(5, 7, 0) {
+			return ServerCapabilities{}, fmt.Errorf("%s is not a supported version of MySQL", version)
+		}
+		caps.Roles = sv.atLeast(8, 0, 0) && hasTable("role_edges")
+		caps.DefaultRoles = caps.Roles && hasTable("default_roles")
+		caps.GlobalGrants = sv.atLeast(8, 0, 0) && hasTable("global_grants")
+
+	case FlavorMariaDB:
+		if !sv.atLeast(10, 0, 0) {
+			return ServerCapabilities{}, fmt.Errorf("%s is not a supported version of MariaDB", version)
+		}
+		caps.Roles = sv.atLeast(10, 0, 5) && hasTable("roles_mapping")
+		caps.GlobalPriv = sv.atLeast(10, 4, 0) && hasTable("global_priv")
+	}
+
+	return caps, nil
+}
+
+// probeCapabilities inspects the connected server. Failing to read information_schema or
+// @@lower_case_table_names is not fatal; the version alone is used instead, and names are compared with their
+// case.
+func (c *Client) probeCapabilities(ctx context.Context) (ServerCapabilities, error) {
+	l := ctxzap.Extract(ctx)
+
+	var info struct {
+		Version        string `db:"version"`
+		VersionComment string `db:"version_comment"`
+	}
+	err := c.pool().GetContext(ctx, &info, "SELECT @@version version, @@version_comment version_comment")
+	if err != nil {
+		return ServerCapabilities{}, err
+	}
+
+	var tableNames []string
+	err = c.pool().SelectContext(
+		ctx,
+		&tableNames,
+		`SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = 'mysql' AND TABLE_NAME IN
+			('role_edges', 'default_roles', 'global_grants', 'roles_mapping', 'global_priv')`,
+	)
+	var tables map[string]bool
+	if err != nil {
+		l.Warn("unable to inspect grant tables, using server version to detect capabilities", zap.Error(err))
+	} else if len(tableNames) > 0 {
+		tables = make(map[string]bool)
+		for _, t := range tableNames {
+			tables[strings.ToLower(t)] = true
+		}
+	}
+
+	caps, err := newServerCapabilities(info.Version, info.VersionComment, tables)
+	if err != nil {
+		return ServerCapabilities{}, err
+	}
+
+	var lowerCaseTableNames int
+	if err := c.pool().GetContext(ctx, &lowerCaseTableNames, "SELECT @@lower_case_table_names"); err != nil {
+		l.Warn("unable to read lower_case_table_names, comparing names with their case", zap.Error(err))
+	}
+	caps.FoldsNameCase = lowerCaseTableNames != 0
+
+	return caps, nil
+}
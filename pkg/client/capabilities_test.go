@@ -0,0 +1,97 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newServerCapabilities(t *testing.T) {
+	allTables := map[string]bool{"role_edges": true, "default_roles": true, "global_grants": true}
+
+	tests := []struct {
+		name           string
+		version        string
+		versionComment string
+		tables         map[string]bool
+		want           ServerCapabilities
+		wantErr        bool
+	}{
+		{
+			name:    "mysql 5.7",
+			version: "5.7.44-log",
+			want:    ServerCapabilities{Flavor: FlavorMySQL, Version: "5.7.44-log"},
+		},
+		{
+			name:    "mysql 8.0",
+			version: "8.0.13",
+			tables:  allTables,
+			want:    ServerCapabilities{Flavor: FlavorMySQL, Version: "8.0.13", Roles: true, DefaultRoles: true, GlobalGrants: true},
+		},
+		{
+			name:    "mysql 8.4 lts",
+			version: "8.4.2",
+			tables:  allTables,
+			want:    ServerCapabilities{Flavor: FlavorMySQL, Version: "8.4.2", Roles: true, DefaultRoles: true, GlobalGrants: true},
+		},
+		{
+			name:    "mysql 9.x",
+			version: "9.1.0",
+			want:    ServerCapabilities{Flavor: FlavorMySQL, Version: "9.1.0", Roles: true, DefaultRoles: true, GlobalGrants: true},
+		},
+		{
+			name:    "mysql 8.0 without visible role tables",
+			version: "8.0.36",
+			tables:  map[string]bool{"global_grants": true},
+			want:    ServerCapabilities{Flavor: FlavorMySQL, Version: "8.0.36", GlobalGrants: true},
+		},
+		{
+			name:           "mariadb",
+			version:        "10.11.6-MariaDB",
+			versionComment: "mariadb.org binary distribution",
+			want:           ServerCapabilities{Flavor: FlavorMariaDB, Version: "10.11.6-MariaDB", Roles: true, GlobalPriv: true},
+		},
+		{
+			name:           "mariadb 10.3 before global_priv",
+			version:        "10.3.39-MariaDB",
+			versionComment: "MariaDB Server",
+			tables:         map[string]bool{"roles_mapping": true},
+			want:           ServerCapabilities{Flavor: FlavorMariaDB, Version: "10.3.39-MariaDB", Roles: true},
+		},
+		{
+			name:           "mariadb with replication prefix",
+			version:        "5.5.5-10.6.12-MariaDB-log",
+			versionComment: "MariaDB Server",
+			tables:         map[string]bool{"roles_mapping": true, "global_priv": true},
+			want:           ServerCapabilities{Flavor: FlavorMariaDB, Version: "5.5.5-10.6.12-MariaDB-log", Roles: true, GlobalPriv: true},
+		},
+		{
+			name:    "mysql 5.6",
+			version: "5.6.51",
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			version: "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newServerCapabilities(tt.version, tt.versionComment, tt.tables)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServerCapabilities_Has(t *testing.T) {
+	caps := ServerCapabilities{Roles: true}
+	require.True(t, caps.Has(""))
+	require.True(t, caps.Has(CapabilityRoles))
+	require.False(t, caps.Has(CapabilityGlobalGrants))
+}
@@ -6,14 +6,10 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
-var supportedReleases = []string{
-	"8", "5.7", "10.",
-}
-
 type dbResourceID struct {
 	ResourceTypeID  string
 	DatabaseName    string
@@ -111,17 +107,33 @@ func newDbResourceID(in string) (dbResourceID, error) {
 }
 
 type Client struct {
-	db      *sqlx.DB
-	version string
+	db *sqlx.DB
+	// dsn is the parsed DSN, for connections outside of the pool.
+	dsn          *mysql.Config
+	capabilities ServerCapabilities
+	snapshots    snapshotState
+	plan         planState
+	retry        RetryPolicy
+	// dryRun logs statements that change accounts or privileges instead of running them.
+	dryRun bool
+	// safeguards are checked before statements that change accounts or privileges are planned, logged or run.
+	safeguards *Safeguards
+}
+
+// Capabilities returns the access control features supported by the server.
+func (c *Client) Capabilities() ServerCapabilities {
+	return c.capabilities
 }
 
-func (c *Client) IsVersion8() bool {
-	return strings.HasPrefix(c.version, "8.")
+// Close ends the current snapshot and closes the connection pool.
+func (c *Client) Close() error {
+	c.EndSnapshot(context.Background())
+	return c.db.Close()
 }
 
 func (c *Client) ValidateConnection(ctx context.Context) error {
 	var v int
-	err := c.db.GetContext(ctx, &v, "SELECT 1;")
+	err := c.pool().GetContext(ctx, &v, "SELECT 1;")
 	if err != nil {
 		return err
 	}
@@ -130,6 +142,11 @@ func (c *Client) ValidateConnection(ctx context.Context) error {
 }
 
 func New(ctx context.Context, dsn string) (*Client, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := sqlx.Connect("mysql", dsn)
 	if err != nil {
 		return nil, err
@@ -140,27 +157,19 @@ func New(ctx context.Context, dsn string) (*Client, error) {
 	db.SetMaxIdleConns(1)
 
 	c := &Client{
-		db: db,
+		db:    db,
+		dsn:   cfg,
+		retry: DefaultRetryPolicy,
+		safeguards: &Safeguards{
+			protected: DefaultProtectedAccounts,
+		},
 	}
 
-	si, err := c.GetServerInfo(ctx)
+	caps, err := c.probeCapabilities(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	supported := false
-	for _, sv := range supportedReleases {
-		if strings.HasPrefix(si.Version, sv) {
-			supported = true
-			break
-		}
-	}
-
-	if !supported {
-		return nil, fmt.Errorf("%s is not a supported version of MySQL", c.version)
-	}
-
-	c.version = si.Version
+	c.capabilities = caps
 
 	return c, nil
 }
@@ -48,7 +48,7 @@ func (c *Client) ListColumns(ctx context.Context, parentResourceID *v2.ResourceI
 		args = append(args, offset)
 	}
 
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	rows, err := c.reader().QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -83,20 +83,25 @@ func (c *Client) ListColumns(ctx context.Context, parentResourceID *v2.ResourceI
 	return ret, nextPageToken, nil
 }
 
-// If the privilege is "grant", it grants SELECT, INSERT, UPDATE, and REFERENCES privileges.
+// GrantColumnPrivilege grants a column privilege. MySQL keeps the grant option for column privileges on the
+// table, so GRANT OPTION is not a column privilege and is granted with GrantTablePrivilege instead.
 func (c *Client) GrantColumnPrivilege(ctx context.Context, table string, column string, user string, privilege string) error {
+	if strings.EqualFold(privilege, GrantOptionPrivilege) {
+		return fmt.Errorf("column %s.%s: grant GRANT OPTION on the table instead", table, column)
+	}
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
 		return fmt.Errorf("invalid user format: %s", user)
 	}
-	userGrant := fmt.Sprintf("%s'@'%s", userSplit[0], userSplit[1])
-
-	var privileges []string
-	if strings.ToLower(privilege) == "grant" {
-		privileges = []string{"SELECT", "INSERT", "UPDATE", "REFERENCES"}
-	} else {
-		privileges = []string{strings.ToUpper(privilege)}
+	userEsc, err := escapeMySQLUserHost(userSplit[0])
+	if err != nil {
+		return err
 	}
+	hostEsc, err := escapeMySQLUserHost(userSplit[1])
+	if err != nil {
+		return err
+	}
+	userGrant := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
 
 	escapedTable, err := escapeMySQLIdent(table)
 	if err != nil {
@@ -107,31 +112,32 @@ func (c *Client) GrantColumnPrivilege(ctx context.Context, table string, column
 		return err
 	}
 
-	var privilegeClauses []string
-	for _, priv := range privileges {
-		privilegeClauses = append(privilegeClauses, fmt.Sprintf("%s (%s)", priv, escapedColumn))
-	}
-	privilegesSQL := strings.Join(privilegeClauses, ", ")
+	privilegeSQL := fmt.Sprintf("%s (%s)", strings.ToUpper(privilege), escapedColumn)
 
-	query := fmt.Sprintf("GRANT %s ON %s TO '%s'", privilegesSQL, escapedTable, userGrant)
+	query := fmt.Sprintf("GRANT %s ON %s TO %s", privilegeSQL, escapedTable, userGrant)
 
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
+// RevokeColumnPrivilege revokes a column privilege. As with GrantColumnPrivilege, GRANT OPTION is revoked with
+// RevokeTablePrivilege instead.
 func (c *Client) RevokeColumnPrivilege(ctx context.Context, table string, column string, user string, privilege string) error {
+	if strings.EqualFold(privilege, GrantOptionPrivilege) {
+		return fmt.Errorf("column %s.%s: revoke GRANT OPTION on the table instead", table, column)
+	}
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
 		return fmt.Errorf("invalid user format: %s", user)
 	}
-	userRevoke := fmt.Sprintf("%s'@'%s", userSplit[0], userSplit[1])
-
-	var privileges []string
-	if strings.ToLower(privilege) == "grant" {
-		privileges = []string{"SELECT", "INSERT", "UPDATE", "REFERENCES"}
-	} else {
-		privileges = []string{strings.ToUpper(privilege)}
+	userEsc, err := escapeMySQLUserHost(userSplit[0])
+	if err != nil {
+		return err
 	}
+	hostEsc, err := escapeMySQLUserHost(userSplit[1])
+	if err != nil {
+		return err
+	}
+	userRevoke := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
 
 	escapedTable, err := escapeMySQLIdent(table)
 	if err != nil {
@@ -142,14 +148,9 @@ func (c *Client) RevokeColumnPrivilege(ctx context.Context, table string, column
 		return err
 	}
 
-	var privilegeClauses []string
-	for _, priv := range privileges {
-		privilegeClauses = append(privilegeClauses, fmt.Sprintf("%s (%s)", priv, escapedColumn))
-	}
-	privilegesSQL := strings.Join(privilegeClauses, ", ")
+	privilegeSQL := fmt.Sprintf("%s (%s)", strings.ToUpper(privilege), escapedColumn)
 
-	query := fmt.Sprintf("REVOKE %s ON %s FROM '%s'", privilegesSQL, escapedTable, userRevoke)
+	query := fmt.Sprintf("REVOKE %s ON %s FROM %s", privilegeSQL, escapedTable, userRevoke)
 
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 )
 
@@ -40,7 +41,7 @@ func (c *Client) ListDatabases(ctx context.Context, pager *Pager) ([]*DbModel, s
 		args = append(args, offset)
 	}
 
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	rows, err := c.reader().QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -73,6 +74,23 @@ func (c *Client) ListDatabases(ctx context.Context, pager *Pager) ([]*DbModel, s
 	return ret, nextPageToken, nil
 }
 
+// GetDatabase returns the database with the given resource ID.
+func (c *Client) GetDatabase(ctx context.Context, resourceID *v2.ResourceId) (*DbModel, error) {
+	id, err := newDbResourceID(resourceID.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbModel DbModel
+	err = c.reader().GetContext(ctx, &dbModel, "SELECT SCHEMA_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME=?", id.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	dbModel.ID = id.Database().String()
+
+	return &dbModel, nil
+}
+
 func (c *Client) GrantDatabasePrivilege(ctx context.Context, database string, user string, privilege string) error {
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
@@ -94,8 +112,7 @@ func (c *Client) GrantDatabasePrivilege(ctx context.Context, database string, us
 	}
 
 	query := fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.ToUpper(privilege), escapedDB, userGrant)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
 func (c *Client) RevokeDatabasePrivilege(ctx context.Context, database string, user string, privilege string) error {
@@ -119,6 +136,5 @@ func (c *Client) RevokeDatabasePrivilege(ctx context.Context, database string, u
 	}
 
 	query := fmt.Sprintf("REVOKE %s ON %s.* FROM %s", strings.ToUpper(privilege), escapedDB, userRevoke)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
@@ -0,0 +1,453 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// ExportFilter selects the accounts and privileges ExportGrants returns.
+type ExportFilter struct {
+	// Databases limits the export to database, table, column and routine privileges in these databases. Global,
+	// proxy and role grants are left out, along with accounts that have no privileges in them.
+	Databases []string
+	// Users limits the export to these accounts, given as user or user@host.
+	Users []string
+	// Passwords includes the password hashes in CREATE USER statements. Without them, password accounts are
+	// created locked.
+	Passwords bool
+}
+
+func (f *ExportFilter) includesDatabase(database string) bool {
+	if len(f.Databases) == 0 {
+		return true
+	}
+	for _, d := range f.Databases {
+		if d == database {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ExportFilter) includesAccount(user string, host string) bool {
+	if len(f.Users) == 0 {
+		return true
+	}
+	for _, u := range f.Users {
+		if u == user || u == user+"@"+host {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountExport is the SQL that recreates an account and its privileges.
+type AccountExport struct {
+	User string
+	Host string
+	Role bool
+	// Create is the CREATE USER or CREATE ROLE statement.
+	Create string
+	// Grants are the GRANT and SET DEFAULT ROLE statements, which may refer to other exported accounts.
+	Grants []string
+}
+
+// accountCredentials are the authentication columns CREATE USER needs.
+type accountCredentials struct {
+	Plugin string `db:"plugin"`
+	// AuthHex is authentication_string in hex, as password hashes can hold any byte.
+	AuthHex string `db:"auth_hex"`
+}
+
+// quoteString quotes s as an SQL string literal.
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdent quotes an identifier. Unlike escapeMySQLIdent it accepts any name, as the statements are printed
+// rather than run.
+func quoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteAccount quotes an account name. MariaDB roles have an empty host and are referenced by name alone.
+func (c *Client) quoteAccount(user string, host string) string {
+	if host == "" && c.Capabilities().Flavor == FlavorMariaDB {
+		return quoteString(user)
+	}
+	return quoteString(user) + "@" + quoteString(host)
+}
+
+// exportPrivileges turns the privileges of a grant table into GRANT keywords, like CREATE TEMPORARY TABLES or
+// ALTER ROUTINE. The grant privilege is reported separately, as it becomes WITH GRANT OPTION.
+func exportPrivileges(privs string) ([]string, bool) {
+	var ret []string
+	grantOption := false
+	for _, p := range strings.Split(privs, ",") {
+		p = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(p), "_", " "))
+		switch p {
+		case "":
+			continue
+		case "GRANT":
+			grantOption = true
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret, grantOption
+}
+
+// grantStatement returns a GRANT statement, or an empty string when there is nothing to grant.
+func grantStatement(privs []string, grantOption bool, on string, to string) string {
+	if len(privs) == 0 {
+		if !grantOption {
+			return ""
+		}
+		privs = []string{"USAGE"}
+	}
+
+	stmt := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(privs, ", "), on, to)
+	if grantOption {
+		stmt += " WITH GRANT OPTION"
+	}
+	return stmt
+}
+
+// createStatement returns the CREATE USER or CREATE ROLE statement for an account.
+func (c *Client) createStatement(ctx context.Context, u *User, passwords bool) (string, error) {
+	account := c.quoteAccount(u.User, u.Host)
+	if u.UserType == RoleType {
+		return fmt.Sprintf("CREATE ROLE IF NOT EXISTS %s", account), nil
+	}
+
+	var creds accountCredentials
+	err := c.reader().GetContext(
+		ctx,
+		&creds,
+		`SELECT plugin, HEX(authentication_string) AS auth_hex FROM mysql.user WHERE User = ? AND Host = ?`,
+		u.User,
+		u.Host,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// An empty plugin is how MariaDB and older MySQL releases record mysql_native_password.
+	plugin := creds.Plugin
+	if plugin == "" {
+		plugin = "mysql_native_password"
+	}
+	if !validIdent.MatchString(plugin) {
+		return "", fmt.Errorf("invalid authentication plugin: %s", plugin)
+	}
+
+	identified, auth := "IDENTIFIED WITH", "AS"
+	if c.Capabilities().Flavor == FlavorMariaDB {
+		identified, auth = "IDENTIFIED VIA", "USING"
+	}
+	stmt := fmt.Sprintf("CREATE USER IF NOT EXISTS %s %s %s", account, identified, plugin)
+
+	locked := u.IsLocked()
+	_, isPassword := passwordPlugins[creds.Plugin]
+	switch {
+	case isPassword && !passwords:
+		// Without its password the account could log in with none, so it is locked until one is set.
+		locked = true
+	case creds.AuthHex != "":
+		stmt += fmt.Sprintf(" %s 0x%s", auth, creds.AuthHex)
+	}
+	if locked {
+		stmt += " ACCOUNT LOCK"
+	}
+
+	return stmt, nil
+}
+
+// exportAccount returns the statements that recreate an account, or nil when the filter leaves nothing to grant
+// it.
+func (c *Client) exportAccount(ctx context.Context, u *User, filter *ExportFilter) (*AccountExport, error) {
+	caps := c.Capabilities()
+	to := c.quoteAccount(u.User, u.Host)
+	var grants []string
+	add := func(stmt string) {
+		if stmt == "" {
+			return
+		}
+		// A grant the safeguards don't allow, like one of a denied privilege, is kept as a comment so replaying
+		// the script doesn't make it.
+		if c.CheckStatement(stmt) != nil {
+			stmt = "-- " + stmt
+		}
+		grants = append(grants, stmt)
+	}
+
+	if len(filter.Databases) == 0 {
+		privs, grantOption := exportPrivileges(u.Privs)
+		add(grantStatement(privs, grantOption, "*.*", to))
+
+		if caps.GlobalGrants || caps.GlobalPriv {
+			globalGrants, err := c.ListGlobalGrants(ctx, u.User, u.Host)
+			if err != nil {
+				return nil, err
+			}
+			var dynamic, dynamicWithGrant []string
+			for _, g := range globalGrants {
+				if g.WithGrant == "Y" {
+					dynamicWithGrant = append(dynamicWithGrant, g.Priv)
+				} else {
+					dynamic = append(dynamic, g.Priv)
+				}
+			}
+			sort.Strings(dynamic)
+			sort.Strings(dynamicWithGrant)
+			add(grantStatement(dynamic, false, "*.*", to))
+			if len(dynamicWithGrant) > 0 {
+				add(grantStatement(dynamicWithGrant, true, "*.*", to))
+			}
+		}
+	}
+
+	databaseGrants, _, err := c.ListDatabaseGrants(ctx, u.User, u.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range databaseGrants {
+		if !filter.includesDatabase(g.Database) {
+			continue
+		}
+		privs, grantOption := exportPrivileges(g.Privs)
+		add(grantStatement(privs, grantOption, quoteIdent(g.Database)+".*", to))
+	}
+
+	tableGrants, _, err := c.ListTableGrants(ctx, u.User, u.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range tableGrants {
+		if !filter.includesDatabase(g.Database) {
+			continue
+		}
+		privs, grantOption := exportPrivileges(g.Privs)
+		add(grantStatement(privs, grantOption, quoteIdent(g.Database)+"."+quoteIdent(g.Table), to))
+	}
+
+	columnGrants, _, err := c.ListColumnGrants(ctx, u.User, u.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range columnGrantStatements(columnGrants, filter, to) {
+		add(stmt)
+	}
+
+	routineGrants, _, err := c.ListRoutineGrants(ctx, u.User, u.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range routineGrants {
+		if !filter.includesDatabase(g.Database) {
+			continue
+		}
+		privs, grantOption := exportPrivileges(g.Privs)
+		on := fmt.Sprintf("%s %s.%s", strings.ToUpper(g.Type), quoteIdent(g.Database), quoteIdent(g.Routine))
+		add(grantStatement(privs, grantOption, on, to))
+	}
+
+	if len(filter.Databases) == 0 {
+		proxyGrants, _, err := c.ListProxyGrants(ctx, u.User, u.Host, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range proxyGrants {
+			add(grantStatement([]string{"PROXY"}, g.WithGrant == 1, c.quoteAccount(g.ProxiedUser, g.ProxiedHost), to))
+		}
+
+		if caps.Roles {
+			roleStmts, err := c.roleGrantStatements(ctx, u, to)
+			if err != nil {
+				return nil, err
+			}
+			grants = append(grants, roleStmts...)
+		}
+	}
+
+	if len(filter.Databases) > 0 && len(grants) == 0 {
+		return nil, nil
+	}
+
+	create, err := c.createStatement(ctx, u, filter.Passwords)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountExport{
+		User:   u.User,
+		Host:   u.Host,
+		Role:   u.UserType == RoleType,
+		Create: create,
+		Grants: grants,
+	}, nil
+}
+
+// columnGrantStatements groups the column privileges of an account by table, into statements like
+// GRANT SELECT (`a`, `b`), UPDATE (`b`) ON `db`.`t` TO account.
+func columnGrantStatements(columnGrants []*ColumnGrant, filter *ExportFilter, to string) []string {
+	type tableColumns struct {
+		on      string
+		privs   []string
+		columns map[string][]string
+	}
+
+	var tables []*tableColumns
+	byTable := make(map[string]*tableColumns)
+	for _, g := range columnGrants {
+		if !filter.includesDatabase(g.Database) {
+			continue
+		}
+		t, ok := byTable[g.TableID()]
+		if !ok {
+			t = &tableColumns{
+				on:      quoteIdent(g.Database) + "." + quoteIdent(g.Table),
+				columns: make(map[string][]string),
+			}
+			byTable[g.TableID()] = t
+			tables = append(tables, t)
+		}
+
+		privs, _ := exportPrivileges(g.Privs)
+		for _, p := range privs {
+			if _, ok := t.columns[p]; !ok {
+				t.privs = append(t.privs, p)
+			}
+			t.columns[p] = append(t.columns[p], quoteIdent(g.Column))
+		}
+	}
+
+	var ret []string
+	for _, t := range tables {
+		var privs []string
+		for _, p := range t.privs {
+			privs = append(privs, fmt.Sprintf("%s (%s)", p, strings.Join(t.columns[p], ", ")))
+		}
+		if stmt := grantStatement(privs, false, t.on, to); stmt != "" {
+			ret = append(ret, stmt)
+		}
+	}
+	return ret
+}
+
+// defaultRole is a row of mysql.default_roles.
+type defaultRole struct {
+	User string `db:"DEFAULT_ROLE_USER"`
+	Host string `db:"DEFAULT_ROLE_HOST"`
+}
+
+// roleGrantStatements returns the statements that grant an account its roles and set its default roles.
+// Grants required:
+//
+//	GRANT SELECT (USER, HOST, DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER) ON mysql.default_roles TO user@host;
+func (c *Client) roleGrantStatements(ctx context.Context, u *User, to string) ([]string, error) {
+	roleGrants, _, err := c.ListRoleGrants(ctx, u.User, u.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, g := range roleGrants {
+		stmt := fmt.Sprintf("GRANT %s TO %s", c.quoteAccount(g.FromUser, g.FromHost), to)
+		if g.WithGrant == "Y" {
+			stmt += " WITH ADMIN OPTION"
+		}
+		ret = append(ret, stmt)
+	}
+
+	caps := c.Capabilities()
+	switch {
+	case caps.Flavor == FlavorMariaDB:
+		if u.DefaultRole != "" {
+			ret = append(ret, fmt.Sprintf("SET DEFAULT ROLE %s FOR %s", quoteString(u.DefaultRole), to))
+		}
+
+	case caps.DefaultRoles:
+		var defaults []*defaultRole
+		err = c.reader().SelectContext(
+			ctx,
+			&defaults,
+			`SELECT DEFAULT_ROLE_USER, DEFAULT_ROLE_HOST FROM mysql.default_roles WHERE USER = ? AND HOST = ?`,
+			u.User,
+			u.Host,
+		)
+		if err != nil {
+			return nil, err
+		}
+		var roles []string
+		for _, d := range defaults {
+			roles = append(roles, c.quoteAccount(d.User, d.Host))
+		}
+		if len(roles) > 0 {
+			ret = append(ret, fmt.Sprintf("SET DEFAULT ROLE %s TO %s", strings.Join(roles, ", "), to))
+		}
+	}
+
+	return ret, nil
+}
+
+// ExportGrants returns the statements that recreate the accounts on the server and their privileges, like
+// SHOW GRANTS does for a single account. Roles come first, so replaying every Create before any Grants
+// rebuilds the accounts in an order the server accepts. Protected accounts are left out, and partial revokes are
+// not exported.
+func (c *Client) ExportGrants(ctx context.Context, filter *ExportFilter) ([]*AccountExport, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("exporting grants")
+
+	var ret []*AccountExport
+	for _, userType := range []string{RoleType, UserType} {
+		pager := &Pager{Size: MaxPageSize}
+		for {
+			accounts, nextPageToken, err := c.ListUsers(ctx, userType, pager, false)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, a := range accounts {
+				if !filter.includesAccount(a.User, a.Host) {
+					continue
+				}
+				if err := c.safeguards.CheckAccount(a.User + "@" + a.Host); err != nil {
+					l.Debug("not exporting protected account", zap.String("user", a.User), zap.String("host", a.Host))
+					continue
+				}
+
+				u, err := c.GetUser(ctx, a.User, a.Host)
+				if err != nil {
+					return nil, err
+				}
+				export, err := c.exportAccount(ctx, u, filter)
+				if err != nil {
+					l.Error(
+						"unable to export account",
+						zap.Error(err),
+						zap.String("user", a.User),
+						zap.String("host", a.Host),
+					)
+					return nil, err
+				}
+				if export != nil {
+					ret = append(ret, export)
+				}
+			}
+
+			if nextPageToken == "" {
+				break
+			}
+			pager.Token = nextPageToken
+		}
+	}
+
+	return ret, nil
+}
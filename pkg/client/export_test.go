@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPrivileges(t *testing.T) {
+	privs, grantOption := exportPrivileges("select,create_temporary_tables,grant,show_databases,")
+	require.Equal(t, []string{"SELECT", "CREATE TEMPORARY TABLES", "SHOW DATABASES"}, privs)
+	require.True(t, grantOption)
+
+	privs, grantOption = exportPrivileges("Execute,Alter Routine")
+	require.Equal(t, []string{"EXECUTE", "ALTER ROUTINE"}, privs)
+	require.False(t, grantOption)
+}
+
+func TestGrantStatement(t *testing.T) {
+	require.Equal(t, "", grantStatement(nil, false, "*.*", "'app'@'%'"))
+	require.Equal(t, "GRANT USAGE ON *.* TO 'app'@'%' WITH GRANT OPTION", grantStatement(nil, true, "*.*", "'app'@'%'"))
+	require.Equal(
+		t,
+		"GRANT SELECT, INSERT ON `shop`.* TO 'app'@'%'",
+		grantStatement([]string{"SELECT", "INSERT"}, false, "`shop`.*", "'app'@'%'"),
+	)
+}
+
+func TestColumnGrantStatements(t *testing.T) {
+	grants := []*ColumnGrant{
+		{Database: "shop", Table: "orders", Column: "id", Privs: "Select"},
+		{Database: "shop", Table: "orders", Column: "total", Privs: "Select,Update"},
+		{Database: "hr", Table: "salaries", Column: "amount", Privs: "Select"},
+	}
+
+	got := columnGrantStatements(grants, &ExportFilter{}, "'app'@'%'")
+	require.Equal(t, []string{
+		"GRANT SELECT (`id`, `total`), UPDATE (`total`) ON `shop`.`orders` TO 'app'@'%'",
+		"GRANT SELECT (`amount`) ON `hr`.`salaries` TO 'app'@'%'",
+	}, got)
+
+	got = columnGrantStatements(grants, &ExportFilter{Databases: []string{"hr"}}, "'app'@'%'")
+	require.Equal(t, []string{"GRANT SELECT (`amount`) ON `hr`.`salaries` TO 'app'@'%'"}, got)
+}
+
+func TestQuoteAccount(t *testing.T) {
+	mysql := &Client{capabilities: ServerCapabilities{Flavor: FlavorMySQL, Roles: true}}
+	mariaDB := &Client{capabilities: ServerCapabilities{Flavor: FlavorMariaDB, Roles: true}}
+
+	require.Equal(t, `'o''brien'@'10.0.0.%'`, mysql.quoteAccount("o'brien", "10.0.0.%"))
+	require.Equal(t, `''@''`, mysql.quoteAccount("", ""))
+	require.Equal(t, `'dba'`, mariaDB.quoteAccount("dba", ""))
+	require.Equal(t, `'dba'@'localhost'`, mariaDB.quoteAccount("dba", "localhost"))
+}
+
+func TestExportFilter(t *testing.T) {
+	f := &ExportFilter{Users: []string{"app", "report@10.0.0.%"}}
+	require.True(t, f.includesAccount("app", "localhost"))
+	require.True(t, f.includesAccount("report", "10.0.0.%"))
+	require.False(t, f.includesAccount("report", "%"))
+	require.True(t, f.includesDatabase("anything"))
+}
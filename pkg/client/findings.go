@@ -0,0 +1,307 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// FindingType names a risky account configuration.
+type FindingType string
+
+const (
+	// FindingAnonymousUser is an account with an empty user name, which any client name can log in as.
+	FindingAnonymousUser FindingType = "anonymous_user"
+	// FindingPrivilegedWildcardHost is an account with global privileges that can connect from any host.
+	FindingPrivilegedWildcardHost FindingType = "privileged_wildcard_host"
+	// FindingEmptyPassword is a password-authenticated account without a password.
+	FindingEmptyPassword FindingType = "empty_password"
+	// FindingNativePassword is an account using mysql_native_password, which MySQL deprecated in 8.0.34.
+	FindingNativePassword FindingType = "mysql_native_password"
+	// FindingShadowedByAnonymous is an account that an anonymous account takes precedence over for some clients.
+	FindingShadowedByAnonymous FindingType = "shadowed_by_anonymous_user"
+	// FindingOrphanedGrant is a table, column or routine privilege on an object that was dropped.
+	FindingOrphanedGrant FindingType = "orphaned_grant"
+)
+
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+)
+
+// Finding is a risk found on a single account.
+type Finding struct {
+	Type     FindingType `json:"type"`
+	Severity string      `json:"severity"`
+	User     string      `json:"user"`
+	Host     string      `json:"host"`
+	Message  string      `json:"message"`
+}
+
+// passwordPlugins are the authentication plugins that check a password stored in authentication_string.
+// An empty plugin is how MariaDB and older MySQL releases record mysql_native_password.
+var passwordPlugins = map[string]struct{}{
+	"":                      {},
+	"caching_sha2_password": {},
+	"mysql_native_password": {},
+	"sha256_password":       {},
+	"mysql_old_password":    {},
+	"ed25519":               {},
+	"parsec":                {},
+}
+
+// AuditData is what auditing accounts reads from the server: the accounts, the accounts holding global privileges
+// outside mysql.user, and the orphaned grants. It is read in a few queries, so a sync audits every account
+// without querying for each one.
+type AuditData struct {
+	flavor   Flavor
+	accounts map[AccountName]*User
+	// anonymous holds the accounts with an empty user name.
+	anonymous []*User
+	// globalGrants holds the accounts with privileges in mysql.global_grants or mysql.global_priv.
+	globalGrants map[AccountName]struct{}
+	orphans      map[AccountName][]*OrphanedGrant
+}
+
+// LoadAuditData reads what auditing every account needs.
+func (c *Client) LoadAuditData(ctx context.Context) (*AuditData, error) {
+	return c.loadAuditData(ctx, "", nil)
+}
+
+// LoadUserAuditData reads what auditing the accounts of one user name, on every host, needs.
+func (c *Client) LoadUserAuditData(ctx context.Context, user string) (*AuditData, error) {
+	return c.loadAuditData(ctx, `WHERE User = ? OR User = ''`, []interface{}{user})
+}
+
+// loadAuditData reads the audit data of the accounts matching filter. The anonymous accounts must match it, since
+// they shadow other accounts. Orphaned grants that can't be read are logged and left out.
+func (c *Client) loadAuditData(ctx context.Context, filter string, args []interface{}) (*AuditData, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("loading audit data")
+
+	sb, err := c.userQuery()
+	if err != nil {
+		return nil, err
+	}
+	_, err = sb.WriteString(filter)
+	if err != nil {
+		return nil, err
+	}
+	var users []*User
+	err = c.reader().SelectContext(ctx, &users, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &AuditData{
+		flavor:       c.Capabilities().Flavor,
+		accounts:     make(map[AccountName]*User, len(users)),
+		globalGrants: make(map[AccountName]struct{}),
+		orphans:      make(map[AccountName][]*OrphanedGrant),
+	}
+	for _, u := range users {
+		d.accounts[AccountName{User: u.User, Host: u.Host}] = u
+		if u.User == "" {
+			d.anonymous = append(d.anonymous, u)
+		}
+	}
+
+	holders, err := c.listGlobalGrantHolders(ctx, filter, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range holders {
+		d.globalGrants[a] = struct{}{}
+	}
+
+	var orphans []*OrphanedGrant
+	if filter == "" {
+		orphans, err = c.ListOrphanedGrants(ctx, nil)
+	} else {
+		for _, u := range users {
+			var o []*OrphanedGrant
+			o, err = c.ListOrphanedGrants(ctx, &AccountName{User: u.User, Host: u.Host})
+			if err != nil {
+				break
+			}
+			orphans = append(orphans, o...)
+		}
+	}
+	if err != nil {
+		l.Warn("unable to list orphaned grants", zap.Error(err))
+	}
+	for _, o := range orphans {
+		a := AccountName{User: o.User, Host: o.Host}
+		d.orphans[a] = append(d.orphans[a], o)
+	}
+
+	return d, nil
+}
+
+// listGlobalGrantHolders returns the accounts matching filter that hold privileges in mysql.global_grants or, on
+// MariaDB, mysql.global_priv.
+func (c *Client) listGlobalGrantHolders(ctx context.Context, filter string, args []interface{}) ([]AccountName, error) {
+	caps := c.Capabilities()
+	var ret []AccountName
+	switch {
+	case caps.GlobalPriv:
+		var rows []struct {
+			User   string        `db:"User"`
+			Host   string        `db:"Host"`
+			Access sql.NullInt64 `db:"access"`
+		}
+		q := `SELECT User, Host, CAST(JSON_VALUE(Priv, '$.access') AS UNSIGNED) AS access FROM mysql.global_priv ` + filter
+		err := c.reader().SelectContext(ctx, &rows, q, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if len(mariaDBAccessPrivileges(uint64(r.Access.Int64))) > 0 {
+				ret = append(ret, AccountName{User: r.User, Host: r.Host})
+			}
+		}
+
+	case caps.GlobalGrants:
+		var rows []struct {
+			User string `db:"USER"`
+			Host string `db:"HOST"`
+		}
+		err := c.reader().SelectContext(ctx, &rows, `SELECT DISTINCT USER, HOST FROM mysql.global_grants `+filter, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			ret = append(ret, AccountName{User: r.User, Host: r.Host})
+		}
+	}
+
+	return ret, nil
+}
+
+// AuditUser returns the findings for the account user@host, or nil when the data doesn't hold the account. Locked
+// accounts and roles cannot log in and have no findings.
+func (d *AuditData) AuditUser(ctx context.Context, user string, host string) []*Finding {
+	account := AccountName{User: user, Host: host}
+	u, ok := d.accounts[account]
+	if !ok || u.UserType != UserType || u.IsLocked() {
+		return nil
+	}
+
+	var ret []*Finding
+	add := func(t FindingType, severity string, format string, args ...interface{}) {
+		ret = append(ret, &Finding{
+			Type:     t,
+			Severity: severity,
+			User:     u.User,
+			Host:     u.Host,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if u.User == "" {
+		add(FindingAnonymousUser, SeverityHigh, "anonymous account ''@'%s' accepts any user name", u.Host)
+	}
+
+	if u.Host == "%" || u.Host == "" {
+		_, dynamic := d.globalGrants[account]
+		if dynamic || len(u.GetPrivs(ctx)) > 0 {
+			add(FindingPrivilegedWildcardHost, SeverityHigh, "account with global privileges can connect from any host")
+		}
+	}
+
+	if _, ok := passwordPlugins[u.Plugin]; ok && u.EmptyPassword {
+		add(FindingEmptyPassword, SeverityHigh, "account has no password")
+	}
+
+	if u.Plugin == "mysql_native_password" && d.flavor == FlavorMySQL {
+		add(FindingNativePassword, SeverityMedium, "mysql_native_password is deprecated, use caching_sha2_password")
+	}
+
+	if u.User != "" {
+		for _, a := range d.anonymous {
+			if a.IsLocked() || !shadows(a.Host, u.Host) {
+				continue
+			}
+			add(
+				FindingShadowedByAnonymous,
+				SeverityMedium,
+				"the anonymous account ''@'%s' is matched before this account for clients from that host",
+				a.Host,
+			)
+		}
+	}
+
+	for _, o := range d.orphans[account] {
+		name := fmt.Sprintf("%s.%s", o.Database, o.Object)
+		if o.Column != "" {
+			name += "." + o.Column
+		}
+		add(
+			FindingOrphanedGrant,
+			SeverityMedium,
+			"%s on %s %s, which no longer exists and would be granted again if it is recreated",
+			strings.Join(o.Privileges(), ", "),
+			o.ObjectType,
+			name,
+		)
+	}
+
+	return ret
+}
+
+// shadows reports whether an anonymous account on anonHost is tried before a named account on host for some of
+// the clients the named account would accept. That is the case when anonHost sorts first and every client it
+// accepts is also accepted by host, which is checked by matching host against anonHost as a literal.
+func shadows(anonHost string, host string) bool {
+	if CompareAccounts("", anonHost, "x", host) >= 0 {
+		return false
+	}
+
+	return HostMatches(host, anonHost)
+}
+
+// Audit returns the findings for every user account on the server, ordered by account.
+func (c *Client) Audit(ctx context.Context) ([]*Finding, error) {
+	d, err := c.LoadAuditData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]AccountName, 0, len(d.accounts))
+	for a := range d.accounts {
+		accounts = append(accounts, a)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].User != accounts[j].User {
+			return accounts[i].User < accounts[j].User
+		}
+		return accounts[i].Host < accounts[j].Host
+	})
+
+	var ret []*Finding
+	for _, a := range accounts {
+		ret = append(ret, d.AuditUser(ctx, a.User, a.Host)...)
+	}
+
+	return ret, nil
+}
+
+// FindingTypes returns the distinct finding types, in order.
+func FindingTypes(findings []*Finding) []string {
+	seen := make(map[FindingType]struct{})
+	var ret []string
+	for _, f := range findings {
+		if _, ok := seen[f.Type]; ok {
+			continue
+		}
+		seen[f.Type] = struct{}{}
+		ret = append(ret, string(f.Type))
+	}
+
+	return ret
+}
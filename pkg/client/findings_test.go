@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_shadows(t *testing.T) {
+	require.True(t, shadows("localhost", "%"))
+	require.True(t, shadows("10.2.3.4", "10.2.%"))
+	require.True(t, shadows("10.2.%", "10.%"))
+	require.False(t, shadows("localhost", "localhost"))
+	require.False(t, shadows("%", "localhost"))
+	require.False(t, shadows("db.example.com", "10.%"))
+}
+
+func TestFindingTypes(t *testing.T) {
+	findings := []*Finding{
+		{Type: FindingAnonymousUser},
+		{Type: FindingShadowedByAnonymous},
+		{Type: FindingAnonymousUser},
+	}
+	require.Equal(t, []string{"anonymous_user", "shadowed_by_anonymous_user"}, FindingTypes(findings))
+}
+
+func TestAuditData_AuditUser(t *testing.T) {
+	ctx := context.Background()
+	anon := &User{UserType: UserType, User: "", Host: "localhost", Plugin: "caching_sha2_password"}
+	d := &AuditData{
+		flavor: FlavorMySQL,
+		accounts: map[AccountName]*User{
+			{User: "", Host: "localhost"}:  anon,
+			{User: "app", Host: "%"}:       {UserType: UserType, User: "app", Host: "%", Plugin: "mysql_native_password"},
+			{User: "ops", Host: "%"}:       {UserType: UserType, User: "ops", Host: "%", Plugin: "caching_sha2_password", EmptyPassword: true},
+			{User: "locked", Host: "%"}:    {UserType: UserType, User: "locked", Host: "%", AccountLocked: "Y", EmptyPassword: true},
+			{User: "admin", Host: "%"}:     {UserType: RoleType, User: "admin", Host: "%", Privs: "select,"},
+			{User: "backup", Host: "10.%"}: {UserType: UserType, User: "backup", Host: "10.%", Plugin: "caching_sha2_password"},
+		},
+		anonymous:    []*User{anon},
+		globalGrants: map[AccountName]struct{}{{User: "app", Host: "%"}: {}},
+		orphans: map[AccountName][]*OrphanedGrant{
+			{User: "backup", Host: "10.%"}: {{ObjectType: TableType, Database: "shop", Object: "orders", Privs: "Select"}},
+		},
+	}
+
+	types := func(user, host string) []string {
+		return FindingTypes(d.AuditUser(ctx, user, host))
+	}
+	require.Equal(t, []string{"anonymous_user"}, types("", "localhost"))
+	require.Equal(t, []string{"privileged_wildcard_host", "mysql_native_password", "shadowed_by_anonymous_user"}, types("app", "%"))
+	require.Equal(t, []string{"empty_password", "shadowed_by_anonymous_user"}, types("ops", "%"))
+	require.Equal(t, []string{"orphaned_grant"}, types("backup", "10.%"))
+	require.Empty(t, types("locked", "%"))
+	require.Empty(t, types("admin", "%"))
+	require.Empty(t, types("missing", "%"))
+}
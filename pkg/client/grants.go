@@ -2,12 +2,16 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
 )
 
+// GrantOptionPrivilege is the privilege keyword that lets an account grant its privileges on an object to others.
+const GrantOptionPrivilege = "GRANT OPTION"
+
 type GlobalGrant struct {
 	User      string `db:"USER"`
 	Host      string `db:"HOST"`
@@ -15,18 +19,23 @@ type GlobalGrant struct {
 	WithGrant string `db:"WITH_GRANT_OPTION"`
 }
 
-// ListGlobalGrants returns the set of grants from the mysql.global_grants
+// ListGlobalGrants returns the set of grants from the mysql.global_grants. On MariaDB, the privileges are read
+// from the access mask in mysql.global_priv instead.
 // Required MySQL grant for connector:
 //
 //	GRANT SELECT (USER, HOST, PRIV, WITH_GRANT_OPTION) ON mysql.global_grants TO user@host;
 func (c *Client) ListGlobalGrants(ctx context.Context, user string, host string) ([]*GlobalGrant, error) {
+	if c.Capabilities().Flavor == FlavorMariaDB {
+		return c.listMariaDBGlobalGrants(ctx, user, host)
+	}
+
 	l := ctxzap.Extract(ctx)
 	l.Debug("checking global grants")
 
 	q := `SELECT USER, HOST, PRIV, WITH_GRANT_OPTION FROM mysql.global_grants WHERE USER=? AND HOST=?`
 
 	var ret []*GlobalGrant
-	err := c.db.SelectContext(ctx, &ret, q, user, host)
+	err := c.reader().SelectContext(ctx, &ret, q, user, host)
 	if err != nil {
 		return nil, err
 	}
@@ -58,14 +67,15 @@ func (u *DatabaseGrant) GetPrivs(ctx context.Context) map[string]struct{} {
 	return ret
 }
 
-// ListDatabaseGrants returns a single user@host row and its perms
+// ListDatabaseGrants returns a page of the database privileges of user@host, ordered by database, along with
+// the key to read the next page after, which is empty on the last page.
 // Grants required:
 //
 //	GRANT SELECT (Host, User, Db, Select_priv, Insert_priv, Update_priv,  Delete_priv, Create_priv, Drop_priv,
 //				  Grant_priv, References_priv, Index_priv, Alter_priv, Create_tmp_table_priv, Lock_tables_priv,
 //				  Execute_priv, Create_view_priv, Show_view_priv, Create_routine_priv,
 //				  Alter_routine_priv, Event_priv, Trigger_priv) ON mysql.db TO user@host;
-func (c *Client) ListDatabaseGrants(ctx context.Context, user string, host string) ([]*DatabaseGrant, error) {
+func (c *Client) ListDatabaseGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*DatabaseGrant, string, error) {
 	q := `SELECT
     		User,
     		Host,
@@ -92,12 +102,21 @@ func (c *Client) ListDatabaseGrants(ctx context.Context, user string, host strin
               CASE WHEN Trigger_priv = 'Y' THEN 'trigger,' ELSE '' END
             ) AS privs
 		FROM mysql.db WHERE User = ? AND Host = ?`
+	q, args, err := page.query(q, []interface{}{user, host}, "Db")
+	if err != nil {
+		return nil, "", err
+	}
 
 	var ret []*DatabaseGrant
-	err := c.db.SelectContext(ctx, &ret, q, user, host)
+	err = c.reader().SelectContext(ctx, &ret, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	n, next, err := page.end(len(ret), func(i int) []string { return []string{ret[i].Database} })
+	if err != nil {
+		return nil, "", err
+	}
+	ret = ret[:n]
 
 	for i, r := range ret {
 		ret[i].Id = dbResourceID{
@@ -106,7 +125,7 @@ func (c *Client) ListDatabaseGrants(ctx context.Context, user string, host strin
 		}.String()
 	}
 
-	return ret, nil
+	return ret, next, nil
 }
 
 type TableGrant struct {
@@ -123,7 +142,7 @@ func (u *TableGrant) GetPrivs(ctx context.Context) map[string]struct{} {
 
 	privs := strings.Split(u.Privs, ",")
 	for _, p := range privs {
-		priv := strings.ReplaceAll(strings.TrimSpace(p), " ", "_")
+		priv := strings.TrimSpace(p)
 		if priv == "" {
 			continue
 		}
@@ -134,11 +153,12 @@ func (u *TableGrant) GetPrivs(ctx context.Context) map[string]struct{} {
 	return ret
 }
 
-// ListTableGrants returns a single user@host row and its perms
+// ListTableGrants returns a page of the table privileges of user@host, ordered by table, along with the key to
+// read the next page after, which is empty on the last page.
 // Grants required:
 //
 //	GRANT SELECT (Host, User, Db, Table_priv, Table_name) ON mysql.tables_priv TO user@host;
-func (c *Client) ListTableGrants(ctx context.Context, user string, host string) ([]*TableGrant, error) {
+func (c *Client) ListTableGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*TableGrant, string, error) {
 	q := `SELECT
     		User,
     		Host,
@@ -146,12 +166,21 @@ func (c *Client) ListTableGrants(ctx context.Context, user string, host string)
     		Table_name,
     		Table_priv
 		FROM mysql.tables_priv WHERE User = ? AND Host = ?`
+	q, args, err := page.query(q, []interface{}{user, host}, "Db", "Table_name")
+	if err != nil {
+		return nil, "", err
+	}
 
 	var ret []*TableGrant
-	err := c.db.SelectContext(ctx, &ret, q, user, host)
+	err = c.reader().SelectContext(ctx, &ret, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	n, next, err := page.end(len(ret), func(i int) []string { return []string{ret[i].Database, ret[i].Table} })
+	if err != nil {
+		return nil, "", err
+	}
+	ret = ret[:n]
 
 	for i, r := range ret {
 		ret[i].Id = dbResourceID{
@@ -161,7 +190,7 @@ func (c *Client) ListTableGrants(ctx context.Context, user string, host string)
 		}.String()
 	}
 
-	return ret, nil
+	return ret, next, nil
 }
 
 type ColumnGrant struct {
@@ -188,7 +217,7 @@ func (u *ColumnGrant) GetPrivs(ctx context.Context) map[string]struct{} {
 
 	privs := strings.Split(u.Privs, ",")
 	for _, p := range privs {
-		priv := strings.ReplaceAll(strings.TrimSpace(p), " ", "_")
+		priv := strings.TrimSpace(p)
 		if priv == "" {
 			continue
 		}
@@ -199,12 +228,14 @@ func (u *ColumnGrant) GetPrivs(ctx context.Context) map[string]struct{} {
 	return ret
 }
 
-// ListColumnGrants returns a single user@host row and its perms
+// ListColumnGrants returns a page of the column privileges of user@host, ordered by column, along with the key
+// to read the next page after, which is empty on the last page. A page holds every column of the tables it
+// reaches, so it can hold more rows than its size.
 // Grants required:
 //
 //	GRANT SELECT (Host, User, Db, Column_name, Column_priv, Table_name) ON mysql.columns_priv TO user@host;
-func (c *Client) ListColumnGrants(ctx context.Context, user string, host string) ([]*ColumnGrant, error) {
-	q := `SELECT
+func (c *Client) ListColumnGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*ColumnGrant, string, error) {
+	base := `SELECT
     		User,
     		Host,
     		Db,
@@ -212,11 +243,44 @@ func (c *Client) ListColumnGrants(ctx context.Context, user string, host string)
     		Column_name,
     		Column_priv
 		FROM mysql.columns_priv WHERE User = ? AND Host = ?`
+	q, args, err := page.query(base, []interface{}{user, host}, "Db", "Table_name", "Column_name")
+	if err != nil {
+		return nil, "", err
+	}
 
 	var ret []*ColumnGrant
-	err := c.db.SelectContext(ctx, &ret, q, user, host)
+	err = c.reader().SelectContext(ctx, &ret, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	key := func(g *ColumnGrant) []string { return []string{g.Database, g.Table, g.Column} }
+	n, next, err := page.end(len(ret), func(i int) []string { return key(ret[i]) })
+	if err != nil {
+		return nil, "", err
+	}
+	ret = ret[:n]
+
+	// The rest of the last table's columns are read too, so the grants on a table's columns are never split
+	// between pages.
+	if next != "" {
+		last := ret[n-1]
+		var rest []*ColumnGrant
+		err = c.reader().SelectContext(
+			ctx,
+			&rest,
+			base+` AND Db = ? AND Table_name = ? AND Column_name > ? ORDER BY Column_name`,
+			user, host, last.Database, last.Table, last.Column,
+		)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(rest) > 0 {
+			ret = append(ret, rest...)
+			next, err = grantPageKey(key(ret[len(ret)-1]))
+			if err != nil {
+				return nil, "", err
+			}
+		}
 	}
 
 	for i, r := range ret {
@@ -228,9 +292,102 @@ func (c *Client) ListColumnGrants(ctx context.Context, user string, host string)
 		}.String()
 	}
 
+	return ret, next, nil
+}
+
+// ListGrantedColumns returns the columns of a table, given as db.table, that any account holds a privilege on,
+// in order.
+// Grants required:
+//
+//	GRANT SELECT (Db, Column_name, Column_priv, Table_name) ON mysql.columns_priv TO user@host;
+func (c *Client) ListGrantedColumns(ctx context.Context, table string, privilege string) ([]string, error) {
+	db, name, ok := strings.Cut(table, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+
+	var ret []string
+	err := c.reader().SelectContext(
+		ctx,
+		&ret,
+		`SELECT DISTINCT Column_name FROM mysql.columns_priv
+		WHERE Db = ? AND Table_name = ? AND FIND_IN_SET(?, LOWER(Column_priv)) > 0 ORDER BY Column_name`,
+		db, name, strings.ToLower(privilege),
+	)
+	if err != nil {
+		return nil, err
+	}
 	return ret, nil
 }
 
+type RoutineGrant struct {
+	Id       string `db:"-"`
+	User     string `db:"User"`
+	Host     string `db:"Host"`
+	Database string `db:"Db"`
+	Routine  string `db:"Routine_name"`
+	Type     string `db:"Routine_type"`
+	Privs    string `db:"Proc_priv"`
+}
+
+// GetPrivs parses the routine grant data from mysql.
+func (u *RoutineGrant) GetPrivs(ctx context.Context) map[string]struct{} {
+	ret := make(map[string]struct{})
+
+	privs := strings.Split(u.Privs, ",")
+	for _, p := range privs {
+		priv := strings.TrimSpace(p)
+		if priv == "" {
+			continue
+		}
+
+		ret[priv] = struct{}{}
+	}
+
+	return ret
+}
+
+// ListRoutineGrants returns a page of the routine privileges of user@host, ordered by routine, along with the
+// key to read the next page after, which is empty on the last page.
+// Grants required:
+//
+//	GRANT SELECT (Host, User, Db, Routine_name, Routine_type, Proc_priv) ON mysql.procs_priv TO user@host;
+func (c *Client) ListRoutineGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*RoutineGrant, string, error) {
+	q := `SELECT
+    		User,
+    		Host,
+    		Db,
+    		Routine_name,
+    		Routine_type,
+    		Proc_priv
+		FROM mysql.procs_priv WHERE User = ? AND Host = ?`
+	q, args, err := page.query(q, []interface{}{user, host}, "Db", "Routine_name", "Routine_type")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var ret []*RoutineGrant
+	err = c.reader().SelectContext(ctx, &ret, q, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	n, next, err := page.end(len(ret), func(i int) []string { return []string{ret[i].Database, ret[i].Routine, ret[i].Type} })
+	if err != nil {
+		return nil, "", err
+	}
+	ret = ret[:n]
+
+	for i, r := range ret {
+		ret[i].Id = dbResourceID{
+			ResourceTypeID: RoutineType,
+			DatabaseName:   r.Database,
+			ResourceName:   r.Routine,
+		}.String()
+	}
+
+	return ret, next, nil
+}
+
 type ProxyGrant struct {
 	Id          string `db:"-"`
 	User        string `db:"User"`
@@ -240,11 +397,12 @@ type ProxyGrant struct {
 	WithGrant   int    `db:"With_grant"`
 }
 
-// ListProxyGrants returns a single user@host row and its perms
+// ListProxyGrants returns a page of the proxy privileges of user@host, ordered by proxied account, along with
+// the key to read the next page after, which is empty on the last page.
 // Grants required:
 //
 //	GRANT SELECT (Host, User, Db, Column_name, Column_priv, Table_name) ON mysql.columns_priv TO user@host;
-func (c *Client) ListProxyGrants(ctx context.Context, user string, host string) ([]*ProxyGrant, error) {
+func (c *Client) ListProxyGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*ProxyGrant, string, error) {
 	q := `SELECT
     		User,
     		Host,
@@ -252,12 +410,21 @@ func (c *Client) ListProxyGrants(ctx context.Context, user string, host string)
     		Proxied_host,
     		With_grant
 		FROM mysql.proxies_priv WHERE User = ? AND Host = ?`
+	q, args, err := page.query(q, []interface{}{user, host}, "Proxied_user", "Proxied_host")
+	if err != nil {
+		return nil, "", err
+	}
 
 	var out []*ProxyGrant
-	err := c.db.SelectContext(ctx, &out, q, user, host)
+	err = c.reader().SelectContext(ctx, &out, q, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	n, next, err := page.end(len(out), func(i int) []string { return []string{out[i].ProxiedUser, out[i].ProxiedHost} })
+	if err != nil {
+		return nil, "", err
+	}
+	out = out[:n]
 
 	var ret []*ProxyGrant
 	for _, r := range out {
@@ -270,7 +437,7 @@ func (c *Client) ListProxyGrants(ctx context.Context, user string, host string)
 		if r.ProxiedUser == "" && r.ProxiedHost == "" {
 			s, err := c.GetServerInfo(ctx)
 			if err != nil {
-				return nil, err
+				return nil, "", err
 			}
 			newR.Id = s.ID
 			ret = append(ret, newR)
@@ -278,13 +445,19 @@ func (c *Client) ListProxyGrants(ctx context.Context, user string, host string)
 		}
 		u, err := c.GetUser(ctx, r.ProxiedUser, r.ProxiedHost)
 		if err != nil {
-			return nil, err
+			ctxzap.Extract(ctx).Error(
+				"unable to fetch proxied user. Ignoring grant",
+				zap.Error(err),
+				zap.String("proxied_user", r.ProxiedUser),
+				zap.String("proxied_host", r.ProxiedHost),
+			)
+			continue
 		}
 		newR.Id = u.GetID()
 		ret = append(ret, newR)
 	}
 
-	return ret, nil
+	return ret, next, nil
 }
 
 type RoleGrant struct {
@@ -296,24 +469,45 @@ type RoleGrant struct {
 	WithGrant string `db:"WITH_ADMIN_OPTION"`
 }
 
-// ListRoleGrants returns a single user@host row and its role edges
+// ListRoleGrants returns a page of the roles granted to user@host, ordered by role, along with the key to read
+// the next page after, which is empty on the last page. The FROM side of an edge is the granted role, and Id is
+// set to its resource ID. On MariaDB, the edges are read from mysql.roles_mapping.
 // Grants required:
 //
 //	GRANT SELECT (FROM_HOST, FROM_USER, TO_HOST, TO_USER, WITH_ADMIN_OPTION) ON mysql.role_edges TO user@host;
-func (c *Client) ListRoleGrants(ctx context.Context, user string, host string) ([]*RoleGrant, error) {
-	q := `SELECT
+func (c *Client) ListRoleGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*RoleGrant, string, error) {
+	var out []*RoleGrant
+	var err error
+	if c.Capabilities().Flavor == FlavorMariaDB {
+		out, err = c.listMariaDBRoleGrants(ctx, user, host, page)
+	} else {
+		q := `SELECT
 			FROM_HOST,
 			FROM_USER,
 			TO_HOST,
 			TO_USER,
 			WITH_ADMIN_OPTION
-		FROM mysql.role_edges WHERE FROM_USER = ? AND FROM_HOST = ?`
-
-	var out []*RoleGrant
-	err := c.db.SelectContext(ctx, &out, q, user, host)
+		FROM mysql.role_edges WHERE TO_USER = ? AND TO_HOST = ?`
+		var args []interface{}
+		q, args, err = page.query(q, []interface{}{user, host}, "FROM_USER", "FROM_HOST")
+		if err != nil {
+			return nil, "", err
+		}
+		err = c.reader().SelectContext(ctx, &out, q, args...)
+	}
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	// MariaDB roles have no host.
+	key := func(i int) []string { return []string{out[i].FromUser, out[i].FromHost} }
+	if c.Capabilities().Flavor == FlavorMariaDB {
+		key = func(i int) []string { return []string{out[i].FromUser} }
 	}
+	n, next, err := page.end(len(out), key)
+	if err != nil {
+		return nil, "", err
+	}
+	out = out[:n]
 
 	var ret []*RoleGrant
 	for _, r := range out {
@@ -323,13 +517,13 @@ func (c *Client) ListRoleGrants(ctx context.Context, user string, host string) (
 
 		newR := r
 
-		u, err := c.GetUser(ctx, r.ToUser, r.ToHost)
+		u, err := c.GetUser(ctx, r.FromUser, r.FromHost)
 		if err != nil {
 			ctxzap.Extract(ctx).Error(
-				"unable to fetch to user for role. Ignoring grant",
+				"unable to fetch granted role. Ignoring grant",
 				zap.Error(err),
-				zap.String("to_user", r.ToUser),
-				zap.String("to_host", r.ToHost),
+				zap.String("from_user", r.FromUser),
+				zap.String("from_host", r.FromHost),
 			)
 			continue
 		}
@@ -337,5 +531,5 @@ func (c *Client) ListRoleGrants(ctx context.Context, user string, host string) (
 		ret = append(ret, newR)
 	}
 
-	return ret, nil
+	return ret, next, nil
 }
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// patternWeight returns the weight MySQL gives a host or user value when it orders accounts, as get_sort() does
+// in sql_acl.cc. A literal value weighs 128 and an empty value 0. A pattern weighs more the longer its literal
+// prefix is, and a lone % weighs least of all.
+func patternWeight(pattern string) int {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '%', '_':
+			pos := i + 1
+			if pos != 1 || pattern[i] != '%' || len(pattern) != 1 {
+				pos++
+			}
+			if pos > 127 {
+				pos = 127
+			}
+			return pos
+		}
+	}
+
+	if pattern == "" {
+		return 0
+	}
+	return 128
+}
+
+// hasNetmask reports whether the host is an IPv4 address with a netmask or prefix length.
+func hasNetmask(host string) bool {
+	return strings.Contains(host, "/")
+}
+
+// CompareAccounts orders two accounts the way the server does before matching a connection against them. It
+// returns a negative number when a is more specific and is tried first. Hosts are compared first, then users,
+// so a named user sorts before the anonymous user on the same host.
+func CompareAccounts(userA, hostA, userB, hostB string) int {
+	if wa, wb := patternWeight(hostA), patternWeight(hostB); wa != wb {
+		return wb - wa
+	}
+	// From 8.0.23, an IP address without a netmask is more specific than one with a netmask.
+	if na, nb := hasNetmask(hostA), hasNetmask(hostB); na != nb {
+		if na {
+			return 1
+		}
+		return -1
+	}
+	if wa, wb := patternWeight(userA), patternWeight(userB); wa != wb {
+		return wb - wa
+	}
+
+	return 0
+}
+
+// SortAccounts sorts accounts from most to least specific.
+func SortAccounts(accounts []*User) {
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return CompareAccounts(accounts[i].User, accounts[i].Host, accounts[j].User, accounts[j].Host) < 0
+	})
+}
+
+// HostMatches reports whether a host pattern from mysql.user matches a client host name or IP address. Patterns
+// use LIKE wildcards, compared case-insensitively, or are an IPv4 address with a netmask (10.0.0.0/255.0.0.0)
+// or prefix length (10.0.0.0/8). An empty pattern matches any host.
+func HostMatches(pattern string, host string) bool {
+	if pattern == "" || pattern == "%" {
+		return true
+	}
+
+	if hasNetmask(pattern) {
+		return netmaskMatches(pattern, host)
+	}
+
+	return likeMatches(strings.ToLower(pattern), strings.ToLower(host))
+}
+
+func netmaskMatches(pattern string, host string) bool {
+	parts := strings.SplitN(pattern, "/", 2)
+
+	ip := net.ParseIP(host).To4()
+	network := net.ParseIP(parts[0]).To4()
+	if ip == nil || network == nil {
+		return false
+	}
+
+	var mask net.IPMask
+	if bits, err := strconv.Atoi(parts[1]); err == nil {
+		if bits < 0 || bits > 32 {
+			return false
+		}
+		mask = net.CIDRMask(bits, 32)
+	} else {
+		m := net.ParseIP(parts[1]).To4()
+		if m == nil {
+			return false
+		}
+		mask = net.IPMask(m)
+	}
+
+	return ip.Mask(mask).Equal(network.Mask(mask))
+}
+
+// likeMatches matches s against a LIKE pattern, where % matches any run of characters, _ matches one character
+// and \ escapes the next character.
+func likeMatches(pattern string, s string) bool {
+	if pattern == "" {
+		return s == ""
+	}
+
+	switch pattern[0] {
+	case '%':
+		for i := 0; i <= len(s); i++ {
+			if likeMatches(pattern[1:], s[i:]) {
+				return true
+			}
+		}
+		return false
+	case '_':
+		return s != "" && likeMatches(pattern[1:], s[1:])
+	case '\\':
+		if len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+	}
+
+	return s != "" && s[0] == pattern[0] && likeMatches(pattern[1:], s[1:])
+}
+
+// SortHosts returns the hosts of a single user from most to least specific.
+func SortHosts(hosts []string) []string {
+	ret := make([]string, len(hosts))
+	copy(ret, hosts)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return CompareAccounts("", ret[i], "", ret[j]) < 0
+	})
+
+	return ret
+}
+
+// AccountCandidate is an account considered for a connection, in the order the server tries them.
+type AccountCandidate struct {
+	User    string `json:"user"`
+	Host    string `json:"host"`
+	Locked  bool   `json:"locked"`
+	Matches bool   `json:"matches"`
+}
+
+// AccountMatch describes which account a connection authenticates as.
+type AccountMatch struct {
+	User string `json:"user"`
+	From string `json:"from"`
+	// Account is the first matching account, or nil when the connection is refused.
+	Account    *AccountCandidate   `json:"account"`
+	Candidates []*AccountCandidate `json:"candidates"`
+}
+
+// MatchAccount returns the account the server picks for user connecting from a client host, which is an IP
+// address or a host name. Accounts are tried from most to least specific, and the anonymous user matches any
+// user name. IP address patterns and netmasks only match IP addresses.
+func MatchAccount(accounts []*User, user string, from string) *AccountMatch {
+	sorted := make([]*User, len(accounts))
+	copy(sorted, accounts)
+	SortAccounts(sorted)
+
+	ret := &AccountMatch{
+		User: user,
+		From: from,
+	}
+	isIP := net.ParseIP(from) != nil
+	for _, a := range sorted {
+		if a.User != user && a.User != "" {
+			continue
+		}
+
+		matches := HostMatches(a.Host, from)
+		if matches && !isIP && net.ParseIP(strings.Split(a.Host, "/")[0]) != nil {
+			matches = false
+		}
+
+		candidate := &AccountCandidate{
+			User:    a.User,
+			Host:    a.Host,
+			Locked:  a.IsLocked(),
+			Matches: matches,
+		}
+		ret.Candidates = append(ret.Candidates, candidate)
+		if matches && ret.Account == nil {
+			ret.Account = candidate
+		}
+	}
+
+	return ret
+}
+
+// WhichAccount returns the account the server picks for user connecting from a client IP address or host name.
+func (c *Client) WhichAccount(ctx context.Context, user string, from string) (*AccountMatch, error) {
+	sb, err := c.getUsersQuery()
+	if err != nil {
+		return nil, err
+	}
+	_, err = sb.WriteString(`WHERE User IN (?, '')`)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*User
+	err = c.reader().SelectContext(ctx, &accounts, sb.String(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	return MatchAccount(accounts, user, from), nil
+}
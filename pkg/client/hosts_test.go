@@ -0,0 +1,91 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortAccounts(t *testing.T) {
+	accounts := []*User{
+		{User: "", Host: "%"},
+		{User: "bob", Host: "%"},
+		{User: "bob", Host: "10.%"},
+		{User: "bob", Host: "10.2.%"},
+		{User: "", Host: "localhost"},
+		{User: "bob", Host: "10.0.0.0/255.0.0.0"},
+		{User: "bob", Host: "10.2.3.4"},
+		{User: "bob", Host: "localhost"},
+	}
+	SortAccounts(accounts)
+
+	var got []string
+	for _, a := range accounts {
+		got = append(got, a.User+"@"+a.Host)
+	}
+	require.Equal(t, []string{
+		"bob@10.2.3.4",
+		"bob@localhost",
+		"@localhost",
+		"bob@10.0.0.0/255.0.0.0",
+		"bob@10.2.%",
+		"bob@10.%",
+		"bob@%",
+		"@%",
+	}, got)
+}
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"%", "10.2.3.4", true},
+		{"", "db.example.com", true},
+		{"localhost", "LOCALHOST", true},
+		{"10.2.%", "10.2.3.4", true},
+		{"10.2.%", "10.20.3.4", false},
+		{"%.example.com", "db.example.com", true},
+		{"%.example.com", "example.com", false},
+		{"db_.example.com", "db1.example.com", true},
+		{"db\\_.example.com", "db1.example.com", false},
+		{"10.0.0.0/255.0.0.0", "10.2.3.4", true},
+		{"10.0.0.0/255.255.0.0", "10.2.3.4", false},
+		{"10.2.0.0/16", "10.2.3.4", true},
+		{"10.2.0.0/16", "db.example.com", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, HostMatches(tt.pattern, tt.host), "%s matching %s", tt.pattern, tt.host)
+	}
+}
+
+func TestSortHosts(t *testing.T) {
+	hosts := []string{"%", "10.%", "localhost", "10.0.0.0/8"}
+	require.Equal(t, []string{"localhost", "10.0.0.0/8", "10.%", "%"}, SortHosts(hosts))
+	require.Equal(t, "%", hosts[0])
+}
+
+func TestMatchAccount(t *testing.T) {
+	accounts := []*User{
+		{User: "bob", Host: "%"},
+		{User: "bob", Host: "10.2.%"},
+		{User: "", Host: "localhost"},
+		{User: "alice", Host: "10.2.3.4"},
+		{User: "bob", Host: "10.0.0.0/255.0.0.0"},
+	}
+
+	m := MatchAccount(accounts, "bob", "10.2.3.4")
+	require.Equal(t, "10.0.0.0/255.0.0.0", m.Account.Host)
+	require.Len(t, m.Candidates, 4)
+
+	m = MatchAccount(accounts, "bob", "localhost")
+	require.Equal(t, "", m.Account.User)
+	require.Equal(t, "localhost", m.Account.Host)
+
+	m = MatchAccount(accounts, "bob", "db.example.com")
+	require.Equal(t, "%", m.Account.Host)
+
+	m = MatchAccount(accounts, "carol", "10.9.9.9")
+	require.Nil(t, m.Account)
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// mariaDBAccessBits are the bits of the mysql.global_priv access mask for privileges that MariaDB does not expose
+// as *_priv columns of the mysql.user view. REPLICATION CLIENT became an alias of BINLOG MONITOR in 10.5.2.
+var mariaDBAccessBits = []struct {
+	bit  uint
+	priv string
+}{
+	{20, "BINLOG MONITOR"},
+	{29, "DELETE HISTORY"},
+	{30, "SET USER"},
+	{31, "FEDERATED ADMIN"},
+	{32, "CONNECTION ADMIN"},
+	{33, "READ_ONLY ADMIN"},
+	{34, "REPLICATION SLAVE ADMIN"},
+	{35, "REPLICATION MASTER ADMIN"},
+	{36, "BINLOG ADMIN"},
+	{37, "BINLOG REPLAY"},
+	{38, "SLAVE MONITOR"},
+	{39, "SHOW CREATE ROUTINE"},
+}
+
+// mariaDBAccessPrivileges decodes the privileges in a mysql.global_priv access mask.
+func mariaDBAccessPrivileges(access uint64) []string {
+	var ret []string
+	for _, b := range mariaDBAccessBits {
+		if access&(1<<b.bit) != 0 {
+			ret = append(ret, b.priv)
+		}
+	}
+
+	return ret
+}
+
+// listMariaDBGlobalGrants returns the global privileges of an account that are only found in the access mask of
+// mysql.global_priv. MariaDB has a single global GRANT OPTION, so WithGrant is always N.
+// Required MariaDB grant for connector:
+//
+//	GRANT SELECT (User, Host, Priv) ON mysql.global_priv TO user@host;
+func (c *Client) listMariaDBGlobalGrants(ctx context.Context, user string, host string) ([]*GlobalGrant, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("checking global_priv access")
+
+	var access sql.NullInt64
+	err := c.reader().GetContext(
+		ctx,
+		&access,
+		`SELECT CAST(JSON_VALUE(Priv, '$.access') AS UNSIGNED) FROM mysql.global_priv WHERE User = ? AND Host = ?`,
+		user,
+		host,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ret []*GlobalGrant
+	for _, priv := range mariaDBAccessPrivileges(uint64(access.Int64)) {
+		ret = append(ret, &GlobalGrant{
+			User:      user,
+			Host:      host,
+			Priv:      priv,
+			WithGrant: "N",
+		})
+	}
+
+	return ret, nil
+}
+
+// listMariaDBRoleGrants returns the roles granted to user@host from mysql.roles_mapping. Roles have an empty host.
+// Required MariaDB grant for connector:
+//
+//	GRANT SELECT (Host, User, Role, Admin_option) ON mysql.roles_mapping TO user@host;
+func (c *Client) listMariaDBRoleGrants(ctx context.Context, user string, host string, page *GrantPage) ([]*RoleGrant, error) {
+	q := `SELECT
+			'' AS FROM_HOST,
+			Role AS FROM_USER,
+			Host AS TO_HOST,
+			User AS TO_USER,
+			Admin_option AS WITH_ADMIN_OPTION
+		FROM mysql.roles_mapping WHERE User = ? AND Host = ?`
+	q, args, err := page.query(q, []interface{}{user, host}, "Role")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*RoleGrant
+	err = c.reader().SelectContext(ctx, &out, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
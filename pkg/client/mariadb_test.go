@@ -0,0 +1,19 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mariaDBAccessPrivileges(t *testing.T) {
+	// SELECT and SUPER are read from the mysql.user columns.
+	require.Empty(t, mariaDBAccessPrivileges(1|1<<15))
+
+	require.Equal(t, []string{"BINLOG MONITOR"}, mariaDBAccessPrivileges(1<<20))
+	require.Equal(
+		t,
+		[]string{"READ_ONLY ADMIN", "BINLOG ADMIN", "SLAVE MONITOR"},
+		mariaDBAccessPrivileges(1<<33|1<<36|1<<38),
+	)
+}
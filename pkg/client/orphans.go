@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// OrphanedGrant is a table, column or routine privilege on an object that no longer exists. The server keeps the
+// rows of mysql.tables_priv, mysql.columns_priv and mysql.procs_priv when the object is dropped, and they apply
+// again to any object created later with the same name.
+type OrphanedGrant struct {
+	// ObjectType is table, column or routine.
+	ObjectType string `db:"object_type"`
+	User       string `db:"User"`
+	Host       string `db:"Host"`
+	Database   string `db:"Db"`
+	// Object is the table or routine name.
+	Object string `db:"object_name"`
+	Column string `db:"Column_name"`
+	// RoutineType is PROCEDURE or FUNCTION for routine privileges.
+	RoutineType string `db:"Routine_type"`
+	Privs       string `db:"privs"`
+}
+
+// ID returns the resource ID the grant would have been synced against.
+func (o *OrphanedGrant) ID() string {
+	return dbResourceID{
+		ResourceTypeID:  o.ObjectType,
+		DatabaseName:    o.Database,
+		ResourceName:    o.Object,
+		SubResourceName: o.Column,
+	}.String()
+}
+
+// Privileges returns the upper-cased privileges of the grant, like SELECT or ALTER ROUTINE. The grant option is
+// returned as GRANT OPTION.
+func (o *OrphanedGrant) Privileges() []string {
+	var ret []string
+	for _, p := range strings.Split(o.Privs, ",") {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		switch p {
+		case "":
+			continue
+		case "GRANT":
+			p = "GRANT OPTION"
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// RevokeStatement returns the REVOKE statement that removes the grant.
+func (o *OrphanedGrant) RevokeStatement() (string, error) {
+	userEsc, err := escapeMySQLUserHost(o.User)
+	if err != nil {
+		return "", err
+	}
+	hostEsc, err := escapeMySQLUserHost(o.Host)
+	if err != nil {
+		return "", err
+	}
+	object, err := escapeMySQLIdent(fmt.Sprintf("%s.%s", o.Database, o.Object))
+	if err != nil {
+		return "", err
+	}
+
+	privs := o.Privileges()
+	if len(privs) == 0 {
+		return "", fmt.Errorf("grant on %s has no privileges", o.ID())
+	}
+	switch o.ObjectType {
+	case ColumnType:
+		column, err := escapeMySQLIdent(o.Column)
+		if err != nil {
+			return "", err
+		}
+		for i, p := range privs {
+			privs[i] = fmt.Sprintf("%s (%s)", p, column)
+		}
+	case RoutineType:
+		object = fmt.Sprintf("%s %s", strings.ToUpper(o.RoutineType), object)
+	}
+
+	return fmt.Sprintf("REVOKE %s ON %s FROM '%s'@'%s'", strings.Join(privs, ", "), object, userEsc, hostEsc), nil
+}
+
+// sameName compares a name in a grant table with one in information_schema. The grant tables and
+// information_schema use different collations, so the names are compared as bytes. When the server folds the case
+// of database and table names (lower_case_table_names is 1 or 2), they are lower-cased first, so objects named
+// with a different case in a grant aren't reported as dropped.
+func sameName(a string, b string, foldCase bool) string {
+	if foldCase {
+		return fmt.Sprintf("CAST(LOWER(%s) AS BINARY) = CAST(LOWER(%s) AS BINARY)", a, b)
+	}
+	return fmt.Sprintf("CAST(%s AS BINARY) = CAST(%s AS BINARY)", a, b)
+}
+
+// orphanedGrantsQuery selects the table, column and routine privileges whose object is not in information_schema.
+// Column and routine names are case-insensitive on every server, so they are always compared with folded case.
+func orphanedGrantsQuery(foldCase bool) string {
+	return `SELECT 'table' AS object_type, tp.User, tp.Host, tp.Db, tp.Table_name AS object_name,
+	'' AS Column_name, '' AS Routine_type, tp.Table_priv AS privs
+FROM mysql.tables_priv tp
+WHERE tp.Table_priv != '' AND %[1]s NOT EXISTS (
+	SELECT 1 FROM information_schema.TABLES t
+	WHERE ` + sameName("t.TABLE_SCHEMA", "tp.Db", foldCase) + ` AND ` + sameName("t.TABLE_NAME", "tp.Table_name", foldCase) + `
+)
+UNION ALL
+SELECT 'column', cp.User, cp.Host, cp.Db, cp.Table_name, cp.Column_name, '', cp.Column_priv
+FROM mysql.columns_priv cp
+WHERE cp.Column_priv != '' AND %[2]s NOT EXISTS (
+	SELECT 1 FROM information_schema.COLUMNS c
+	WHERE ` + sameName("c.TABLE_SCHEMA", "cp.Db", foldCase) + ` AND ` + sameName("c.TABLE_NAME", "cp.Table_name", foldCase) + `
+		AND ` + sameName("c.COLUMN_NAME", "cp.Column_name", true) + `
+)
+UNION ALL
+SELECT 'routine', pp.User, pp.Host, pp.Db, pp.Routine_name, '', pp.Routine_type, pp.Proc_priv
+FROM mysql.procs_priv pp
+WHERE pp.Proc_priv != '' AND %[3]s NOT EXISTS (
+	SELECT 1 FROM information_schema.ROUTINES r
+	WHERE ` + sameName("r.ROUTINE_SCHEMA", "pp.Db", foldCase) + ` AND ` + sameName("r.ROUTINE_NAME", "pp.Routine_name", true) + `
+		AND ` + sameName("r.ROUTINE_TYPE", "pp.Routine_type", true) + `
+)
+ORDER BY User, Host, Db, object_name, Column_name`
+}
+
+// ListOrphanedGrants returns the table, column and routine privileges on objects that are not in
+// information_schema, for a single account or, when account is nil, for every account. information_schema only
+// lists the objects the connector can see, which are also the only ones it syncs.
+// Grants required:
+//
+//	GRANT SELECT (Host, User, Db, Table_priv, Table_name) ON mysql.tables_priv TO user@host;
+//	GRANT SELECT (Host, User, Db, Column_name, Column_priv, Table_name) ON mysql.columns_priv TO user@host;
+//	GRANT SELECT (Host, User, Db, Routine_name, Routine_type, Proc_priv) ON mysql.procs_priv TO user@host;
+func (c *Client) ListOrphanedGrants(ctx context.Context, account *AccountName) ([]*OrphanedGrant, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing orphaned grants")
+
+	filters := []interface{}{"", "", ""}
+	var args []interface{}
+	if account != nil {
+		l.Debug("filtering orphaned grants", zap.String("user", account.User), zap.String("host", account.Host))
+		filters = []interface{}{"tp.User = ? AND tp.Host = ? AND", "cp.User = ? AND cp.Host = ? AND", "pp.User = ? AND pp.Host = ? AND"}
+		for range filters {
+			args = append(args, account.User, account.Host)
+		}
+	}
+
+	var ret []*OrphanedGrant
+	err := c.reader().SelectContext(ctx, &ret, fmt.Sprintf(orphanedGrantsQuery(c.Capabilities().FoldsNameCase), filters...), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// OrphanedGrantIDs returns the IDs of the objects an account holds orphaned grants on.
+func (c *Client) OrphanedGrantIDs(ctx context.Context, user string, host string) (map[string]struct{}, error) {
+	orphans, err := c.ListOrphanedGrants(ctx, &AccountName{User: user, Host: host})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]struct{}, len(orphans))
+	for _, o := range orphans {
+		ret[o.ID()] = struct{}{}
+	}
+	return ret, nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedGrantRevokeStatement(t *testing.T) {
+	tests := []struct {
+		name  string
+		grant OrphanedGrant
+		want  string
+	}{
+		{
+			name:  "table",
+			grant: OrphanedGrant{ObjectType: TableType, User: "app", Host: "%", Database: "shop", Object: "orders", Privs: "Select,Insert"},
+			want:  "REVOKE SELECT, INSERT ON `shop`.`orders` FROM 'app'@'%'",
+		},
+		{
+			name: "column",
+			grant: OrphanedGrant{
+				ObjectType: ColumnType, User: "app", Host: "10.0.0.%", Database: "shop", Object: "orders", Column: "total",
+				Privs: "Select,Update",
+			},
+			want: "REVOKE SELECT (`total`), UPDATE (`total`) ON `shop`.`orders` FROM 'app'@'10.0.0.%'",
+		},
+		{
+			name: "routine with the grant option",
+			grant: OrphanedGrant{
+				ObjectType: RoutineType, User: "admin", Host: "localhost", Database: "shop", Object: "refund",
+				RoutineType: "PROCEDURE", Privs: "Execute,Grant",
+			},
+			want: "REVOKE EXECUTE, GRANT OPTION ON PROCEDURE `shop`.`refund` FROM 'admin'@'localhost'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.grant.RevokeStatement()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOrphanedGrantRevokeStatementInvalid(t *testing.T) {
+	_, err := (&OrphanedGrant{ObjectType: TableType, User: "app", Host: "%", Database: "shop", Object: "orders`; DROP", Privs: "Select"}).RevokeStatement()
+	require.Error(t, err)
+
+	_, err = (&OrphanedGrant{ObjectType: TableType, User: "app", Host: "%", Database: "shop", Object: "orders"}).RevokeStatement()
+	require.Error(t, err)
+}
+
+func TestOrphanedGrantID(t *testing.T) {
+	g := OrphanedGrant{ObjectType: ColumnType, Database: "shop", Object: "orders", Column: "total"}
+	require.Equal(t, "column:shop.orders.total", g.ID())
+}
+
+func TestOrphanedGrantsQuery(t *testing.T) {
+	folded := orphanedGrantsQuery(true)
+	require.Contains(t, folded, "CAST(LOWER(t.TABLE_NAME) AS BINARY) = CAST(LOWER(tp.Table_name) AS BINARY)")
+
+	// Database and table names keep their case unless the server folds it, while column and routine names never do.
+	exact := orphanedGrantsQuery(false)
+	require.Contains(t, exact, "CAST(t.TABLE_SCHEMA AS BINARY) = CAST(tp.Db AS BINARY)")
+	require.Contains(t, exact, "CAST(t.TABLE_NAME AS BINARY) = CAST(tp.Table_name AS BINARY)")
+	require.Contains(t, exact, "CAST(LOWER(c.COLUMN_NAME) AS BINARY) = CAST(LOWER(cp.Column_name) AS BINARY)")
+	require.Contains(t, exact, "CAST(LOWER(r.ROUTINE_NAME) AS BINARY) = CAST(LOWER(pp.Routine_name) AS BINARY)")
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GrantRequirement is a privilege the connector needs: on *.* when Database is empty, on a database when Table is
+// empty, and otherwise on a table, or on the Columns of a table when they are given.
+type GrantRequirement struct {
+	Privilege string
+	Database  string
+	Table     string
+	Columns   []string
+}
+
+// String returns the requirement the way GRANT writes it, like SELECT (Host, User) ON mysql.db.
+func (r GrantRequirement) String() string {
+	priv := r.Privilege
+	if len(r.Columns) > 0 {
+		priv = fmt.Sprintf("%s (%s)", priv, strings.Join(r.Columns, ", "))
+	}
+
+	switch {
+	case r.Database == "":
+		return fmt.Sprintf("%s ON *.*", priv)
+	case r.Table == "":
+		return fmt.Sprintf("%s ON %s.*", priv, r.Database)
+	default:
+		return fmt.Sprintf("%s ON %s.%s", priv, r.Database, r.Table)
+	}
+}
+
+// ListOwnGrants returns the grants of the account the connector connects as, parsed from SHOW GRANTS. Roles
+// granted to the account are returned as role grants, without the privileges the roles hold.
+func (c *Client) ListOwnGrants(ctx context.Context) ([]*AccountStatement, error) {
+	var rows []string
+	err := c.reader().SelectContext(ctx, &rows, "SHOW GRANTS")
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*AccountStatement
+	for _, row := range rows {
+		stmt, ok := ParseAccountStatement(row, "")
+		if !ok {
+			continue
+		}
+		ret = append(ret, stmt)
+	}
+
+	return ret, nil
+}
+
+// grantsPrivilege reports whether a grant's privileges include the privilege, and returns the columns it is
+// limited to, if any.
+func grantsPrivilege(stmt *AccountStatement, privilege string) (bool, []string) {
+	for _, p := range stmt.Privileges {
+		switch p {
+		case "ALL", "ALL PRIVILEGES":
+			return true, nil
+		case privilege:
+			return true, stmt.Columns[p]
+		}
+	}
+	return false, nil
+}
+
+// MissingGrant returns the part of a requirement that the grants don't cover, or nil when they cover all of it.
+// A privilege is covered by a grant of it, or of ALL, on the same or a broader level, unless a partial revoke
+// removes it from the requirement's database. Column requirements are also covered by column grants, and the
+// returned requirement then only has the columns that are missing.
+func MissingGrant(grants []*AccountStatement, req GrantRequirement) *GrantRequirement {
+	privilege := strings.ToUpper(req.Privilege)
+	missing := make(map[string]struct{}, len(req.Columns))
+	for _, col := range req.Columns {
+		missing[strings.ToLower(col)] = struct{}{}
+	}
+
+	revoked := false
+	for _, g := range grants {
+		if g.Verb != "REVOKE" || g.Object != "*" || !strings.EqualFold(g.Database, req.Database) {
+			continue
+		}
+		if ok, _ := grantsPrivilege(g, privilege); ok {
+			revoked = true
+		}
+	}
+
+	for _, g := range grants {
+		if g.Verb != "GRANT" {
+			continue
+		}
+		ok, cols := grantsPrivilege(g, privilege)
+		if !ok {
+			continue
+		}
+
+		var covers bool
+		switch {
+		case g.Database == "*" && g.Object == "*":
+			covers = !revoked
+		case !strings.EqualFold(g.Database, req.Database) || req.Database == "":
+			covers = false
+		case g.Object == "*":
+			covers = true
+		default:
+			covers = req.Table != "" && strings.EqualFold(g.Object, req.Table)
+		}
+		if !covers {
+			continue
+		}
+
+		if len(cols) == 0 {
+			return nil
+		}
+		if len(req.Columns) == 0 {
+			continue
+		}
+		for _, col := range cols {
+			delete(missing, strings.ToLower(col))
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+	}
+
+	ret := req
+	if len(req.Columns) > 0 {
+		ret.Columns = nil
+		for _, col := range req.Columns {
+			if _, ok := missing[strings.ToLower(col)]; ok {
+				ret.Columns = append(ret.Columns, col)
+			}
+		}
+	}
+	return &ret
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingGrant(t *testing.T) {
+	parse := func(stmts ...string) []*AccountStatement {
+		var ret []*AccountStatement
+		for _, s := range stmts {
+			stmt, ok := ParseAccountStatement(s, "")
+			require.True(t, ok, s)
+			ret = append(ret, stmt)
+		}
+		return ret
+	}
+	roleEdges := GrantRequirement{
+		Privilege: "SELECT",
+		Database:  "mysql",
+		Table:     "role_edges",
+		Columns:   []string{"FROM_HOST", "FROM_USER", "TO_HOST", "TO_USER"},
+	}
+	replication := GrantRequirement{Privilege: "REPLICATION SLAVE"}
+
+	tests := []struct {
+		name   string
+		grants []string
+		req    GrantRequirement
+		want   string
+	}{
+		{
+			name:   "no grants",
+			grants: []string{"GRANT USAGE ON *.* TO `baton`@`%`"},
+			req:    roleEdges,
+			want:   "SELECT (FROM_HOST, FROM_USER, TO_HOST, TO_USER) ON mysql.role_edges",
+		},
+		{
+			name:   "global select",
+			grants: []string{"GRANT SELECT ON *.* TO `baton`@`%`"},
+			req:    roleEdges,
+		},
+		{
+			name:   "all on the database",
+			grants: []string{"GRANT ALL PRIVILEGES ON `mysql`.* TO `baton`@`%`"},
+			req:    roleEdges,
+		},
+		{
+			name:   "select on the table",
+			grants: []string{"GRANT SELECT ON `mysql`.`role_edges` TO `baton`@`%`"},
+			req:    roleEdges,
+		},
+		{
+			name:   "select on another table",
+			grants: []string{"GRANT SELECT ON `mysql`.`user` TO `baton`@`%`"},
+			req:    roleEdges,
+			want:   "SELECT (FROM_HOST, FROM_USER, TO_HOST, TO_USER) ON mysql.role_edges",
+		},
+		{
+			name: "column grants",
+			grants: []string{
+				"GRANT SELECT (`from_host`, `FROM_USER`) ON `mysql`.`role_edges` TO `baton`@`%`",
+				"GRANT SELECT (`TO_USER`), INSERT (`TO_HOST`) ON `mysql`.`role_edges` TO `baton`@`%`",
+			},
+			req:  roleEdges,
+			want: "SELECT (TO_HOST) ON mysql.role_edges",
+		},
+		{
+			name: "partial revoke",
+			grants: []string{
+				"GRANT SELECT ON *.* TO `baton`@`%`",
+				"REVOKE SELECT ON `mysql`.* FROM `baton`@`%`",
+			},
+			req:  roleEdges,
+			want: "SELECT (FROM_HOST, FROM_USER, TO_HOST, TO_USER) ON mysql.role_edges",
+		},
+		{
+			name:   "global privilege",
+			grants: []string{"GRANT SELECT, REPLICATION SLAVE ON *.* TO `baton`@`%`"},
+			req:    replication,
+		},
+		{
+			name:   "global privilege on a database",
+			grants: []string{"GRANT ALL ON `mysql`.* TO `baton`@`%`"},
+			req:    replication,
+			want:   "REPLICATION SLAVE ON *.*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MissingGrant(parse(tt.grants...), tt.req)
+			if tt.want == "" {
+				require.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
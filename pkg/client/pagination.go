@@ -1,7 +1,10 @@
 package client
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -39,3 +42,58 @@ func (p *Pager) Parse() (int, int, error) {
 
 	return offset, parsedPageSize, nil
 }
+
+// GrantPage asks for one page of an account's grants. Grants are read in the order of the key columns of their
+// grant table, and a page starts after the key of the last grant of the previous page, so grants made or revoked
+// between pages don't shift the ones that follow.
+type GrantPage struct {
+	// After is the key the previous page ended with, or empty for the first page.
+	After string
+	// Size is the number of grant rows to read. Zero reads them all.
+	Size int
+}
+
+// query adds the page's key condition, order and limit to a grants query ending with its WHERE clause.
+func (p *GrantPage) query(q string, args []interface{}, keyColumns ...string) (string, []interface{}, error) {
+	if p != nil && p.After != "" {
+		var after []string
+		if err := json.Unmarshal([]byte(p.After), &after); err != nil || len(after) != len(keyColumns) {
+			return "", nil, fmt.Errorf("invalid grant page key %q", p.After)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keyColumns)), ", ")
+		q += fmt.Sprintf(" AND (%s) > (%s)", strings.Join(keyColumns, ", "), placeholders)
+		for _, v := range after {
+			args = append(args, v)
+		}
+	}
+
+	q += " ORDER BY " + strings.Join(keyColumns, ", ")
+	if p != nil && p.Size > 0 {
+		// One more row than the page holds tells whether another page follows.
+		q += " LIMIT ?"
+		args = append(args, p.Size+1)
+	}
+	return q, args, nil
+}
+
+// end returns how many of the n rows read belong to the page and, when more follow, the key of the last one.
+func (p *GrantPage) end(n int, key func(i int) []string) (int, string, error) {
+	if p == nil || p.Size <= 0 || n <= p.Size {
+		return n, "", nil
+	}
+
+	next, err := grantPageKey(key(p.Size - 1))
+	if err != nil {
+		return 0, "", err
+	}
+	return p.Size, next, nil
+}
+
+// grantPageKey returns the page key that reads the grants after the one with the given key column values.
+func grantPageKey(key []string) (string, error) {
+	ret, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return string(ret), nil
+}
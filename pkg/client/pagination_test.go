@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantPage(t *testing.T) {
+	base := "SELECT Db, Table_name FROM mysql.tables_priv WHERE User = ? AND Host = ?"
+	baseArgs := []interface{}{"app", "%"}
+
+	var all *GrantPage
+	q, args, err := all.query(base, baseArgs, "Db", "Table_name")
+	require.NoError(t, err)
+	require.Equal(t, base+" ORDER BY Db, Table_name", q)
+	require.Equal(t, baseArgs, args)
+	n, next, err := all.end(3, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Empty(t, next)
+
+	first := &GrantPage{Size: 2}
+	q, args, err = first.query(base, baseArgs, "Db", "Table_name")
+	require.NoError(t, err)
+	require.Equal(t, base+" ORDER BY Db, Table_name LIMIT ?", q)
+	require.Equal(t, []interface{}{"app", "%", 3}, args)
+
+	// A third row means another page follows, which starts after the second row.
+	rows := [][]string{{"shop", "orders"}, {"shop", "users"}, {"web", "sessions"}}
+	n, next, err = first.end(len(rows), func(i int) []string { return rows[i] })
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, `["shop","users"]`, next)
+
+	second := &GrantPage{After: next, Size: 2}
+	q, args, err = second.query(base, baseArgs, "Db", "Table_name")
+	require.NoError(t, err)
+	require.Equal(t, base+" AND (Db, Table_name) > (?, ?) ORDER BY Db, Table_name LIMIT ?", q)
+	require.Equal(t, []interface{}{"app", "%", "shop", "users", 3}, args)
+	n, next, err = second.end(1, func(i int) []string { return rows[2:][i] })
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Empty(t, next)
+
+	_, _, err = (&GrantPage{After: `["shop"]`}).query(base, baseArgs, "Db", "Table_name")
+	require.Error(t, err)
+	_, _, err = (&GrantPage{After: "12"}).query(base, baseArgs, "Db", "Table_name")
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// MySQL error numbers returned when the connector account lacks a privilege.
+const (
+	errDBAccessDenied       = 1044
+	errAccessDenied         = 1045
+	errTableAccessDenied    = 1142
+	errColumnAccessDenied   = 1143
+	errSpecificAccessDenied = 1227
+)
+
+// IsAccessDenied reports whether err is the server refusing access to a database, table, column or statement.
+func IsAccessDenied(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+
+	switch myErr.Number {
+	case errDBAccessDenied, errAccessDenied, errTableAccessDenied, errColumnAccessDenied, errSpecificAccessDenied:
+		return true
+	default:
+		return false
+	}
+}
+
+// StatementUsage is the number of statements of one class an account ran since the server started or the
+// statistics were truncated.
+type StatementUsage struct {
+	User      string `db:"USER"`
+	Host      string `db:"HOST"`
+	EventName string `db:"EVENT_NAME"`
+	Count     int64  `db:"COUNT_STAR"`
+}
+
+// ListStatementUsage returns the statement classes each account has run.
+// Required MySQL grant for connector:
+//
+//	GRANT SELECT ON performance_schema.events_statements_summary_by_account_by_event_name TO user@host;
+func (c *Client) ListStatementUsage(ctx context.Context) ([]*StatementUsage, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing statement usage")
+
+	q := `SELECT USER, HOST, EVENT_NAME, COUNT_STAR
+		FROM performance_schema.events_statements_summary_by_account_by_event_name
+		WHERE COUNT_STAR > 0 AND USER IS NOT NULL AND HOST IS NOT NULL`
+
+	var ret []*StatementUsage
+	err := c.pool().SelectContext(ctx, &ret, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetUptime returns how long the server has been running.
+func (c *Client) GetUptime(ctx context.Context) (time.Duration, error) {
+	var status struct {
+		Name  string `db:"Variable_name"`
+		Value string `db:"Value"`
+	}
+	err := c.pool().GetContext(ctx, &status, "SHOW GLOBAL STATUS LIKE 'Uptime'")
+	if err != nil {
+		return 0, err
+	}
+
+	seconds, err := strconv.ParseInt(status.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected uptime %q: %w", status.Value, err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// planState collects the statements that change accounts or privileges while a plan is being built, instead of
+// running them.
+type planState struct {
+	mtx        sync.Mutex
+	active     bool
+	statements []string
+}
+
+// record adds the statement to the plan being built, and reports whether there is one.
+func (p *planState) record(query string) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.active {
+		return false
+	}
+	p.statements = append(p.statements, query)
+	return true
+}
+
+// Plan calls fn with the statements that change accounts or privileges recorded instead of run, and returns them.
+// Reads still go to the server, so the statements are built exactly as they are when they run.
+func (c *Client) Plan(fn func() error) ([]string, error) {
+	c.plan.mtx.Lock()
+	c.plan.active = true
+	c.plan.statements = nil
+	c.plan.mtx.Unlock()
+
+	err := fn()
+
+	c.plan.mtx.Lock()
+	defer c.plan.mtx.Unlock()
+	ret := c.plan.statements
+	c.plan.active = false
+	c.plan.statements = nil
+
+	return ret, err
+}
+
+// SetDryRun sets whether statements that change accounts or privileges are only logged instead of run.
+func (c *Client) SetDryRun(dryRun bool) {
+	c.dryRun = dryRun
+}
+
+// identifiedBy matches the password of a CREATE USER or ALTER USER statement.
+var identifiedBy = regexp.MustCompile(`(?i)(IDENTIFIED BY )'(?:[^']|'')*'`)
+
+// redactPasswords replaces the passwords in a statement so it can be logged.
+func redactPasswords(query string) string {
+	return identifiedBy.ReplaceAllString(query, "${1}'"+redactedPassword+"'")
+}
+
+// skipStatement reports whether a statement that changes accounts or privileges is not run, because a plan is
+// being built or the client is in dry-run mode.
+func (c *Client) skipStatement(ctx context.Context, query string) bool {
+	if c.plan.record(query) {
+		return true
+	}
+	if c.dryRun {
+		ctxzap.Extract(ctx).Info("dry run: not running statement", zap.String("statement", redactPasswords(query)))
+		return true
+	}
+	return false
+}
+
+// execStatement runs a statement that changes accounts or privileges. The current snapshot is ended first, so
+// reads that follow, like a targeted sync after provisioning, see the change.
+func (c *Client) execStatement(ctx context.Context, query string) error {
+	err := c.CheckStatement(query)
+	if err != nil {
+		return err
+	}
+	if c.skipStatement(ctx, query) {
+		return nil
+	}
+
+	c.EndSnapshot(ctx)
+	return c.withRetry(ctx, query, isIdempotent(query), func() error {
+		_, err := c.db.ExecContext(ctx, query)
+		return err
+	})
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactPasswords(t *testing.T) {
+	require.Equal(
+		t,
+		"CREATE USER 'app'@'%' IDENTIFIED BY '********'",
+		redactPasswords("CREATE USER 'app'@'%' IDENTIFIED BY 'it''s s3cret'"),
+	)
+	require.Equal(t, "GRANT SELECT ON *.* TO 'app'@'%'", redactPasswords("GRANT SELECT ON *.* TO 'app'@'%'"))
+}
+
+func TestDryRun(t *testing.T) {
+	// The client has no connection, so any statement that is run fails.
+	c := &Client{capabilities: ServerCapabilities{Flavor: FlavorMySQL}}
+	c.SetDryRun(true)
+
+	ctx := context.Background()
+	require.NoError(t, c.CreateUser(ctx, "app@%", "s3cret"))
+	require.NoError(t, c.GrantDatabasePrivilege(ctx, "shop", "app@%", "select"))
+	require.NoError(t, c.RevokeServerPrivilege(ctx, "app@%", "process", true))
+	require.NoError(t, c.DropUser(ctx, "app@%"))
+
+	// Invalid names are still rejected, as the statements are built the same way.
+	require.Error(t, c.GrantTablePrivilege(ctx, "shop.orders`", "app@%", "select"))
+}
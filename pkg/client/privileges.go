@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+type PrivilegeModel struct {
+	Name    string `db:"Privilege"`
+	Context string `db:"Context"`
+	Comment string `db:"Comment"`
+}
+
+// IsGlobal reports whether the privilege can only be granted on *.*.
+func (p *PrivilegeModel) IsGlobal() bool {
+	for _, ctx := range strings.Split(p.Context, ",") {
+		switch strings.TrimSpace(ctx) {
+		case "Server Admin", "File access":
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ListPrivileges returns the privileges the server supports, including dynamic privileges registered by
+// components and plugins.
+func (c *Client) ListPrivileges(ctx context.Context) ([]*PrivilegeModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing privileges")
+
+	var ret []*PrivilegeModel
+	err := c.reader().SelectContext(ctx, &ret, "SHOW PRIVILEGES")
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ListGlobalGrantPrivileges returns the distinct dynamic privileges that are granted to any account.
+// Required MySQL grant for connector:
+//
+//	GRANT SELECT (PRIV) ON mysql.global_grants TO user@host;
+func (c *Client) ListGlobalGrantPrivileges(ctx context.Context) ([]string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing granted dynamic privileges")
+
+	var ret []string
+	err := c.reader().SelectContext(ctx, &ret, "SELECT DISTINCT PRIV FROM mysql.global_grants")
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
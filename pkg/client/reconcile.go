@@ -0,0 +1,638 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// DesiredAccount is the access a user or role should have. Privileges are written as in GRANT, like SELECT,
+// CREATE TEMPORARY TABLES or BINLOG_ADMIN, and GRANT OPTION stands for WITH GRANT OPTION.
+type DesiredAccount struct {
+	// Roles are the roles granted to the account, as role@host.
+	Roles []string `yaml:"roles"`
+	// Global are the privileges on *.*.
+	Global []string `yaml:"global"`
+	// Databases, Tables, Columns and Routines map db, db.table, db.table.column and db.routine to privileges.
+	Databases map[string][]string `yaml:"databases"`
+	Tables    map[string][]string `yaml:"tables"`
+	Columns   map[string][]string `yaml:"columns"`
+	Routines  map[string][]string `yaml:"routines"`
+}
+
+// DesiredState is the users, roles and privileges a server should have, keyed by user@host and role@host.
+// MariaDB roles are keyed by name alone.
+type DesiredState struct {
+	Users map[string]*DesiredAccount `yaml:"users"`
+	Roles map[string]*DesiredAccount `yaml:"roles"`
+	// Prune drops the users and roles that are not listed, except those matching Ignore.
+	Prune bool `yaml:"prune"`
+	// Ignore are patterns of user@host accounts that Prune leaves alone, like root@localhost or mysql.*, written as
+	// for matchGlob.
+	Ignore []string `yaml:"ignore"`
+}
+
+// LoadDesiredState reads a desired state from a YAML file.
+func LoadDesiredState(file string) (*DesiredState, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	ret := &DesiredState{}
+	err = dec.Decode(ret)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid desired state in %s: %w", file, err)
+	}
+	for _, p := range ret.Ignore {
+		err := checkGlob(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q in %s: %w", p, file, err)
+		}
+	}
+
+	return ret, nil
+}
+
+// redactedPassword replaces generated passwords in planned statements.
+const redactedPassword = "********"
+
+// privilegeLevel is an object privileges are granted on: the server, or a database, table, column or routine.
+type privilegeLevel struct {
+	Type   string
+	Object string
+}
+
+func (l privilegeLevel) String() string {
+	if l.Type == ServerType {
+		return "*.*"
+	}
+	return fmt.Sprintf("%s %s", l.Type, l.Object)
+}
+
+// accountState is the roles and privileges of an account, read from the server or from a desired state.
+type accountState struct {
+	roles      map[string]struct{}
+	privileges map[privilegeLevel]map[string]struct{}
+}
+
+func newAccountState() *accountState {
+	return &accountState{
+		roles:      make(map[string]struct{}),
+		privileges: make(map[privilegeLevel]map[string]struct{}),
+	}
+}
+
+func (s *accountState) add(level privilegeLevel, privs ...string) {
+	if len(privs) == 0 {
+		return
+	}
+	if s.privileges[level] == nil {
+		s.privileges[level] = make(map[string]struct{})
+	}
+	for _, p := range privs {
+		s.privileges[level][p] = struct{}{}
+	}
+}
+
+// addTablePrivileges adds privileges read from a grant table, like select,create_view or Select,Create View.
+func (s *accountState) addTablePrivileges(level privilegeLevel, privs string) {
+	keywords, grantOption := exportPrivileges(privs)
+	if grantOption {
+		keywords = append(keywords, GrantOptionPrivilege)
+	}
+	s.add(level, keywords...)
+}
+
+// parseAccount splits user@host. A name without a host is user@% for users and MySQL roles, and has an empty
+// host for MariaDB roles.
+func (c *Client) parseAccount(name string, role bool) (AccountName, error) {
+	i := strings.LastIndex(name, "@")
+	if i < 0 {
+		if role && c.Capabilities().Flavor == FlavorMariaDB {
+			return AccountName{User: name}, nil
+		}
+		return AccountName{User: name, Host: "%"}, nil
+	}
+	if i == 0 {
+		return AccountName{}, fmt.Errorf("invalid account %q: the user name is empty", name)
+	}
+	return AccountName{User: name[:i], Host: name[i+1:]}, nil
+}
+
+func accountKey(a AccountName) string {
+	return a.User + "@" + a.Host
+}
+
+// normalizePrivileges upper-cases privileges and collapses their spaces.
+func normalizePrivileges(privs []string) ([]string, error) {
+	var ret []string
+	for _, p := range privs {
+		p = strings.Join(strings.Fields(strings.ToUpper(p)), " ")
+		switch p {
+		case "", "USAGE":
+			continue
+		case "ALL", "ALL PRIVILEGES":
+			return nil, fmt.Errorf("%s is not supported, list the privileges instead", p)
+		}
+		ret = append(ret, p)
+	}
+	return ret, nil
+}
+
+// desiredAccountState turns a desired account into the roles and privileges it should hold.
+func (c *Client) desiredAccountState(d *DesiredAccount) (*accountState, error) {
+	ret := newAccountState()
+	if d == nil {
+		return ret, nil
+	}
+
+	for _, r := range d.Roles {
+		role, err := c.parseAccount(r, true)
+		if err != nil {
+			return nil, err
+		}
+		ret.roles[accountKey(role)] = struct{}{}
+	}
+
+	privs, err := normalizePrivileges(d.Global)
+	if err != nil {
+		return nil, err
+	}
+	ret.add(privilegeLevel{Type: ServerType}, privs...)
+
+	levels := []struct {
+		typ   string
+		parts int
+		privs map[string][]string
+	}{
+		{DatabaseType, 1, d.Databases},
+		{TableType, 2, d.Tables},
+		{ColumnType, 3, d.Columns},
+		{RoutineType, 2, d.Routines},
+	}
+	for _, l := range levels {
+		for object, p := range l.privs {
+			if len(strings.Split(object, ".")) != l.parts {
+				return nil, fmt.Errorf("invalid %s %q", l.typ, object)
+			}
+			privs, err := normalizePrivileges(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", l.typ, object, err)
+			}
+			if l.typ == ColumnType {
+				for _, priv := range privs {
+					if priv == GrantOptionPrivilege {
+						return nil, fmt.Errorf("column %s: grant GRANT OPTION on the table instead", object)
+					}
+				}
+			}
+			ret.add(privilegeLevel{Type: l.typ, Object: object}, privs...)
+		}
+	}
+
+	return ret, nil
+}
+
+// currentAccountState reads the roles and privileges an account holds.
+func (c *Client) currentAccountState(ctx context.Context, account AccountName) (*accountState, error) {
+	caps := c.Capabilities()
+	ret := newAccountState()
+
+	u, err := c.GetUser(ctx, account.User, account.Host)
+	if err != nil {
+		return nil, err
+	}
+	ret.addTablePrivileges(privilegeLevel{Type: ServerType}, u.Privs)
+
+	if caps.GlobalGrants || caps.GlobalPriv {
+		globalGrants, err := c.ListGlobalGrants(ctx, account.User, account.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range globalGrants {
+			ret.add(privilegeLevel{Type: ServerType}, strings.ToUpper(g.Priv))
+		}
+	}
+
+	databaseGrants, _, err := c.ListDatabaseGrants(ctx, account.User, account.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range databaseGrants {
+		ret.addTablePrivileges(privilegeLevel{Type: DatabaseType, Object: g.Database}, g.Privs)
+	}
+
+	tableGrants, _, err := c.ListTableGrants(ctx, account.User, account.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range tableGrants {
+		ret.addTablePrivileges(privilegeLevel{Type: TableType, Object: g.Database + "." + g.Table}, g.Privs)
+	}
+
+	columnGrants, _, err := c.ListColumnGrants(ctx, account.User, account.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range columnGrants {
+		ret.addTablePrivileges(privilegeLevel{Type: ColumnType, Object: g.Database + "." + g.Table + "." + g.Column}, g.Privs)
+	}
+
+	routineGrants, _, err := c.ListRoutineGrants(ctx, account.User, account.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range routineGrants {
+		ret.addTablePrivileges(privilegeLevel{Type: RoutineType, Object: g.Database + "." + g.Routine}, g.Privs)
+	}
+
+	if caps.Roles {
+		roleGrants, _, err := c.ListRoleGrants(ctx, account.User, account.Host, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range roleGrants {
+			ret.roles[accountKey(AccountName{User: g.FromUser, Host: g.FromHost})] = struct{}{}
+		}
+	}
+
+	return ret, nil
+}
+
+// Change is a single step of a plan, made through one of the client's provisioning methods.
+type Change struct {
+	// Account is the user or role the change applies to, as user@host.
+	Account string
+	// Description says what the change does, like "grant SELECT on table shop.orders".
+	Description string
+	// Statements are the SQL statements the change runs, with generated passwords redacted.
+	Statements []string
+	// Password is the password generated for a new user.
+	Password string
+
+	apply func(ctx context.Context) error
+}
+
+// ReconcilePlan is the list of changes that bring the server to a desired state.
+type ReconcilePlan struct {
+	Changes []*Change
+}
+
+// Apply makes the changes in order, and stops at the first that fails.
+func (p *ReconcilePlan) Apply(ctx context.Context) error {
+	l := ctxzap.Extract(ctx)
+	for _, ch := range p.Changes {
+		l.Info("applying change", zap.String("account", ch.Account), zap.String("change", ch.Description))
+		err := ch.apply(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to %s for %s: %w", ch.Description, ch.Account, err)
+		}
+	}
+	return nil
+}
+
+// generatePassword returns a random password for a new user.
+func generatePassword() (string, error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, 32)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// grantChange returns the change that grants or revokes a privilege on a level.
+func (c *Client) grantChange(account string, level privilegeLevel, priv string, grant bool) *Change {
+	verb := "revoke"
+	if grant {
+		verb = "grant"
+	}
+
+	ch := &Change{
+		Account:     account,
+		Description: fmt.Sprintf("%s %s on %s", verb, priv, level),
+	}
+	ch.apply = func(ctx context.Context) error {
+		switch level.Type {
+		case ServerType:
+			if grant {
+				return c.GrantServerPrivilege(ctx, account, priv, false)
+			}
+			return c.RevokeServerPrivilege(ctx, account, priv, false)
+
+		case DatabaseType:
+			if grant {
+				return c.GrantDatabasePrivilege(ctx, level.Object, account, priv)
+			}
+			return c.RevokeDatabasePrivilege(ctx, level.Object, account, priv)
+
+		case TableType:
+			if grant {
+				return c.GrantTablePrivilege(ctx, level.Object, account, priv)
+			}
+			return c.RevokeTablePrivilege(ctx, level.Object, account, priv)
+
+		case ColumnType:
+			i := strings.LastIndex(level.Object, ".")
+			if grant {
+				return c.GrantColumnPrivilege(ctx, level.Object[:i], level.Object[i+1:], account, priv)
+			}
+			return c.RevokeColumnPrivilege(ctx, level.Object[:i], level.Object[i+1:], account, priv)
+
+		case RoutineType:
+			parts := strings.SplitN(level.Object, ".", 2)
+			if grant {
+				return c.GrantRoutinePrivilege(ctx, priv, parts[0], parts[1], account)
+			}
+			return c.RevokeRoutinePrivilege(ctx, priv, parts[0], parts[1], account)
+		}
+		return fmt.Errorf("unexpected privilege level %s", level.Type)
+	}
+
+	return ch
+}
+
+// diffAccount returns the changes that turn the current roles and privileges of an account into the desired ones.
+// Revokes come before grants.
+func (c *Client) diffAccount(account string, current *accountState, desired *accountState) []*Change {
+	var revokes, grants []*Change
+
+	for _, role := range sortedKeys(current.roles) {
+		if _, ok := desired.roles[role]; !ok {
+			role := role
+			revokes = append(revokes, &Change{
+				Account:     account,
+				Description: fmt.Sprintf("revoke role %s", role),
+				apply: func(ctx context.Context) error {
+					return c.RevokeRolePrivilege(ctx, role, account, "role_assignment")
+				},
+			})
+		}
+	}
+	for _, role := range sortedKeys(desired.roles) {
+		if _, ok := current.roles[role]; !ok {
+			role := role
+			grants = append(grants, &Change{
+				Account:     account,
+				Description: fmt.Sprintf("grant role %s", role),
+				apply: func(ctx context.Context) error {
+					return c.GrantRolePrivilege(ctx, role, account, "role_assignment")
+				},
+			})
+		}
+	}
+
+	for _, level := range sortedLevels(current.privileges, desired.privileges) {
+		for _, priv := range sortedKeys(current.privileges[level]) {
+			if _, ok := desired.privileges[level][priv]; !ok {
+				revokes = append(revokes, c.grantChange(account, level, priv, false))
+			}
+		}
+		for _, priv := range sortedKeys(desired.privileges[level]) {
+			if _, ok := current.privileges[level][priv]; !ok {
+				grants = append(grants, c.grantChange(account, level, priv, true))
+			}
+		}
+	}
+
+	return append(revokes, grants...)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func sortedLevels(maps ...map[privilegeLevel]map[string]struct{}) []privilegeLevel {
+	seen := make(map[privilegeLevel]struct{})
+	var ret []privilegeLevel
+	for _, m := range maps {
+		for l := range m {
+			if _, ok := seen[l]; ok {
+				continue
+			}
+			seen[l] = struct{}{}
+			ret = append(ret, l)
+		}
+	}
+
+	order := map[string]int{ServerType: 0, DatabaseType: 1, TableType: 2, ColumnType: 3, RoutineType: 4}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Type != ret[j].Type {
+			return order[ret[i].Type] < order[ret[j].Type]
+		}
+		return ret[i].Object < ret[j].Object
+	})
+	return ret
+}
+
+// listAccounts returns every user or role on the server, keyed by user@host.
+func (c *Client) listAccounts(ctx context.Context, userType string) (map[string]*User, error) {
+	ret := make(map[string]*User)
+	pager := &Pager{Size: MaxPageSize}
+	for {
+		accounts, nextPageToken, err := c.ListUsers(ctx, userType, pager, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accounts {
+			ret[accountKey(AccountName{User: a.User, Host: a.Host})] = a
+		}
+
+		if nextPageToken == "" {
+			return ret, nil
+		}
+		pager.Token = nextPageToken
+	}
+}
+
+// keepAccount reports whether pruning leaves an account that is not in the desired state alone: the account the
+// client is logged in as, the accounts the safeguards protect and those matching Ignore.
+func (d *DesiredState) keepAccount(account string, currentUser string, safeguards *Safeguards) bool {
+	if account == currentUser {
+		return true
+	}
+	if safeguards.CheckAccount(account) != nil {
+		return true
+	}
+	_, ok := matchAccount(d.Ignore, account)
+	return ok
+}
+
+// PlanReconcile compares the users, roles and privileges on the server with a desired state, and returns the
+// changes that make them match: CREATE ROLE and CREATE USER first, then REVOKE and GRANT for each account, and
+// DROP last. Accounts that are not in the desired state are only dropped when it prunes, and are otherwise left
+// alone. Each change is planned through the provisioning method that applies it, so its statements are exactly
+// what Apply runs.
+func (c *Client) PlanReconcile(ctx context.Context, desired *DesiredState) (*ReconcilePlan, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("planning reconcile")
+
+	users, err := c.listAccounts(ctx, UserType)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := c.listAccounts(ctx, RoleType)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentUser string
+	err = c.reader().GetContext(ctx, &currentUser, "SELECT CURRENT_USER()")
+	if err != nil {
+		return nil, err
+	}
+
+	type desiredEntry struct {
+		name    AccountName
+		role    bool
+		account *DesiredAccount
+	}
+	var entries []*desiredEntry
+	listed := make(map[string]struct{})
+	for _, group := range []struct {
+		role     bool
+		accounts map[string]*DesiredAccount
+	}{{true, desired.Roles}, {false, desired.Users}} {
+		var names []string
+		for n := range group.accounts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			name, err := c.parseAccount(n, group.role)
+			if err != nil {
+				return nil, err
+			}
+			key := accountKey(name)
+			if _, ok := listed[key]; ok {
+				return nil, fmt.Errorf("%s is listed more than once", key)
+			}
+			listed[key] = struct{}{}
+			entries = append(entries, &desiredEntry{name: name, role: group.role, account: group.accounts[n]})
+		}
+	}
+
+	var creates, updates, drops []*Change
+	for _, e := range entries {
+		key := accountKey(e.name)
+		desiredState, err := c.desiredAccountState(e.account)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		_, isUser := users[key]
+		_, isRole := roles[key]
+		current := newAccountState()
+		switch {
+		case isUser && e.role:
+			return nil, fmt.Errorf("%s is listed as a role but is a user", key)
+		case isRole && !e.role:
+			return nil, fmt.Errorf("%s is listed as a user but is a role", key)
+		case isUser || isRole:
+			current, err = c.currentAccountState(ctx, e.name)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the privileges of %s: %w", key, err)
+			}
+		case e.role:
+			creates = append(creates, &Change{
+				Account:     key,
+				Description: "create role",
+				apply: func(ctx context.Context) error {
+					return c.CreateRole(ctx, key)
+				},
+			})
+		default:
+			password, err := generatePassword()
+			if err != nil {
+				return nil, err
+			}
+			creates = append(creates, &Change{
+				Account:     key,
+				Description: "create user",
+				Password:    password,
+				apply: func(ctx context.Context) error {
+					return c.CreateUser(ctx, key, password)
+				},
+			})
+		}
+
+		updates = append(updates, c.diffAccount(key, current, desiredState)...)
+	}
+
+	if desired.Prune {
+		for _, group := range []struct {
+			accounts map[string]*User
+			role     bool
+		}{{users, false}, {roles, true}} {
+			var keys []string
+			for k := range group.accounts {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				if _, ok := listed[key]; ok || desired.keepAccount(key, currentUser, c.safeguards) {
+					continue
+				}
+				key := key
+				ch := &Change{Account: key, Description: "drop user"}
+				ch.apply = func(ctx context.Context) error {
+					return c.DropUser(ctx, key)
+				}
+				if group.role {
+					ch.Description = "drop role"
+					ch.apply = func(ctx context.Context) error {
+						return c.DropRole(ctx, key)
+					}
+				}
+				drops = append(drops, ch)
+			}
+		}
+	}
+
+	plan := &ReconcilePlan{}
+	plan.Changes = append(append(creates, updates...), drops...)
+	for _, ch := range plan.Changes {
+		ch := ch
+		statements, err := c.Plan(func() error {
+			return ch.apply(ctx)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to plan %s for %s: %w", ch.Description, ch.Account, err)
+		}
+		for i, s := range statements {
+			if ch.Password != "" {
+				s = strings.ReplaceAll(s, ch.Password, redactedPassword)
+			}
+			statements[i] = s
+		}
+		ch.Statements = statements
+	}
+
+	return plan, nil
+}
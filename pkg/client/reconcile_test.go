@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDesiredState(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "desired.yaml")
+	err := os.WriteFile(file, []byte(`
+prune: true
+ignore: ["backup@%"]
+roles:
+  reader@%:
+    databases:
+      shop: [select]
+users:
+  app@10.0.0.%:
+    roles: [reader@%]
+    tables:
+      shop.orders: [SELECT, UPDATE]
+`), 0o600)
+	require.NoError(t, err)
+
+	desired, err := LoadDesiredState(file)
+	require.NoError(t, err)
+	require.True(t, desired.Prune)
+	require.Equal(t, []string{"SELECT", "UPDATE"}, desired.Users["app@10.0.0.%"].Tables["shop.orders"])
+	safeguards, err := NewSafeguards(append(DefaultProtectedAccounts, "svc_*@%"), nil)
+	require.NoError(t, err)
+	require.True(t, desired.keepAccount("root@localhost", "baton@%", safeguards))
+	require.True(t, desired.keepAccount("svc_backup@%", "baton@%", safeguards))
+	require.True(t, desired.keepAccount("backup@%", "baton@%", safeguards))
+	require.True(t, desired.keepAccount("baton@%", "baton@%", safeguards))
+	require.True(t, desired.keepAccount("mysql.sys@localhost", "baton@%", safeguards))
+	require.False(t, desired.keepAccount("app@%", "baton@%", safeguards))
+
+	err = os.WriteFile(file, []byte("users:\n  app@%:\n    tabels: {}\n"), 0o600)
+	require.NoError(t, err)
+	_, err = LoadDesiredState(file)
+	require.Error(t, err)
+}
+
+func TestDesiredAccountState(t *testing.T) {
+	c := &Client{capabilities: ServerCapabilities{Flavor: FlavorMariaDB, Roles: true}}
+
+	state, err := c.desiredAccountState(&DesiredAccount{
+		Roles:     []string{"reader"},
+		Global:    []string{"process", "create  temporary tables"},
+		Databases: map[string][]string{"shop": {"Select", "usage"}},
+		Columns:   map[string][]string{"shop.orders.total": {"update"}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, state.roles, "reader@")
+	require.Equal(t, map[string]struct{}{"PROCESS": {}, "CREATE TEMPORARY TABLES": {}}, state.privileges[privilegeLevel{Type: ServerType}])
+	require.Equal(t, map[string]struct{}{"SELECT": {}}, state.privileges[privilegeLevel{Type: DatabaseType, Object: "shop"}])
+	require.Contains(t, state.privileges[privilegeLevel{Type: ColumnType, Object: "shop.orders.total"}], "UPDATE")
+
+	_, err = c.desiredAccountState(&DesiredAccount{Tables: map[string][]string{"orders": {"SELECT"}}})
+	require.Error(t, err)
+	_, err = c.desiredAccountState(&DesiredAccount{Global: []string{"ALL PRIVILEGES"}})
+	require.Error(t, err)
+	_, err = c.desiredAccountState(&DesiredAccount{Columns: map[string][]string{"shop.orders.total": {"GRANT OPTION"}}})
+	require.Error(t, err)
+}
+
+func TestDiffAccount(t *testing.T) {
+	c := &Client{capabilities: ServerCapabilities{Flavor: FlavorMySQL, Roles: true}}
+
+	current := newAccountState()
+	current.addTablePrivileges(privilegeLevel{Type: ServerType}, "process,grant,")
+	current.addTablePrivileges(privilegeLevel{Type: TableType, Object: "shop.orders"}, "Select,Delete")
+	current.roles["admin@%"] = struct{}{}
+
+	desired, err := c.desiredAccountState(&DesiredAccount{
+		Roles:  []string{"reader@%"},
+		Global: []string{"PROCESS"},
+		Tables: map[string][]string{"shop.orders": {"SELECT", "UPDATE"}},
+	})
+	require.NoError(t, err)
+
+	changes := c.diffAccount("app@%", current, desired)
+	var got []string
+	for _, ch := range changes {
+		statements, err := c.Plan(func() error {
+			return ch.apply(context.Background())
+		})
+		require.NoError(t, err)
+		got = append(got, statements...)
+	}
+	require.Equal(t, []string{
+		"REVOKE 'admin'@'%' FROM 'app'@'%'",
+		"REVOKE GRANT OPTION ON *.* FROM 'app'@'%'",
+		"REVOKE DELETE ON `shop`.`orders` FROM 'app'@'%'",
+		"GRANT 'reader'@'%' TO 'app'@'%'",
+		"GRANT UPDATE ON `shop`.`orders` TO 'app'@'%'",
+	}, got)
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// RetryPolicy is how often, and how far apart, queries and statements are attempted when they fail with a
+// transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first. One or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It doubles for each retry after it, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a new client.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// SetRetryPolicy sets how queries and statements are retried after transient errors.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// MySQL error numbers of transient failures.
+const (
+	errTooManyConnections = 1040
+	errServerShutdown     = 1053
+	errLockWaitTimeout    = 1205
+	errLockDeadlock       = 1213
+	errOptionPrevents     = 1290
+	errReadOnlyMode       = 1836
+	errConnectionKilled   = 1927
+	errServerGone         = 2006
+	errServerLost         = 2013
+	errServerLostExtended = 2055
+)
+
+// retryClass is whether a failed query or statement can be attempted again.
+type retryClass int
+
+const (
+	// notRetryable errors fail the same way when retried.
+	notRetryable retryClass = iota
+	// retryNotRun errors are returned before the server ran the statement, or after it rolled it back, so any
+	// statement can be retried.
+	retryNotRun
+	// retryMaybeRun errors lose the connection while the statement runs, so it may have taken effect and only
+	// idempotent statements can be retried.
+	retryMaybeRun
+)
+
+// classifyError returns whether an error is transient, and whether the statement may have run.
+func classifyError(err error) retryClass {
+	if errors.Is(err, driver.ErrBadConn) {
+		// The driver only returns ErrBadConn when nothing was sent on the connection.
+		return retryNotRun
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case errTooManyConnections, errLockWaitTimeout, errLockDeadlock, errReadOnlyMode:
+			// The server refused the statement, or rolled it back.
+			return retryNotRun
+		case errOptionPrevents:
+			// A server running with --read-only or --super-read-only, like a replica during a failover, refuses
+			// the statement until it is promoted. Other options that prevent a statement, like
+			// --skip-grant-tables, don't go away.
+			if strings.Contains(myErr.Message, "read-only") {
+				return retryNotRun
+			}
+			return notRetryable
+		case errServerShutdown, errConnectionKilled, errServerGone, errServerLost, errServerLostExtended:
+			return retryMaybeRun
+		default:
+			return notRetryable
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) {
+		return retryMaybeRun
+	}
+
+	return notRetryable
+}
+
+// isIdempotent reports whether running a statement twice has the same effect as running it once. Reads and
+// GRANT and SET DEFAULT ROLE statements are; CREATE, DROP, ALTER and REVOKE statements fail or change something
+// else when they already ran.
+func isIdempotent(query string) bool {
+	stmt, ok := ParseAccountStatement(query, "")
+	if !ok {
+		verb := strings.ToUpper(strings.TrimSpace(query))
+		return strings.HasPrefix(verb, "SELECT") || strings.HasPrefix(verb, "SHOW")
+	}
+	return stmt.Verb == "GRANT" || stmt.Verb == "SET DEFAULT ROLE"
+}
+
+// backoff returns the wait before a retry: the doubled backoff for the attempt with up to half of it taken off
+// at random, so clients that failed together don't retry together.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - rand.N(d/2+1)
+}
+
+// withRetry calls fn until it succeeds, fails with an error that isn't transient, or the policy's attempts run
+// out. Errors that may come after the statement ran are only retried when it is idempotent.
+func (c *Client) withRetry(ctx context.Context, query string, idempotent bool, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+		switch classifyError(err) {
+		case retryNotRun:
+		case retryMaybeRun:
+			if !idempotent {
+				return err
+			}
+		default:
+			return err
+		}
+
+		wait := c.retry.backoff(attempt)
+		ctxzap.Extract(ctx).Warn(
+			"transient MySQL error, retrying",
+			zap.Error(err),
+			zap.String("query", redactPasswords(query)),
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryingQuerier retries the reads of a querier after transient errors.
+type retryingQuerier struct {
+	c *Client
+	q querier
+}
+
+// pool returns a querier for the connection pool that retries reads after transient errors.
+func (c *Client) pool() querier {
+	return &retryingQuerier{c: c, q: c.db}
+}
+
+// resetDest empties the slice a failed attempt may have partly filled, since sqlx appends to it.
+func resetDest(dest interface{}) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+func (r *retryingQuerier) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.c.withRetry(ctx, query, true, func() error {
+		return r.q.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (r *retryingQuerier) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.c.withRetry(ctx, query, true, func() error {
+		resetDest(dest)
+		return r.q.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (r *retryingQuerier) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	var rows *sqlx.Rows
+	err := r.c.withRetry(ctx, query, true, func() error {
+		var err error
+		rows, err = r.q.QueryxContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want retryClass
+	}{
+		{"bad connection", driver.ErrBadConn, retryNotRun},
+		{"deadlock", &mysql.MySQLError{Number: 1213}, retryNotRun},
+		{"lock wait timeout", fmt.Errorf("granting: %w", &mysql.MySQLError{Number: 1205}), retryNotRun},
+		{"read only", &mysql.MySQLError{Number: 1290, Message: "The MySQL server is running with the --read-only option so it cannot execute this statement"}, retryNotRun},
+		{"super read only", &mysql.MySQLError{Number: 1290, Message: "The MySQL server is running with the --super-read-only option so it cannot execute this statement"}, retryNotRun},
+		{"skip grant tables", &mysql.MySQLError{Number: 1290, Message: "The MySQL server is running with the --skip-grant-tables option so it cannot execute this statement"}, notRetryable},
+		{"read only transaction", &mysql.MySQLError{Number: 1792}, notRetryable},
+		{"read only mode", &mysql.MySQLError{Number: 1836}, retryNotRun},
+		{"server gone", &mysql.MySQLError{Number: 2006}, retryMaybeRun},
+		{"invalid connection", mysql.ErrInvalidConn, retryMaybeRun},
+		{"unexpected eof", io.ErrUnexpectedEOF, retryMaybeRun},
+		{"access denied", &mysql.MySQLError{Number: 1142}, notRetryable},
+		{"syntax error", &mysql.MySQLError{Number: 1064}, notRetryable},
+		{"other", errors.New("boom"), notRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, classifyError(tt.err))
+		})
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	require.True(t, isIdempotent("SELECT 1"))
+	require.True(t, isIdempotent("  show grants"))
+	require.True(t, isIdempotent("GRANT SELECT ON `db`.* TO 'alice'@'%'"))
+	require.True(t, isIdempotent("SET DEFAULT ROLE ALL TO 'alice'@'%'"))
+	require.False(t, isIdempotent("REVOKE SELECT ON `db`.* FROM 'alice'@'%'"))
+	require.False(t, isIdempotent("CREATE USER 'alice'@'%'"))
+	require.False(t, isIdempotent("DROP USER 'alice'@'%'"))
+	require.False(t, isIdempotent("FLUSH PRIVILEGES"))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for i := 0; i < 20; i++ {
+		d := p.backoff(1)
+		require.GreaterOrEqual(t, d, 50*time.Millisecond)
+		require.LessOrEqual(t, d, 100*time.Millisecond)
+
+		d = p.backoff(3)
+		require.GreaterOrEqual(t, d, 200*time.Millisecond)
+		require.LessOrEqual(t, d, 400*time.Millisecond)
+
+		d = p.backoff(10)
+		require.GreaterOrEqual(t, d, 500*time.Millisecond)
+		require.LessOrEqual(t, d, time.Second)
+	}
+	require.Zero(t, RetryPolicy{}.backoff(1))
+}
+
+func TestClient_withRetry(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{retry: RetryPolicy{MaxAttempts: 3}}
+	failing := func(err error, calls *int) func() error {
+		return func() error {
+			*calls++
+			return err
+		}
+	}
+
+	calls := 0
+	err := c.withRetry(ctx, "REVOKE SELECT ON *.* FROM 'alice'@'%'", false, failing(mysql.ErrInvalidConn, &calls))
+	require.ErrorIs(t, err, mysql.ErrInvalidConn)
+	require.Equal(t, 1, calls, "statements that may have run are only retried when idempotent")
+
+	calls = 0
+	err = c.withRetry(ctx, "REVOKE SELECT ON *.* FROM 'alice'@'%'", false, failing(driver.ErrBadConn, &calls))
+	require.ErrorIs(t, err, driver.ErrBadConn)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = c.withRetry(ctx, "SELECT 1", true, failing(mysql.ErrInvalidConn, &calls))
+	require.ErrorIs(t, err, mysql.ErrInvalidConn)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = c.withRetry(ctx, "SELECT 1", true, failing(&mysql.MySQLError{Number: 1142}, &calls))
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	err = c.withRetry(ctx, "SELECT 1", true, func() error {
+		calls++
+		if calls < 2 {
+			return &mysql.MySQLError{Number: 1213}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	c.retry.MaxAttempts = 1
+	calls = 0
+	err = c.withRetry(ctx, "SELECT 1", true, failing(driver.ErrBadConn, &calls))
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
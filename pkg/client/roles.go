@@ -6,30 +6,55 @@ import (
 	"strings"
 )
 
-func (c *Client) GrantRolePrivilege(ctx context.Context, role, user, privilege string) error {
+// roleAccount formats a role for GRANT, REVOKE and SET DEFAULT ROLE. MariaDB roles are stored with an empty host
+// and are referenced by name alone.
+func (c *Client) roleAccount(role string) (string, error) {
 	roleParts := strings.Split(role, "@")
 	if len(roleParts) != 2 {
-		return fmt.Errorf("invalid role format: %s", role)
+		return "", fmt.Errorf("invalid role format: %s", role)
+	}
+
+	roleUser, err := escapeMySQLUserHost(roleParts[0])
+	if err != nil {
+		return "", err
+	}
+	if roleParts[1] == "" && c.Capabilities().Flavor == FlavorMariaDB {
+		return fmt.Sprintf("'%s'", roleUser), nil
+	}
+
+	roleHost, err := escapeMySQLUserHost(roleParts[1])
+	if err != nil {
+		return "", err
 	}
 
+	return fmt.Sprintf("'%s'@'%s'", roleUser, roleHost), nil
+}
+
+// userAccount formats a user@host account for use in SQL statements.
+func userAccount(user string) (string, error) {
 	userParts := strings.Split(user, "@")
 	if len(userParts) != 2 {
-		return fmt.Errorf("invalid user format: %s", user)
+		return "", fmt.Errorf("invalid user format: %s", user)
 	}
 
-	roleUser, err := escapeMySQLUserHost(roleParts[0])
+	targetUser, err := escapeMySQLUserHost(userParts[0])
 	if err != nil {
-		return err
+		return "", err
 	}
-	roleHost, err := escapeMySQLUserHost(roleParts[1])
+	targetHost, err := escapeMySQLUserHost(userParts[1])
 	if err != nil {
-		return err
+		return "", err
 	}
-	targetUser, err := escapeMySQLUserHost(userParts[0])
+
+	return fmt.Sprintf("'%s'@'%s'", targetUser, targetHost), nil
+}
+
+func (c *Client) GrantRolePrivilege(ctx context.Context, role, user, privilege string) error {
+	roleStr, err := c.roleAccount(role)
 	if err != nil {
 		return err
 	}
-	targetHost, err := escapeMySQLUserHost(userParts[1])
+	userStr, err := userAccount(user)
 	if err != nil {
 		return err
 	}
@@ -37,45 +62,26 @@ func (c *Client) GrantRolePrivilege(ctx context.Context, role, user, privilege s
 	var grantStmt string
 	switch privilege {
 	case "role_assignment":
-		grantStmt = fmt.Sprintf("GRANT '%s'@'%s' TO '%s'@'%s'", roleUser, roleHost, targetUser, targetHost)
+		grantStmt = fmt.Sprintf("GRANT %s TO %s", roleStr, userStr)
 	case "role_assignment_with_grant":
-		grantStmt = fmt.Sprintf("GRANT '%s'@'%s' TO '%s'@'%s' WITH ADMIN OPTION", roleUser, roleHost, targetUser, targetHost)
+		grantStmt = fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", roleStr, userStr)
 	case "proxy":
-		grantStmt = fmt.Sprintf("GRANT PROXY ON '%s'@'%s' TO '%s'@'%s'", roleUser, roleHost, targetUser, targetHost)
+		grantStmt = fmt.Sprintf("GRANT PROXY ON %s TO %s", roleStr, userStr)
 	case "proxy_with_grant":
-		grantStmt = fmt.Sprintf("GRANT PROXY ON '%s'@'%s' TO '%s'@'%s' WITH GRANT OPTION", roleUser, roleHost, targetUser, targetHost)
+		grantStmt = fmt.Sprintf("GRANT PROXY ON %s TO %s WITH GRANT OPTION", roleStr, userStr)
 	default:
 		return fmt.Errorf("unknown privilege: %s", privilege)
 	}
 
-	_ = c.db.MustExec(grantStmt)
-	return nil
+	return c.execStatement(ctx, grantStmt)
 }
 
 func (c *Client) RevokeRolePrivilege(ctx context.Context, role, user, privilege string) error {
-	roleParts := strings.Split(role, "@")
-	if len(roleParts) != 2 {
-		return fmt.Errorf("invalid role format: %s", role)
-	}
-
-	userParts := strings.Split(user, "@")
-	if len(userParts) != 2 {
-		return fmt.Errorf("invalid user format: %s", user)
-	}
-
-	roleUser, err := escapeMySQLUserHost(roleParts[0])
-	if err != nil {
-		return err
-	}
-	roleHost, err := escapeMySQLUserHost(roleParts[1])
-	if err != nil {
-		return err
-	}
-	targetUser, err := escapeMySQLUserHost(userParts[0])
+	roleStr, err := c.roleAccount(role)
 	if err != nil {
 		return err
 	}
-	targetHost, err := escapeMySQLUserHost(userParts[1])
+	userStr, err := userAccount(user)
 	if err != nil {
 		return err
 	}
@@ -83,13 +89,57 @@ func (c *Client) RevokeRolePrivilege(ctx context.Context, role, user, privilege
 	var revokeStmt string
 	switch privilege {
 	case "role_assignment", "role_assignment_with_grant":
-		revokeStmt = fmt.Sprintf("REVOKE '%s'@'%s' FROM '%s'@'%s'", roleUser, roleHost, targetUser, targetHost)
+		revokeStmt = fmt.Sprintf("REVOKE %s FROM %s", roleStr, userStr)
 	case "proxy", "proxy_with_grant":
-		revokeStmt = fmt.Sprintf("REVOKE PROXY ON '%s'@'%s' FROM '%s'@'%s'", roleUser, roleHost, targetUser, targetHost)
+		revokeStmt = fmt.Sprintf("REVOKE PROXY ON %s FROM %s", roleStr, userStr)
 	default:
 		return fmt.Errorf("unknown privilege: %s", privilege)
 	}
 
-	_ = c.db.MustExec(revokeStmt)
-	return nil
+	return c.execStatement(ctx, revokeStmt)
+}
+
+// SetDefaultRole sets the role that is activated when user logs in. An empty role clears the default role.
+// MariaDB keeps a single default role, set with SET DEFAULT ROLE ... FOR, while MySQL replaces the list of
+// default roles with SET DEFAULT ROLE ... TO.
+func (c *Client) SetDefaultRole(ctx context.Context, role, user string) error {
+	userStr, err := userAccount(user)
+	if err != nil {
+		return err
+	}
+
+	roleStr := "NONE"
+	if role != "" {
+		roleStr, err = c.roleAccount(role)
+		if err != nil {
+			return err
+		}
+	}
+
+	keyword := "TO"
+	if c.Capabilities().Flavor == FlavorMariaDB {
+		keyword = "FOR"
+	}
+
+	return c.execStatement(ctx, fmt.Sprintf("SET DEFAULT ROLE %s %s %s", roleStr, keyword, userStr))
+}
+
+// CreateRole creates a role, given as role@host. MariaDB roles are given with an empty host, as role@.
+func (c *Client) CreateRole(ctx context.Context, role string) error {
+	roleStr, err := c.roleAccount(role)
+	if err != nil {
+		return err
+	}
+
+	return c.execStatement(ctx, fmt.Sprintf("CREATE ROLE %s", roleStr))
+}
+
+// DropRole drops a role, which also revokes it from every account it is granted to.
+func (c *Client) DropRole(ctx context.Context, role string) error {
+	roleStr, err := c.roleAccount(role)
+	if err != nil {
+		return err
+	}
+
+	return c.execStatement(ctx, fmt.Sprintf("DROP ROLE %s", roleStr))
 }
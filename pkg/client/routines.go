@@ -48,7 +48,7 @@ func (c *Client) ListRoutines(ctx context.Context, parentResourceID *v2.Resource
 		args = append(args, offset)
 	}
 
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	rows, err := c.reader().QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -83,6 +83,33 @@ func (c *Client) ListRoutines(ctx context.Context, parentResourceID *v2.Resource
 	return ret, nextPageToken, nil
 }
 
+// GetRoutine returns the routine with the given resource ID.
+func (c *Client) GetRoutine(ctx context.Context, resourceID *v2.ResourceId) (*RoutineModel, error) {
+	id, err := newDbResourceID(resourceID.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var routineModel RoutineModel
+	err = c.reader().GetContext(
+		ctx,
+		&routineModel,
+		"SELECT SPECIFIC_NAME, ROUTINE_SCHEMA, ROUTINE_TYPE FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA=? AND SPECIFIC_NAME=? LIMIT 1",
+		id.DatabaseName,
+		id.ResourceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	routineModel.ID = dbResourceID{
+		ResourceTypeID: RoutineType,
+		DatabaseName:   id.DatabaseName,
+		ResourceName:   routineModel.Name,
+	}.String()
+
+	return &routineModel, nil
+}
+
 func (c *Client) GrantRoutinePrivilege(ctx context.Context, privilege string, schema string, routineName string, user string) error {
 	routineType, err := c.GetRoutineType(ctx, schema, routineName)
 	if err != nil {
@@ -115,8 +142,7 @@ func (c *Client) GrantRoutinePrivilege(ctx context.Context, privilege string, sc
 	query := fmt.Sprintf("GRANT %s ON %s %s.%s TO %s",
 		privilege, strings.ToUpper(routineType), schemaEsc, routineNameEsc, userGrant)
 
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
 func (c *Client) RevokeRoutinePrivilege(ctx context.Context, privilege string, schema string, routineName string, user string) error {
@@ -150,8 +176,7 @@ func (c *Client) RevokeRoutinePrivilege(ctx context.Context, privilege string, s
 
 	query := fmt.Sprintf("REVOKE %s ON %s %s.%s FROM %s",
 		privilege, strings.ToUpper(routineType), schemaEsc, routineNameEsc, userRevoke)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
 func (c *Client) GetRoutineType(ctx context.Context, schema, routineName string) (string, error) {
@@ -161,7 +186,7 @@ func (c *Client) GetRoutineType(ctx context.Context, schema, routineName string)
 		WHERE ROUTINE_SCHEMA = ? AND ROUTINE_NAME = ?
 	`
 	var routineType string
-	err := c.db.QueryRowContext(ctx, query, schema, routineName).Scan(&routineType)
+	err := c.pool().GetContext(ctx, &routineType, query, schema, routineName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get routine type: %w", err)
 	}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultProtectedAccounts are the accounts the connector never changes unless configured otherwise: root and the
+// accounts the server creates for itself.
+var DefaultProtectedAccounts = []string{
+	"root@*",
+	"mysql.sys@localhost",
+	"mysql.session@localhost",
+	"mysql.infoschema@localhost",
+	"mariadb.sys@localhost",
+}
+
+// Safeguards are the accounts the client never changes or deletes, and the privileges it never grants. Every
+// statement that changes accounts or privileges is checked against them before it is planned, logged or run.
+type Safeguards struct {
+	// protected are glob patterns matched against user@host.
+	protected []string
+	// denied are privilege keywords, normalized with normalizeKeyword.
+	denied map[string]struct{}
+}
+
+// normalizeKeyword upper-cases a privilege and writes it with spaces, so SYSTEM_USER and system user match.
+func normalizeKeyword(privilege string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(strings.ReplaceAll(privilege, "_", " "))), " ")
+}
+
+// NewSafeguards returns safeguards that protect the accounts matching the protected user@host patterns, written as
+// for matchGlob, and deny granting the denied privileges.
+func NewSafeguards(protected []string, denied []string) (*Safeguards, error) {
+	s := &Safeguards{
+		denied: make(map[string]struct{}),
+	}
+	for _, p := range protected {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if err := checkGlob(p); err != nil {
+			return nil, fmt.Errorf("invalid protected account pattern %q: %w", p, err)
+		}
+		s.protected = append(s.protected, p)
+	}
+	for _, d := range denied {
+		d = normalizeKeyword(d)
+		if d == "" {
+			continue
+		}
+		s.denied[d] = struct{}{}
+	}
+
+	return s, nil
+}
+
+// errBadGlob is returned for a pattern that ends in an unescaped backslash.
+var errBadGlob = errors.New("pattern ends with an escape character")
+
+// checkGlob returns an error when a pattern can't be matched by matchGlob.
+func checkGlob(pattern string) error {
+	escaped := false
+	for _, c := range pattern {
+		escaped = !escaped && c == '\\'
+	}
+	if escaped {
+		return errBadGlob
+	}
+	return nil
+}
+
+// matchGlob reports whether name matches a pattern, where * matches any run of characters, including the / of a
+// netmask host like 10.0.0.0/255.255.255.0, ? matches a single character and a backslash escapes the character
+// after it. The pattern must pass checkGlob.
+func matchGlob(pattern string, name string) bool {
+	pr, nr := []rune(pattern), []rune(name)
+	p, n := 0, 0
+	// star is the position after the last * and the position in name it is matched up to, for backtracking.
+	star, starN := -1, 0
+	for n < len(nr) {
+		if p < len(pr) {
+			switch c := pr[p]; {
+			case c == '*':
+				p++
+				star, starN = p, n
+				continue
+			case c == '?':
+				p++
+				n++
+				continue
+			case c == '\\' && p+1 < len(pr) && pr[p+1] == nr[n]:
+				p += 2
+				n++
+				continue
+			case c != '\\' && c == nr[n]:
+				p++
+				n++
+				continue
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		starN++
+		p, n = star, starN
+	}
+	for p < len(pr) && pr[p] == '*' {
+		p++
+	}
+	return p == len(pr)
+}
+
+// matchAccount returns the first pattern that matches an account, given as user@host.
+func matchAccount(patterns []string, account string) (string, bool) {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, account) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// CheckAccount returns an error when an account, given as user@host, is protected. A collapsed user is given with
+// its hosts separated by commas, and is protected when any of its accounts is.
+func (s *Safeguards) CheckAccount(account string) error {
+	if s == nil {
+		return nil
+	}
+
+	user, hosts := account, ""
+	if i := strings.LastIndex(account, "@"); i >= 0 {
+		user, hosts = account[:i], account[i+1:]
+	}
+
+	for _, host := range strings.Split(hosts, ",") {
+		name := user + "@" + host
+		if pattern, ok := matchAccount(s.protected, name); ok {
+			return fmt.Errorf("baton-mysql: %s is a protected account and cannot be changed (matches %q)", name, pattern)
+		}
+	}
+	return nil
+}
+
+// CheckPrivilege returns an error when a privilege, written as in GRANT or as an entitlement ID like system_user,
+// may not be granted.
+func (s *Safeguards) CheckPrivilege(privilege string) error {
+	if s == nil {
+		return nil
+	}
+
+	name := normalizeKeyword(privilege)
+	if _, ok := s.denied[name]; ok {
+		return fmt.Errorf("baton-mysql: granting %s is not allowed (%s is a denied privilege)", strings.ToUpper(privilege), name)
+	}
+	return nil
+}
+
+// checkStatement returns an error when a statement changes a protected account, creates, drops or grants a
+// protected role, or grants a denied privilege. Denying GRANT OPTION also denies granting any privilege or role
+// with the grant or admin option, and denying PROXY denies every proxy grant.
+func (s *Safeguards) checkStatement(stmt *AccountStatement) error {
+	var accounts []string
+	for _, a := range stmt.Accounts {
+		if a.Host == "" {
+			a.Host = "%"
+		}
+		accounts = append(accounts, accountKey(a))
+	}
+	// Roles and proxied accounts are checked when they are created, dropped or granted, but can still be revoked
+	// and set as a default role. They keep an empty host, which is how MariaDB roles are named.
+	switch stmt.Verb {
+	case "GRANT", "CREATE ROLE", "DROP ROLE":
+		for _, a := range append(append([]AccountName{}, stmt.Roles...), stmt.Proxied...) {
+			accounts = append(accounts, accountKey(a))
+		}
+	}
+	for _, a := range accounts {
+		err := s.CheckAccount(a)
+		if err != nil {
+			return err
+		}
+	}
+
+	if stmt.Verb != "GRANT" {
+		return nil
+	}
+	privileges := append([]string{}, stmt.Privileges...)
+	if len(stmt.Proxied) > 0 {
+		privileges = append(privileges, "PROXY")
+	}
+	if stmt.GrantOption {
+		privileges = append(privileges, GrantOptionPrivilege)
+	}
+	for _, p := range privileges {
+		err := s.CheckPrivilege(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetSafeguards sets the accounts the client never changes and the privileges it never grants. A client protects
+// DefaultProtectedAccounts until it is set.
+func (c *Client) SetSafeguards(s *Safeguards) {
+	c.safeguards = s
+}
+
+// CheckStatement returns an error when a statement that changes accounts or privileges would change a protected
+// account or grant a denied privilege. Other statements are not checked.
+func (c *Client) CheckStatement(query string) error {
+	if c.safeguards == nil {
+		return nil
+	}
+	stmt, ok := ParseAccountStatement(query, "")
+	if !ok {
+		return nil
+	}
+	return c.safeguards.checkStatement(stmt)
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeguardsCheckAccount(t *testing.T) {
+	s, err := NewSafeguards(append(DefaultProtectedAccounts, "svc_*@10.0.%"), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		account string
+		wantErr bool
+	}{
+		{"root@localhost", true},
+		{"root@%", true},
+		{"mysql.sys@localhost", true},
+		{"mysql.sys@%", false},
+		{"svc_backup@10.0.%", true},
+		{"svc_backup@%", false},
+		{"bob@%", false},
+		{"bob@%,root@localhost", false},
+		{"root@127.0.0.1,::1", true},
+		{"app@%,localhost", false},
+		{"root@10.0.0.0/255.255.255.0", true},
+		{"svc_backup@10.0.%/255.255.0.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.account, func(t *testing.T) {
+			err := s.CheckAccount(tt.account)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	_, err = NewSafeguards([]string{`bad\`}, nil)
+	require.Error(t, err)
+	_, err = NewSafeguards([]string{"root@[*]", `app\*@%`}, nil)
+	require.NoError(t, err)
+}
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"root@*", "root@localhost", true},
+		{"root@*", "root@10.0.0.0/255.255.255.0", true},
+		{"root@*", "root@", true},
+		{"root@*", "rooty@localhost", false},
+		{"*@10.0.0.0/255.255.255.0", "app@10.0.0.0/255.255.255.0", true},
+		{"app@10.0.0.0/*", "app@10.0.0.0/255.255.255.0", true},
+		{"svc_*@10.0.%", "svc_backup@10.0.%", true},
+		{"svc_*@10.0.%", "svc_backup@10.0.1.%", false},
+		{"*_admin@*", "ops_team_admin@%", true},
+		{"app@?", "app@%", true},
+		{"app@?", "app@10", false},
+		{"app@[::1]", "app@[::1]", true},
+		{"app@*", "app@fe80::1%eth0", true},
+		{`app\*@%`, "app*@%", true},
+		{`app\*@%`, "apps@%", false},
+		{"ünïcode@?", "ünïcode@é", true},
+		{"**", "", true},
+		{"", "x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, matchGlob(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestSafeguardsCheckStatement(t *testing.T) {
+	s, err := NewSafeguards(append(DefaultProtectedAccounts, "admin@%"), []string{"super", "system_user", "Grant Option"})
+	require.NoError(t, err)
+	c := &Client{safeguards: s}
+
+	tests := []struct {
+		stmt    string
+		wantErr bool
+	}{
+		{"GRANT SUPER ON *.* TO 'app'@'%'", true},
+		{"GRANT SYSTEM_USER ON *.* TO 'app'@'%'", true},
+		{"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%' WITH GRANT OPTION", true},
+		{"GRANT SELECT ON *.* TO 'app'@'%'", false},
+		{"GRANT CREATE TEMPORARY TABLES ON `shop`.* TO 'app'@'%'", false},
+		{"REVOKE SUPER ON *.* FROM 'app'@'%'", false},
+		{"GRANT SELECT ON `shop`.* TO 'root'@'localhost'", true},
+		{"REVOKE SELECT ON `shop`.* FROM 'root'@'localhost'", true},
+		{"DROP USER 'mysql.sys'@'localhost'", true},
+		{"DROP USER 'app'@'%'", false},
+		{"GRANT 'admin'@'%' TO 'app'@'%'", true},
+		{"GRANT 'reader'@'%' TO 'app'@'%' WITH ADMIN OPTION", true},
+		{"GRANT 'reader'@'%' TO 'app'@'%'", false},
+		{"REVOKE 'admin'@'%' FROM 'app'@'%'", false},
+		{"DROP ROLE 'admin'@'%'", true},
+		{"GRANT PROXY ON 'root'@'localhost' TO 'app'@'%'", true},
+		{"SELECT 1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			err := c.CheckStatement(tt.stmt)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// The statements are checked before they are planned or run, so every path that changes accounts fails the same.
+	ctx := context.Background()
+	_, err = c.Plan(func() error {
+		return c.DropUser(ctx, "root@localhost")
+	})
+	require.ErrorContains(t, err, "protected account")
+	require.ErrorContains(t, c.GrantRolePrivilege(ctx, "admin@%", "app@%", "role_assignment"), "protected account")
+	require.ErrorContains(t, c.GrantServerPrivilege(ctx, "app@%", "super", false), "denied privilege")
+	_, err = c.ExecContext(ctx, "REVOKE SELECT ON `shop`.* FROM 'root'@'localhost'")
+	require.ErrorContains(t, err, "protected account")
+
+	none, err := NewSafeguards(nil, nil)
+	require.NoError(t, err)
+	c.SetSafeguards(none)
+	require.NoError(t, c.CheckStatement("GRANT SUPER ON *.* TO 'root'@'localhost'"))
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"strings"
 
@@ -22,7 +23,7 @@ func (c *Client) GetServerInfo(ctx context.Context) (*ServerModel, error) {
 	l.Debug("getting server")
 
 	s := ServerModel{}
-	err := c.db.GetContext(ctx, &s, "SELECT @@hostname hostname, @@version version")
+	err := c.reader().GetContext(ctx, &s, "SELECT @@hostname hostname, @@version version")
 	if err != nil {
 		return nil, err
 	}
@@ -33,13 +34,30 @@ func (c *Client) GetServerInfo(ctx context.Context) (*ServerModel, error) {
 }
 
 func (c *Client) ExecContext(ctx context.Context, query string) (sql.Result, error) {
+	err := c.CheckStatement(query)
+	if err != nil {
+		return nil, err
+	}
 	if c.db == nil {
 		return nil, fmt.Errorf("database connection is not initialized")
 	}
-	return c.db.ExecContext(ctx, query)
+	if c.skipStatement(ctx, query) {
+		return driver.RowsAffected(0), nil
+	}
+	c.EndSnapshot(ctx)
+
+	var res sql.Result
+	err = c.withRetry(ctx, query, isIdempotent(query), func() error {
+		var err error
+		res, err = c.db.ExecContext(ctx, query)
+		return err
+	})
+	return res, err
 }
 
-func (c *Client) GrantServerPrivilege(ctx context.Context, user string, privilege string) error {
+// GrantServerPrivilege grants a global privilege. When withGrantOption is set the privilege is granted
+// WITH GRANT OPTION, which is how MySQL records grantable dynamic privileges.
+func (c *Client) GrantServerPrivilege(ctx context.Context, user string, privilege string, withGrantOption bool) error {
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
 		return fmt.Errorf("invalid user format, expected user@host")
@@ -55,11 +73,24 @@ func (c *Client) GrantServerPrivilege(ctx context.Context, user string, privileg
 	userGrant := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
 
 	query := fmt.Sprintf("GRANT %s ON *.* TO %s", strings.ToUpper(privilege), userGrant)
-	_ = c.db.MustExec(query)
-	return nil
+	if withGrantOption {
+		query += " WITH GRANT OPTION"
+	}
+	return c.execStatement(ctx, query)
+}
+
+// isDynamicPrivilege reports whether a global privilege keyword names a dynamic privilege. Dynamic privilege
+// keywords are written with underscores, like BACKUP_ADMIN, while static ones are written with spaces.
+func isDynamicPrivilege(privilege string) bool {
+	return strings.Contains(privilege, "_")
 }
 
-func (c *Client) RevokeServerPrivilege(ctx context.Context, user string, privilege string) error {
+// RevokeServerPrivilege revokes a global privilege. When grantOptionOnly is set only the ability to grant the
+// privilege is removed. For a static privilege that is REVOKE GRANT OPTION ON *.*, as the grant option of static
+// privileges is held once per level. A dynamic privilege has its own grant option, which MySQL can't revoke on its
+// own, so the privilege is revoked and granted again without it, in one transaction on one connection. If granting
+// it again fails, the privilege is granted back with its grant option, and the error says whether that worked.
+func (c *Client) RevokeServerPrivilege(ctx context.Context, user string, privilege string, grantOptionOnly bool) error {
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
 		return fmt.Errorf("invalid user format, expected user@host")
@@ -73,8 +104,75 @@ func (c *Client) RevokeServerPrivilege(ctx context.Context, user string, privile
 		return err
 	}
 	userRevoke := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
+	privilege = strings.ToUpper(privilege)
+
+	if !grantOptionOnly {
+		return c.execStatement(ctx, fmt.Sprintf("REVOKE %s ON *.* FROM %s", privilege, userRevoke))
+	}
+	if !isDynamicPrivilege(privilege) {
+		return c.execStatement(ctx, fmt.Sprintf("REVOKE %s ON *.* FROM %s", GrantOptionPrivilege, userRevoke))
+	}
+
+	return c.revokeDynamicGrantOption(ctx, userRevoke, privilege)
+}
+
+// revokeDynamicGrantOption revokes a dynamic privilege from an account and grants it again without the grant
+// option. Both statements run in one transaction on one connection, so no other statement runs between them.
+// GRANT and REVOKE commit on their own, so a failed regrant is undone by granting the privilege back WITH GRANT
+// OPTION on the same connection.
+func (c *Client) revokeDynamicGrantOption(ctx context.Context, account string, privilege string) error {
+	revoke := fmt.Sprintf("REVOKE %s ON *.* FROM %s", privilege, account)
+	regrant := fmt.Sprintf("GRANT %s ON *.* TO %s", privilege, account)
+	restore := regrant + " WITH GRANT OPTION"
+
+	// The privilege is granted again after it is revoked, so a privilege that may not be granted keeps its grant
+	// option rather than being lost.
+	for _, query := range []string{revoke, regrant} {
+		err := c.CheckStatement(query)
+		if err != nil {
+			return err
+		}
+	}
+	skipRevoke := c.skipStatement(ctx, revoke)
+	skipRegrant := c.skipStatement(ctx, regrant)
+	if skipRevoke || skipRegrant {
+		return nil
+	}
+	if c.db == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	c.EndSnapshot(ctx)
+
+	conn, err := c.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
-	query := fmt.Sprintf("REVOKE %s ON *.* FROM %s", strings.ToUpper(privilege), userRevoke)
-	_ = c.db.MustExec(query)
-	return nil
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, revoke)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, regrant)
+	if err != nil {
+		_ = tx.Rollback()
+		_, restoreErr := conn.ExecContext(ctx, restore)
+		if restoreErr != nil {
+			return fmt.Errorf(
+				"baton-mysql: revoked %s from %s to remove its grant option, but granting it again failed (%w), "+
+					"and so did restoring it, so the privilege is removed and must be granted again: %w",
+				privilege, account, err, restoreErr,
+			)
+		}
+		return fmt.Errorf(
+			"baton-mysql: unable to remove the grant option of %s from %s, the privilege was granted back with it: %w",
+			privilege, account, err,
+		)
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+// execDriver is a database/sql driver that records the statements it runs and fails those in fail.
+type execDriver struct {
+	mtx   sync.Mutex
+	fail  []string
+	conns int
+	ran   []string
+}
+
+func (d *execDriver) Connect(context.Context) (driver.Conn, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.conns++
+	return &execConn{d: d, id: d.conns}, nil
+}
+
+func (d *execDriver) Driver() driver.Driver { return nil }
+
+type execConn struct {
+	d  *execDriver
+	id int
+}
+
+func (c *execConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *execConn) Close() error                        { return nil }
+func (c *execConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *execConn) Commit() error                       { return nil }
+func (c *execConn) Rollback() error                     { return nil }
+
+func (c *execConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.d.mtx.Lock()
+	defer c.d.mtx.Unlock()
+	c.d.ran = append(c.d.ran, query)
+	if slices.Contains(c.d.fail, query) {
+		return nil, errors.New("Error 1227: Access denied")
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func TestRevokeServerPrivilegeGrantOption(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(fail ...string) (*Client, *execDriver) {
+		d := &execDriver{fail: fail}
+		return &Client{db: sqlx.NewDb(sql.OpenDB(d), "mysql")}, d
+	}
+
+	// The grant option of static privileges is held once per level, so it is revoked on its own.
+	c, d := newClient()
+	require.NoError(t, c.RevokeServerPrivilege(ctx, "app@%", "process", true))
+	require.Equal(t, []string{"REVOKE GRANT OPTION ON *.* FROM 'app'@'%'"}, d.ran)
+
+	c, d = newClient()
+	require.NoError(t, c.RevokeServerPrivilege(ctx, "app@%", "backup_admin", true))
+	require.Equal(t, []string{
+		"REVOKE BACKUP_ADMIN ON *.* FROM 'app'@'%'",
+		"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%'",
+	}, d.ran)
+	require.Equal(t, 1, d.conns)
+
+	// When the privilege can't be granted again it is restored with its grant option.
+	c, d = newClient("GRANT BACKUP_ADMIN ON *.* TO 'app'@'%'")
+	err := c.RevokeServerPrivilege(ctx, "app@%", "backup_admin", true)
+	require.ErrorContains(t, err, "granted back")
+	require.Equal(t, []string{
+		"REVOKE BACKUP_ADMIN ON *.* FROM 'app'@'%'",
+		"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%'",
+		"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%' WITH GRANT OPTION",
+	}, d.ran)
+	require.Equal(t, 1, d.conns)
+
+	// When restoring it fails too, the error says the privilege is lost.
+	c, _ = newClient(
+		"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%'",
+		"GRANT BACKUP_ADMIN ON *.* TO 'app'@'%' WITH GRANT OPTION",
+	)
+	err = c.RevokeServerPrivilege(ctx, "app@%", "backup_admin", true)
+	require.ErrorContains(t, err, "must be granted again")
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// querier runs read queries, either on the connection pool or in a snapshot.
+type querier interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// snapshotIdleTimeout is how long a snapshot lasts without being read from. The connector isn't told when a sync
+// ends, so a snapshot no read has used for this long is assumed to belong to a finished sync, and is ended so it
+// doesn't keep the server from purging the row versions it holds on to.
+const snapshotIdleTimeout = 10 * time.Minute
+
+// snapshot is a read-only REPEATABLE READ transaction started WITH CONSISTENT SNAPSHOT on a dedicated connection.
+type snapshot struct {
+	conn      *sqlx.Conn
+	startedAt time.Time
+	// idle ends the snapshot once it hasn't been read from for snapshotIdleTimeout.
+	idle *time.Timer
+}
+
+// snapshotState is the snapshot users, grants and schema objects are currently read from, if any.
+type snapshotState struct {
+	mtx     sync.Mutex
+	current *snapshot
+}
+
+// reader returns where users, grants and schema objects are read from: the current snapshot, or the pool. Reads
+// from the pool are retried after transient errors; a snapshot's reads are not, since losing its connection
+// loses the snapshot.
+func (c *Client) reader() querier {
+	c.snapshots.mtx.Lock()
+	defer c.snapshots.mtx.Unlock()
+
+	if s := c.snapshots.current; s != nil {
+		s.idle.Reset(snapshotIdleTimeout)
+		return s.conn
+	}
+	return c.pool()
+}
+
+// BeginSnapshot ends the current snapshot, if any, and starts a new one. Until it ends, users, grants and
+// schema objects are read as they were when it started, so a sync sees the grant tables at a single point in
+// time. The snapshot only covers transactional tables: the grant tables of MySQL 8 and later are InnoDB, while
+// older servers and MariaDB keep them in MyISAM or Aria tables that are read as they are. The snapshot ends on its
+// own once it hasn't been read from for snapshotIdleTimeout.
+// It returns the time the snapshot started, as reported by the server.
+func (c *Client) BeginSnapshot(ctx context.Context) (time.Time, error) {
+	c.EndSnapshot(ctx)
+
+	// The snapshot keeps its connection for as long as it lasts, so the pool needs another one for everything else.
+	c.db.SetMaxOpenConns(2)
+
+	conn, err := c.db.Connx(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	_, err = conn.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY")
+	}
+	var startedAt int64
+	if err == nil {
+		err = conn.GetContext(ctx, &startedAt, "SELECT CAST(UNIX_TIMESTAMP() AS SIGNED)")
+	}
+	if err != nil {
+		_ = conn.Close()
+		return time.Time{}, err
+	}
+
+	s := &snapshot{
+		conn:      conn,
+		startedAt: time.Unix(startedAt, 0).UTC(),
+	}
+	l := ctxzap.Extract(ctx)
+
+	c.snapshots.mtx.Lock()
+	c.snapshots.current = s
+	s.idle = time.AfterFunc(snapshotIdleTimeout, func() {
+		if c.endSnapshot(context.Background(), s) {
+			l.Info("ended idle consistent snapshot", zap.Time("started_at", s.startedAt))
+		}
+	})
+	c.snapshots.mtx.Unlock()
+
+	l.Debug("started consistent snapshot", zap.Time("started_at", s.startedAt))
+
+	return s.startedAt, nil
+}
+
+// SnapshotTime returns when the current snapshot started, or false when reads are not from a snapshot.
+func (c *Client) SnapshotTime() (time.Time, bool) {
+	c.snapshots.mtx.Lock()
+	defer c.snapshots.mtx.Unlock()
+
+	if c.snapshots.current == nil {
+		return time.Time{}, false
+	}
+	return c.snapshots.current.startedAt, true
+}
+
+// EndSnapshot ends the current snapshot, if any, and goes back to reading the latest committed data.
+func (c *Client) EndSnapshot(ctx context.Context) {
+	c.endSnapshot(ctx, nil)
+}
+
+// endSnapshot ends the current snapshot, or only the given one when it is set, and reports whether it ended one.
+func (c *Client) endSnapshot(ctx context.Context, only *snapshot) bool {
+	c.snapshots.mtx.Lock()
+	s := c.snapshots.current
+	if s == nil || (only != nil && s != only) {
+		c.snapshots.mtx.Unlock()
+		return false
+	}
+	c.snapshots.current = nil
+	s.idle.Stop()
+	c.snapshots.mtx.Unlock()
+
+	_, err := s.conn.ExecContext(ctx, "ROLLBACK")
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("unable to end consistent snapshot", zap.Error(err))
+	}
+	_ = s.conn.Close()
+	c.db.SetMaxOpenConns(1)
+	return true
+}
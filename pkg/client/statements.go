@@ -0,0 +1,429 @@
+package client
+
+import (
+	"strings"
+)
+
+// AccountName is an account or role named in a statement. Host is empty when the statement omits it, which means
+// '%' for accounts and MySQL roles and no host for MariaDB roles.
+type AccountName struct {
+	User string
+	Host string
+}
+
+// AccountStatement is an account management or privilege statement that changes what the connector syncs.
+type AccountStatement struct {
+	// Verb is the statement, like GRANT, CREATE USER or SET DEFAULT ROLE.
+	Verb string
+	// Accounts are the users and roles whose grants or attributes changed.
+	Accounts []AccountName
+	// Roles are the roles granted, revoked or set as a default role.
+	Roles []AccountName
+	// Privileges are the upper-cased privileges of a GRANT or REVOKE on an object, like "SELECT" or "ALL".
+	Privileges []string
+	// Columns are the columns of the privileges given with a column list, like SELECT (id, name), keyed by
+	// privilege.
+	Columns map[string][]string
+	// ObjectType is TABLE, FUNCTION or PROCEDURE when the statement names it.
+	ObjectType string
+	// Database and Object are the privilege level: both are "*" for *.*, Object is "*" for db.*.
+	Database string
+	Object   string
+	// GrantOption is set for a GRANT WITH GRANT OPTION or WITH ADMIN OPTION.
+	GrantOption bool
+	// Proxied is the account of a GRANT or REVOKE PROXY ON, which is not a resource the connector models.
+	Proxied []AccountName
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// is reports whether the token is the unquoted keyword or punctuation s.
+func (t token) is(s string) bool {
+	return t.kind != tokenQuoted && strings.EqualFold(t.value, s)
+}
+
+// tokenize splits a statement into words, quoted names and strings, and punctuation. Comments are dropped.
+func tokenize(stmt string) []token {
+	isWord := func(c byte) bool {
+		return c >= 0x80 || c == '_' || c == '$' || c == '*' || c == '%' ||
+			(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+
+	var ret []token
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case strings.HasPrefix(stmt[i:], "/*"):
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return ret
+			}
+			i += end + 4
+
+		case c == '#' || strings.HasPrefix(stmt[i:], "-- "):
+			for i < len(stmt) && stmt[i] != '\n' {
+				i++
+			}
+
+		case c == '\'' || c == '"' || c == '`':
+			var sb strings.Builder
+			for i++; i < len(stmt); i++ {
+				if stmt[i] == '\\' && c != '`' && i+1 < len(stmt) {
+					i++
+					sb.WriteByte(stmt[i])
+					continue
+				}
+				if stmt[i] == c {
+					if i+1 < len(stmt) && stmt[i+1] == c {
+						i++
+						sb.WriteByte(c)
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(stmt[i])
+			}
+			ret = append(ret, token{kind: tokenQuoted, value: sb.String()})
+
+		case isWord(c):
+			start := i
+			for i < len(stmt) && isWord(stmt[i]) {
+				i++
+			}
+			ret = append(ret, token{kind: tokenWord, value: stmt[start:i]})
+
+		default:
+			ret = append(ret, token{kind: tokenPunct, value: string(c)})
+			i++
+		}
+	}
+
+	return ret
+}
+
+// statementParser walks the tokens of one statement.
+type statementParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *statementParser) done() bool {
+	return p.pos >= len(p.tokens) || p.tokens[p.pos].is(";")
+}
+
+func (p *statementParser) peek() token {
+	if p.done() {
+		return token{kind: tokenPunct}
+	}
+	return p.tokens[p.pos]
+}
+
+// accept consumes the keywords if they are next.
+func (p *statementParser) accept(keywords ...string) bool {
+	if p.pos+len(keywords) > len(p.tokens) {
+		return false
+	}
+	for i, k := range keywords {
+		if !p.tokens[p.pos+i].is(k) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+// account parses user[@host]. It returns false for CURRENT_USER and anything that is not a name.
+func (p *statementParser) account() (AccountName, bool) {
+	t := p.peek()
+	if t.kind == tokenPunct {
+		return AccountName{}, false
+	}
+	p.pos++
+	if t.is("CURRENT_USER") {
+		p.accept("(", ")")
+		return AccountName{}, false
+	}
+
+	a := AccountName{User: t.value}
+	if p.peek().is("@") {
+		p.pos++
+		if host := p.peek(); host.kind != tokenPunct {
+			a.Host = host.value
+			p.pos++
+		}
+	}
+	return a, true
+}
+
+// accountList parses comma-separated accounts. After each account, tokens are skipped until the next comma or
+// one of the stop keywords, so authentication options between accounts are ignored.
+func (p *statementParser) accountList(stop ...string) []AccountName {
+	var ret []AccountName
+	for !p.done() {
+		a, ok := p.account()
+		if ok {
+			ret = append(ret, a)
+		}
+
+		depth := 0
+		for !p.done() {
+			t := p.peek()
+			if depth == 0 && (t.is(",") || p.atStop(stop)) {
+				break
+			}
+			switch {
+			case t.is("("):
+				depth++
+			case t.is(")"):
+				depth--
+			}
+			p.pos++
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	return ret
+}
+
+// atStop reports whether the next tokens are one of the stop keywords, which may be several words like
+// "DEFAULT ROLE". A keyword directly after IDENTIFIED, BY or USING belongs to the authentication clause instead.
+func (p *statementParser) atStop(stop []string) bool {
+	if p.pos > 0 {
+		prev := p.tokens[p.pos-1]
+		if prev.is("IDENTIFIED") || prev.is("BY") || prev.is("USING") {
+			return false
+		}
+	}
+	for _, s := range stop {
+		words := strings.Fields(s)
+		if p.pos+len(words) > len(p.tokens) {
+			continue
+		}
+		match := true
+		for i, w := range words {
+			if !p.tokens[p.pos+i].is(w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// privilegeList parses the privileges of a GRANT or REVOKE up to ON, and the columns of those given with a column
+// list. It returns false when the list has no ON, so it names roles instead.
+func (p *statementParser) privilegeList() ([]string, map[string][]string, bool) {
+	start := p.pos
+
+	var ret []string
+	var columns map[string][]string
+	var words []string
+	var cols []string
+	depth := 0
+	for !p.done() {
+		t := p.peek()
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case depth > 0:
+			if !t.is(",") {
+				cols = append(cols, t.value)
+			}
+		case t.is(",") || t.is("ON"):
+			if len(words) > 0 {
+				priv := strings.ToUpper(strings.Join(words, " "))
+				ret = append(ret, priv)
+				if len(cols) > 0 {
+					if columns == nil {
+						columns = make(map[string][]string)
+					}
+					columns[priv] = append(columns[priv], cols...)
+				}
+			}
+			words, cols = nil, nil
+			if t.is("ON") {
+				p.pos++
+				return ret, columns, true
+			}
+		case t.is("TO") || t.is("FROM"):
+			p.pos = start
+			return nil, nil, false
+		default:
+			words = append(words, t.value)
+		}
+		p.pos++
+	}
+
+	p.pos = start
+	return nil, nil, false
+}
+
+// level parses the object of a GRANT or REVOKE, like *.*, db.*, db.table or PROCEDURE db.proc.
+func (p *statementParser) level(stmt *AccountStatement, defaultDB string) {
+	for _, objectType := range []string{"TABLE", "FUNCTION", "PROCEDURE"} {
+		if p.accept(objectType) {
+			stmt.ObjectType = objectType
+			break
+		}
+	}
+
+	var parts []string
+	for !p.done() && !p.peek().is("TO") && !p.peek().is("FROM") {
+		t := p.peek()
+		if !t.is(".") {
+			parts = append(parts, t.value)
+		}
+		p.pos++
+	}
+
+	switch len(parts) {
+	case 1:
+		stmt.Database, stmt.Object = defaultDB, parts[0]
+		if parts[0] == "*" && defaultDB == "" {
+			stmt.Database = "*"
+		}
+	case 2:
+		stmt.Database, stmt.Object = parts[0], parts[1]
+	}
+}
+
+// ParseAccountStatement parses a statement that manages accounts, roles or privileges. defaultDB is the current
+// database, used for privilege levels without one. It returns false for other statements.
+func ParseAccountStatement(stmt string, defaultDB string) (*AccountStatement, bool) {
+	p := &statementParser{tokens: tokenize(stmt)}
+	for p.accept("USE") {
+		for !p.done() {
+			defaultDB = p.peek().value
+			p.pos++
+		}
+		p.accept(";")
+	}
+
+	ret := &AccountStatement{}
+	switch {
+	case p.accept("CREATE", "USER"), p.accept("CREATE", "OR", "REPLACE", "USER"):
+		ret.Verb = "CREATE USER"
+		p.accept("IF", "NOT", "EXISTS")
+		ret.Accounts = p.accountList("DEFAULT ROLE", "REQUIRE", "WITH", "PASSWORD", "ACCOUNT", "COMMENT", "ATTRIBUTE",
+			"FAILED_LOGIN_ATTEMPTS", "PASSWORD_LOCK_TIME", "RESOURCE")
+		if p.accept("DEFAULT", "ROLE") {
+			ret.Roles = p.accountList()
+		}
+
+	case p.accept("ALTER", "USER"):
+		ret.Verb = "ALTER USER"
+		p.accept("IF", "EXISTS")
+		ret.Accounts = p.accountList("DEFAULT ROLE", "REQUIRE", "WITH", "PASSWORD", "ACCOUNT", "COMMENT", "ATTRIBUTE",
+			"FAILED_LOGIN_ATTEMPTS", "PASSWORD_LOCK_TIME", "RESOURCE", "DISCARD", "RETAIN")
+		if p.accept("DEFAULT", "ROLE") && !p.peek().is("NONE") && !p.peek().is("ALL") {
+			ret.Roles = p.accountList()
+		}
+
+	case p.accept("DROP", "USER"):
+		ret.Verb = "DROP USER"
+		p.accept("IF", "EXISTS")
+		ret.Accounts = p.accountList()
+
+	case p.accept("RENAME", "USER"):
+		ret.Verb = "RENAME USER"
+		for !p.done() {
+			if a, ok := p.account(); ok {
+				ret.Accounts = append(ret.Accounts, a)
+			}
+			if !p.accept("TO") && !p.accept(",") {
+				break
+			}
+		}
+
+	case p.accept("CREATE", "ROLE"), p.accept("CREATE", "OR", "REPLACE", "ROLE"):
+		ret.Verb = "CREATE ROLE"
+		p.accept("IF", "NOT", "EXISTS")
+		ret.Roles = p.accountList("WITH")
+
+	case p.accept("DROP", "ROLE"):
+		ret.Verb = "DROP ROLE"
+		p.accept("IF", "EXISTS")
+		ret.Roles = p.accountList()
+
+	case p.accept("SET", "DEFAULT", "ROLE"):
+		ret.Verb = "SET DEFAULT ROLE"
+		if !p.accept("NONE") && !p.accept("ALL") {
+			ret.Roles = p.accountList("TO", "FOR")
+		}
+		if p.accept("TO") || p.accept("FOR") {
+			ret.Accounts = p.accountList()
+		}
+
+	case p.accept("GRANT"):
+		ret.Verb = "GRANT"
+		p.parseGrant(ret, defaultDB, "TO")
+
+	case p.accept("REVOKE"):
+		ret.Verb = "REVOKE"
+		p.accept("IF", "EXISTS")
+		p.accept("ADMIN", "OPTION", "FOR")
+		p.parseGrant(ret, defaultDB, "FROM")
+
+	default:
+		return nil, false
+	}
+
+	if len(ret.Accounts) == 0 && len(ret.Roles) == 0 {
+		return nil, false
+	}
+
+	return ret, true
+}
+
+// parseGrant parses the rest of a GRANT or REVOKE, which either grants privileges on an object or grants roles.
+func (p *statementParser) parseGrant(stmt *AccountStatement, defaultDB string, direction string) {
+	start := p.pos
+	privs, columns, onObject := p.privilegeList()
+	switch {
+	case onObject && len(privs) == 1 && privs[0] == "PROXY":
+		stmt.Proxied = p.accountList(direction)
+	case onObject:
+		stmt.Privileges = privs
+		stmt.Columns = columns
+		p.level(stmt, defaultDB)
+	case p.peek().is("ALL"):
+		// REVOKE ALL PRIVILEGES, GRANT OPTION FROM removes every privilege on every level.
+		stmt.Privileges = []string{"ALL"}
+		stmt.Database, stmt.Object = "*", "*"
+		for !p.done() && !p.peek().is(direction) {
+			p.pos++
+		}
+	default:
+		p.pos = start
+		stmt.Roles = p.accountList(direction)
+	}
+
+	if p.accept(direction) {
+		stmt.Accounts = p.accountList("WITH", "AS", "IGNORE", "REQUIRE")
+	}
+	if p.accept("WITH", "GRANT", "OPTION") || p.accept("WITH", "ADMIN", "OPTION") {
+		stmt.GrantOption = true
+	}
+}
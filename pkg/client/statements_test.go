@@ -0,0 +1,200 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		stmt      string
+		defaultDB string
+		want      *AccountStatement
+	}{
+		{
+			name: "grant on a table",
+			stmt: "GRANT SELECT, INSERT (id, name), UPDATE ON `shop`.`orders` TO 'app'@'10.0.0.%', `report`@`%` WITH GRANT OPTION",
+			want: &AccountStatement{
+				Verb:        "GRANT",
+				Accounts:    []AccountName{{User: "app", Host: "10.0.0.%"}, {User: "report", Host: "%"}},
+				Privileges:  []string{"SELECT", "INSERT", "UPDATE"},
+				Columns:     map[string][]string{"INSERT": {"id", "name"}},
+				Database:    "shop",
+				Object:      "orders",
+				GrantOption: true,
+			},
+		},
+		{
+			name:      "grant on a table in the current database",
+			stmt:      "use `shop`; GRANT CREATE TEMPORARY TABLES ON orders TO app",
+			defaultDB: "ignored",
+			want: &AccountStatement{
+				Verb:       "GRANT",
+				Accounts:   []AccountName{{User: "app"}},
+				Privileges: []string{"CREATE TEMPORARY TABLES"},
+				Database:   "shop",
+				Object:     "orders",
+			},
+		},
+		{
+			name: "grant on a procedure",
+			stmt: "GRANT EXECUTE ON PROCEDURE shop.refund TO 'app'@'%'",
+			want: &AccountStatement{
+				Verb:       "GRANT",
+				Accounts:   []AccountName{{User: "app", Host: "%"}},
+				Privileges: []string{"EXECUTE"},
+				ObjectType: "PROCEDURE",
+				Database:   "shop",
+				Object:     "refund",
+			},
+		},
+		{
+			name: "global revoke",
+			stmt: "REVOKE IF EXISTS BACKUP_ADMIN ON *.* FROM 'ops'@'localhost' IGNORE UNKNOWN USER",
+			want: &AccountStatement{
+				Verb:       "REVOKE",
+				Accounts:   []AccountName{{User: "ops", Host: "localhost"}},
+				Privileges: []string{"BACKUP_ADMIN"},
+				Database:   "*",
+				Object:     "*",
+			},
+		},
+		{
+			name: "revoke everything",
+			stmt: "REVOKE ALL PRIVILEGES, GRANT OPTION FROM 'ops'@'localhost'",
+			want: &AccountStatement{
+				Verb:       "REVOKE",
+				Accounts:   []AccountName{{User: "ops", Host: "localhost"}},
+				Privileges: []string{"ALL"},
+				Database:   "*",
+				Object:     "*",
+			},
+		},
+		{
+			name: "role grant",
+			stmt: "GRANT `reader`@`%`, 'writer' TO `app`@`%` WITH ADMIN OPTION",
+			want: &AccountStatement{
+				Verb:        "GRANT",
+				Accounts:    []AccountName{{User: "app", Host: "%"}},
+				Roles:       []AccountName{{User: "reader", Host: "%"}, {User: "writer"}},
+				GrantOption: true,
+			},
+		},
+		{
+			name: "mariadb role revoke",
+			stmt: "REVOKE ADMIN OPTION FOR reader FROM app@localhost",
+			want: &AccountStatement{
+				Verb:     "REVOKE",
+				Accounts: []AccountName{{User: "app", Host: "localhost"}},
+				Roles:    []AccountName{{User: "reader"}},
+			},
+		},
+		{
+			name: "create users with authentication and default role",
+			stmt: "CREATE USER IF NOT EXISTS 'a'@'%' IDENTIFIED WITH 'caching_sha2_password' AS '$A$005$x,y', " +
+				"'b'@'%' IDENTIFIED BY PASSWORD '*ABC' DEFAULT ROLE `reader`@`%` REQUIRE NONE PASSWORD EXPIRE DEFAULT",
+			want: &AccountStatement{
+				Verb:     "CREATE USER",
+				Accounts: []AccountName{{User: "a", Host: "%"}, {User: "b", Host: "%"}},
+				Roles:    []AccountName{{User: "reader", Host: "%"}},
+			},
+		},
+		{
+			name: "mariadb create user with a plugin",
+			stmt: "CREATE USER 'a'@'%' IDENTIFIED VIA ed25519 USING PASSWORD('secret'), 'b'@'%'",
+			want: &AccountStatement{
+				Verb:     "CREATE USER",
+				Accounts: []AccountName{{User: "a", Host: "%"}, {User: "b", Host: "%"}},
+			},
+		},
+		{
+			name: "alter user default role",
+			stmt: "ALTER USER 'app'@'%' DEFAULT ROLE reader, writer",
+			want: &AccountStatement{
+				Verb:     "ALTER USER",
+				Accounts: []AccountName{{User: "app", Host: "%"}},
+				Roles:    []AccountName{{User: "reader"}, {User: "writer"}},
+			},
+		},
+		{
+			name: "alter user lock",
+			stmt: "/* comment */ ALTER USER 'app'@'%' ACCOUNT LOCK",
+			want: &AccountStatement{
+				Verb:     "ALTER USER",
+				Accounts: []AccountName{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name: "drop users",
+			stmt: "DROP USER IF EXISTS 'a'@'%', b",
+			want: &AccountStatement{
+				Verb:     "DROP USER",
+				Accounts: []AccountName{{User: "a", Host: "%"}, {User: "b"}},
+			},
+		},
+		{
+			name: "rename user",
+			stmt: "RENAME USER 'a'@'%' TO 'b'@'%'",
+			want: &AccountStatement{
+				Verb:     "RENAME USER",
+				Accounts: []AccountName{{User: "a", Host: "%"}, {User: "b", Host: "%"}},
+			},
+		},
+		{
+			name: "create role",
+			stmt: "CREATE ROLE IF NOT EXISTS 'reader', 'writer'@'%'",
+			want: &AccountStatement{
+				Verb:  "CREATE ROLE",
+				Roles: []AccountName{{User: "reader"}, {User: "writer", Host: "%"}},
+			},
+		},
+		{
+			name: "mysql set default role",
+			stmt: "SET DEFAULT ROLE `reader`@`%` TO `app`@`%`, 'b'",
+			want: &AccountStatement{
+				Verb:     "SET DEFAULT ROLE",
+				Accounts: []AccountName{{User: "app", Host: "%"}, {User: "b"}},
+				Roles:    []AccountName{{User: "reader", Host: "%"}},
+			},
+		},
+		{
+			name: "mariadb set default role",
+			stmt: "SET DEFAULT ROLE NONE FOR 'app'@'%'",
+			want: &AccountStatement{
+				Verb:     "SET DEFAULT ROLE",
+				Accounts: []AccountName{{User: "app", Host: "%"}},
+			},
+		},
+		{
+			name: "grant proxy",
+			stmt: "GRANT PROXY ON 'root'@'localhost' TO 'admin'@'%'",
+			want: &AccountStatement{
+				Verb:     "GRANT",
+				Accounts: []AccountName{{User: "admin", Host: "%"}},
+				Proxied:  []AccountName{{User: "root", Host: "localhost"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseAccountStatement(tt.stmt, tt.defaultDB)
+			require.True(t, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAccountStatement_other(t *testing.T) {
+	for _, stmt := range []string{
+		"INSERT INTO t VALUES (1)",
+		"CREATE TABLE grants (id int)",
+		"SET ROLE ALL",
+		"ALTER USER CURRENT_USER() IDENTIFIED BY 'x'",
+		"",
+	} {
+		_, ok := ParseAccountStatement(stmt, "")
+		require.False(t, ok, stmt)
+	}
+}
@@ -48,7 +48,7 @@ func (c *Client) ListTables(ctx context.Context, parentResourceID *v2.ResourceId
 		args = append(args, offset)
 	}
 
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	rows, err := c.reader().QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -82,6 +82,29 @@ func (c *Client) ListTables(ctx context.Context, parentResourceID *v2.ResourceId
 	return ret, nextPageToken, nil
 }
 
+// GetTable returns the table with the given resource ID.
+func (c *Client) GetTable(ctx context.Context, resourceID *v2.ResourceId) (*TableModel, error) {
+	id, err := newDbResourceID(resourceID.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var tableModel TableModel
+	err = c.reader().GetContext(
+		ctx,
+		&tableModel,
+		"SELECT TABLE_NAME, TABLE_SCHEMA, TABLE_TYPE FROM information_schema.TABLES WHERE TABLE_SCHEMA=? AND TABLE_NAME=?",
+		id.DatabaseName,
+		id.ResourceName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	tableModel.ID = id.Table().String()
+
+	return &tableModel, nil
+}
+
 func (c *Client) GrantTablePrivilege(ctx context.Context, table string, user string, privilege string) error {
 	userSplit := strings.Split(user, "@")
 	if len(userSplit) != 2 {
@@ -103,8 +126,7 @@ func (c *Client) GrantTablePrivilege(ctx context.Context, table string, user str
 	}
 
 	query := fmt.Sprintf("GRANT %s ON %s TO %s", strings.ToUpper(privilege), escapedTable, userGrant)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
 func (c *Client) RevokeTablePrivilege(ctx context.Context, table string, user string, privilege string) error {
@@ -128,6 +150,5 @@ func (c *Client) RevokeTablePrivilege(ctx context.Context, table string, user st
 	}
 
 	query := fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.ToUpper(privilege), escapedTable, userRevoke)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
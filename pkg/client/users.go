@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -20,6 +21,18 @@ type User struct {
 	Host     string `db:"Host"`
 	User     string `db:"User"`
 	Privs    string `db:"privs"`
+	// Plugin is the authentication plugin, like caching_sha2_password or auth_socket.
+	Plugin        string `db:"plugin"`
+	AccountLocked string `db:"account_locked"`
+	// EmptyPassword is set when authentication_string is empty.
+	EmptyPassword bool `db:"empty_password"`
+	// DefaultRole is the role MariaDB activates when the user logs in.
+	DefaultRole string `db:"default_role"`
+}
+
+// IsLocked reports whether the account is locked and cannot log in.
+func (u *User) IsLocked() bool {
+	return u.AccountLocked == "Y"
 }
 
 func (u *User) GetID() string {
@@ -42,6 +55,107 @@ func (u *User) GetPrivs(ctx context.Context) map[string]struct{} {
 	return ret
 }
 
+// mysqlPasswordPlugins are the authentication plugins CREATE ROLE assigns. Accounts using other plugins, like
+// auth_socket or LDAP and PAM, have an empty authentication_string but are still users.
+var mysqlPasswordPlugins = []string{"caching_sha2_password", "mysql_native_password", "sha256_password"}
+
+// roleRow holds the parts of a mysql.user row that decide whether a MySQL account is a role.
+type roleRow struct {
+	locked               bool
+	authenticationString string
+	plugin               string
+	granted              bool
+	defaultRole          bool
+}
+
+// roleTerm is one way a MySQL account can be a role, as a condition on mysql.user and the same test on a row.
+type roleTerm struct {
+	sql   string
+	match func(r roleRow) bool
+}
+
+// mysqlRoleTerms returns the ways a MySQL account can be a role: it is locked with no password and a password
+// plugin, as CREATE ROLE leaves it, or it is granted to other accounts, or it is set as a default role.
+func mysqlRoleTerms(caps ServerCapabilities) []roleTerm {
+	terms := []roleTerm{
+		{
+			sql: `(account_locked = 'Y' AND authentication_string = '' AND plugin IN ('` +
+				strings.Join(mysqlPasswordPlugins, "', '") + `'))`,
+			match: func(r roleRow) bool {
+				return r.locked && r.authenticationString == "" && slices.Contains(mysqlPasswordPlugins, r.plugin)
+			},
+		},
+		{
+			sql:   `EXISTS (SELECT 1 FROM mysql.role_edges re WHERE re.FROM_USER = mysql.user.User AND re.FROM_HOST = mysql.user.Host)`,
+			match: func(r roleRow) bool { return r.granted },
+		},
+	}
+	if caps.DefaultRoles {
+		terms = append(terms, roleTerm{
+			sql:   `EXISTS (SELECT 1 FROM mysql.default_roles dr WHERE dr.DEFAULT_ROLE_USER = mysql.user.User AND dr.DEFAULT_ROLE_HOST = mysql.user.Host)`,
+			match: func(r roleRow) bool { return r.defaultRole },
+		})
+	}
+	return terms
+}
+
+// roleCondition returns the condition that is true for mysql.user rows that are roles. MariaDB flags roles with
+// is_role, and MySQL roles match one of mysqlRoleTerms. Servers without roles have none.
+func (c *Client) roleCondition() string {
+	caps := c.Capabilities()
+	switch {
+	case !caps.Roles:
+		return `FALSE`
+	case caps.Flavor == FlavorMariaDB:
+		return `is_role = 'Y'`
+	}
+
+	var terms []string
+	for _, t := range mysqlRoleTerms(caps) {
+		terms = append(terms, t.sql)
+	}
+	return `(` + strings.Join(terms, `
+	OR `) + `)`
+}
+
+// userTypeSelect returns the user_type column.
+func (c *Client) userTypeSelect() string {
+	return fmt.Sprintf(`CASE WHEN %s THEN 'role' ELSE 'user' END AS user_type`, c.roleCondition())
+}
+
+// userTypeFilter returns the condition selecting accounts of the given type from mysql.user.
+func (c *Client) userTypeFilter(userType string) (string, error) {
+	switch userType {
+	case UserType:
+		return fmt.Sprintf(`WHERE NOT (%s) `, c.roleCondition()), nil
+
+	case RoleType:
+		return fmt.Sprintf(`WHERE %s `, c.roleCondition()), nil
+
+	default:
+		return "", fmt.Errorf("unexpected user type %s", userType)
+	}
+}
+
+// accountSelect returns the authentication columns of an account. When grouped, the hosts of a user are
+// collapsed, the account is only locked if every host is locked and has an empty password if any host has one.
+func (c *Client) accountSelect(grouped bool) string {
+	mariaDB := c.Capabilities().Flavor == FlavorMariaDB
+
+	if grouped {
+		if mariaDB {
+			return `GROUP_CONCAT(DISTINCT plugin) AS plugin, MAX(authentication_string = '') AS empty_password`
+		}
+		return `GROUP_CONCAT(DISTINCT plugin) AS plugin, MIN(account_locked) AS account_locked,
+			MAX(authentication_string = '') AS empty_password`
+	}
+
+	if mariaDB {
+		return `plugin, default_role, authentication_string = '' AS empty_password`
+	}
+	return `plugin, account_locked, authentication_string = '' AS empty_password`
+}
+
 func (c *Client) userPrivsSelect(sb *strings.Builder) error {
 	_, err := sb.WriteString(`CONCAT(
 CASE WHEN Select_priv = 'Y' THEN 'select,' ELSE '' END,
@@ -71,14 +185,13 @@ CASE WHEN Alter_routine_priv = 'Y' THEN 'alter_routine,' ELSE '' END,
 CASE WHEN Create_user_priv = 'Y' THEN 'create_user,' ELSE '' END,
 CASE WHEN Event_priv = 'Y' THEN 'event,' ELSE '' END,
 CASE WHEN Trigger_priv = 'Y' THEN 'trigger,' ELSE '' END,
-CASE WHEN Create_tablespace_priv = 'Y' THEN 'create_tablespace,' ELSE '' END,
-CASE WHEN Create_role_priv = 'Y' THEN 'create_role,' ELSE '' END,
-CASE WHEN Drop_role_priv = 'Y' THEN 'drop_role,' ELSE '' END`)
+CASE WHEN Create_tablespace_priv = 'Y' THEN 'create_tablespace,' ELSE '' END`)
 	if err != nil {
 		return err
 	}
 
-	if c.IsVersion8() {
+	// Create_role_priv and Drop_role_priv only exist in MySQL's mysql.user table.
+	if caps := c.Capabilities(); caps.Flavor == FlavorMySQL && caps.Roles {
 		_, err = sb.WriteString(`,
 CASE WHEN Create_role_priv = 'Y' THEN 'create_role,' ELSE '' END,
 CASE WHEN Drop_role_priv = 'Y' THEN 'drop_role,' ELSE '' END
@@ -102,48 +215,85 @@ CASE WHEN Drop_role_priv = 'Y' THEN 'drop_role,' ELSE '' END
 //				  References_priv, Index_priv, Alter_priv, Show_db_priv, Super_priv, Create_tmp_table_priv, Lock_tables_priv,
 //				  Execute_priv, Repl_slave_priv, Repl_client_priv, Create_view_priv, Show_view_priv, Create_routine_priv,
 //				  Alter_routine_priv, Create_user_priv, Event_priv, Trigger_priv, Create_tablespace_priv, Create_role_priv,
-//				  Drop_role_priv, File_priv,, Grant_priv, authentication_string) ON mysql.user TO user@host;
+//				  Drop_role_priv, File_priv,, Grant_priv, authentication_string, plugin, account_locked) ON mysql.user TO user@host;
+//	GRANT SELECT (DEFAULT_ROLE_HOST, DEFAULT_ROLE_USER) ON mysql.default_roles TO user@host;
+//
+// On MariaDB, is_role and default_role are read instead of Create_role_priv, Drop_role_priv and account_locked.
 func (c *Client) GetUser(ctx context.Context, user string, host string) (*User, error) {
 	u := User{}
-	sb := &strings.Builder{}
-	_, err := sb.WriteString(`SELECT User, Host,`)
+	sb, err := c.userQuery()
 	if err != nil {
 		return nil, err
 	}
-
-	err = c.userPrivsSelect(sb)
+	_, err = sb.WriteString(`WHERE User = ? AND Host = ?`)
 	if err != nil {
 		return nil, err
 	}
-	_, err = sb.WriteString(`CASE WHEN authentication_string = '' THEN 'role' ELSE 'user' END AS user_type `)
+
+	err = c.reader().GetContext(ctx, &u, sb.String(), user, host)
 	if err != nil {
 		return nil, err
 	}
-	_, err = sb.WriteString(`FROM mysql.user WHERE User = ? AND Host = ?`)
+
+	return &u, nil
+}
+
+// userQuery returns the query selecting accounts from mysql.user with their global privileges, to be followed by
+// a WHERE clause.
+func (c *Client) userQuery() (*strings.Builder, error) {
+	sb := &strings.Builder{}
+	_, err := sb.WriteString(`SELECT User, Host,`)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.db.GetContext(ctx, &u, sb.String(), user, host)
+	err = c.userPrivsSelect(sb)
 	if err != nil {
 		return nil, err
 	}
-
-	return &u, nil
+	_, err = sb.WriteString(c.userTypeSelect() + ", " + c.accountSelect(false) + ` FROM mysql.user `)
+	if err != nil {
+		return nil, err
+	}
+	return sb, nil
 }
 
 func (c *Client) getUserGroupedByHostQuery() (*strings.Builder, error) {
 	sb := &strings.Builder{}
-	_, err := sb.WriteString(`SELECT User, GROUP_CONCAT(Host) as Host, 'user' AS user_type FROM mysql.user `)
+	_, err := sb.WriteString(`SELECT User, GROUP_CONCAT(Host) as Host, 'user' AS user_type, ` + c.accountSelect(true) + ` FROM mysql.user `)
 	return sb, err
 }
 
 func (c *Client) getUsersQuery() (*strings.Builder, error) {
 	sb := &strings.Builder{}
-	_, err := sb.WriteString(`SELECT Host, User, CASE WHEN authentication_string = '' THEN 'role' ELSE 'user' END AS user_type FROM mysql.user `)
+	_, err := sb.WriteString(`SELECT Host, User, ` + c.userTypeSelect() + `, ` + c.accountSelect(false) + ` FROM mysql.user `)
 	return sb, err
 }
 
+// GetCollapsedUser returns a user with its hosts combined, the way ListUsers returns users when collapsing them.
+func (c *Client) GetCollapsedUser(ctx context.Context, user string) (*User, error) {
+	sb, err := c.getUserGroupedByHostQuery()
+	if err != nil {
+		return nil, err
+	}
+	filter, err := c.userTypeFilter(UserType)
+	if err != nil {
+		return nil, err
+	}
+	_, err = sb.WriteString(filter + `AND User = ? GROUP BY User`)
+	if err != nil {
+		return nil, err
+	}
+
+	u := User{}
+	err = c.reader().GetContext(ctx, &u, sb.String(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
 // ListUsers queries the server and fetches all the users for the given  page.
 func (c *Client) ListUsers(ctx context.Context, userType string, pager *Pager, collapseUsers bool) ([]*User, string, error) {
 	l := ctxzap.Extract(ctx)
@@ -166,21 +316,13 @@ func (c *Client) ListUsers(ctx context.Context, userType string, pager *Pager, c
 		}
 	}
 
-	switch userType {
-	case UserType:
-		_, err = sb.WriteString(`WHERE authentication_string != '' `)
-		if err != nil {
-			return nil, "", err
-		}
-
-	case RoleType:
-		_, err = sb.WriteString(`WHERE authentication_string = '' `)
-		if err != nil {
-			return nil, "", err
-		}
-
-	default:
-		return nil, "", fmt.Errorf("unexpected user type %s", userType)
+	filter, err := c.userTypeFilter(userType)
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = sb.WriteString(filter)
+	if err != nil {
+		return nil, "", err
 	}
 
 	if collapseUsers {
@@ -203,7 +345,7 @@ func (c *Client) ListUsers(ctx context.Context, userType string, pager *Pager, c
 		args = append(args, offset)
 	}
 	var ret []*User
-	err = c.db.SelectContext(ctx, &ret, sb.String(), args...)
+	err = c.reader().SelectContext(ctx, &ret, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -220,7 +362,7 @@ func (c *Client) ListUsers(ctx context.Context, userType string, pager *Pager, c
 
 func (c *Client) GetHost(ctx context.Context) (string, error) {
 	var host string
-	err := c.db.QueryRowxContext(ctx, "SELECT @@hostname").Scan(&host)
+	err := c.pool().GetContext(ctx, &host, "SELECT @@hostname")
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch server info: %w", err)
 	}
@@ -243,8 +385,7 @@ func (c *Client) CreateUser(ctx context.Context, user string, password string) e
 	pwEsc := strings.ReplaceAll(password, "'", "''")
 	userStr := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
 	query := fmt.Sprintf("CREATE USER %s IDENTIFIED BY '%s'", userStr, pwEsc)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
 
 func (c *Client) DropUser(ctx context.Context, user string) error {
@@ -262,6 +403,5 @@ func (c *Client) DropUser(ctx context.Context, user string) error {
 	}
 	userStr := fmt.Sprintf("'%s'@'%s'", userEsc, hostEsc)
 	query := fmt.Sprintf("DROP USER %s", userStr)
-	_ = c.db.MustExec(query)
-	return nil
+	return c.execStatement(ctx, query)
 }
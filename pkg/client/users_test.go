@@ -0,0 +1,95 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_userTypeFilter(t *testing.T) {
+	mysql57 := &Client{capabilities: ServerCapabilities{Flavor: FlavorMySQL}}
+	filter, err := mysql57.userTypeFilter(RoleType)
+	require.NoError(t, err)
+	require.Equal(t, "WHERE FALSE ", filter)
+
+	// Every MySQL role term is part of the condition, so the rows below are classified as the query does.
+	mysql8 := &Client{capabilities: ServerCapabilities{Flavor: FlavorMySQL, Roles: true, DefaultRoles: true}}
+	filter, err = mysql8.userTypeFilter(UserType)
+	require.NoError(t, err)
+	require.Equal(t, "WHERE NOT ("+mysql8.roleCondition()+") ", filter)
+	for _, term := range mysqlRoleTerms(mysql8.Capabilities()) {
+		require.Contains(t, filter, term.sql)
+	}
+
+	mariaDB := &Client{capabilities: ServerCapabilities{Flavor: FlavorMariaDB, Roles: true}}
+	filter, err = mariaDB.userTypeFilter(RoleType)
+	require.NoError(t, err)
+	require.Equal(t, "WHERE is_role = 'Y' ", filter)
+
+	_, err = mariaDB.userTypeFilter("group")
+	require.Error(t, err)
+}
+
+func Test_mysqlRoleTerms(t *testing.T) {
+	tests := []struct {
+		name         string
+		row          roleRow
+		defaultRoles bool
+		wantRole     bool
+	}{
+		{
+			name:     "role made by CREATE ROLE and not granted yet",
+			row:      roleRow{locked: true, plugin: "caching_sha2_password"},
+			wantRole: true,
+		},
+		{
+			name:     "role made by CREATE ROLE on a server defaulting to native passwords",
+			row:      roleRow{locked: true, plugin: "mysql_native_password"},
+			wantRole: true,
+		},
+		{
+			name:     "granted role",
+			row:      roleRow{granted: true, plugin: "caching_sha2_password", authenticationString: "$A$005$hash"},
+			wantRole: true,
+		},
+		{
+			name:         "default role",
+			row:          roleRow{defaultRole: true, plugin: "caching_sha2_password"},
+			defaultRoles: true,
+			wantRole:     true,
+		},
+		{
+			name: "default role without mysql.default_roles",
+			row:  roleRow{defaultRole: true, plugin: "caching_sha2_password"},
+		},
+		{
+			name: "user with a password",
+			row:  roleRow{plugin: "caching_sha2_password", authenticationString: "$A$005$hash"},
+		},
+		{
+			name: "passwordless user",
+			row:  roleRow{plugin: "caching_sha2_password"},
+		},
+		{
+			name: "locked user with a password",
+			row:  roleRow{locked: true, plugin: "mysql_native_password", authenticationString: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19"},
+		},
+		{
+			name: "locked auth_socket user",
+			row:  roleRow{locked: true, plugin: "auth_socket"},
+		},
+		{
+			name: "locked LDAP user",
+			row:  roleRow{locked: true, plugin: "authentication_ldap_simple"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isRole := false
+			for _, term := range mysqlRoleTerms(ServerCapabilities{Flavor: FlavorMySQL, Roles: true, DefaultRoles: tt.defaultRoles}) {
+				isRole = isRole || term.match(tt.row)
+			}
+			require.Equal(t, tt.wantRole, isRole)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	configv1 "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const actionRevokeOrphanedGrants = "revoke_orphaned_grants"
+
+var actionSchemas = map[string]*v2.BatonActionSchema{
+	actionRevokeOrphanedGrants: {
+		Name:        actionRevokeOrphanedGrants,
+		DisplayName: "Revoke orphaned grants",
+		Description: "Lists the REVOKE statements that remove table, column and routine privileges on objects that " +
+			"no longer exist, and runs them when execute is set.",
+		Arguments: []*configv1.Field{
+			{
+				Name:        "execute",
+				DisplayName: "Execute",
+				Description: "Run the REVOKE statements instead of only returning them",
+				Field:       &configv1.Field_BoolField{BoolField: &configv1.BoolField{}},
+			},
+		},
+		ReturnTypes: []*configv1.Field{
+			{
+				Name:        "statements",
+				DisplayName: "Statements",
+				Description: "The REVOKE statements for the orphaned grants",
+				Field:       &configv1.Field_StringSliceField{StringSliceField: &configv1.StringSliceField{}},
+			},
+			{
+				Name:        "executed",
+				DisplayName: "Executed",
+				Description: "Whether the statements were run",
+				Field:       &configv1.Field_BoolField{BoolField: &configv1.BoolField{}},
+			},
+			{
+				Name:        "errors",
+				DisplayName: "Errors",
+				Description: "Orphaned grants that could not be revoked, and why",
+				Field:       &configv1.Field_StringSliceField{StringSliceField: &configv1.StringSliceField{}},
+			},
+		},
+	},
+}
+
+// actionResult is the outcome of an action, kept so its status can be looked up after it is invoked.
+type actionResult struct {
+	status   v2.BatonActionStatus
+	response *structpb.Struct
+}
+
+// actionManager runs the connector's custom actions. Actions run to completion when they are invoked.
+type actionManager struct {
+	client  *client.Client
+	mtx     sync.Mutex
+	results map[string]*actionResult
+}
+
+// RegisterActionManager returns the manager for the connector's custom actions.
+func (c *connectorImpl) RegisterActionManager(ctx context.Context) (connectorbuilder.CustomActionManager, error) {
+	return &actionManager{
+		client:  c.client,
+		results: make(map[string]*actionResult),
+	}, nil
+}
+
+func (m *actionManager) ListActionSchemas(ctx context.Context) ([]*v2.BatonActionSchema, annotations.Annotations, error) {
+	var ret []*v2.BatonActionSchema
+	for _, schema := range actionSchemas {
+		ret = append(ret, schema)
+	}
+	return ret, nil, nil
+}
+
+func (m *actionManager) GetActionSchema(ctx context.Context, name string) (*v2.BatonActionSchema, annotations.Annotations, error) {
+	schema, ok := actionSchemas[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown action %q", name)
+	}
+	return schema, nil, nil
+}
+
+func (m *actionManager) InvokeAction(
+	ctx context.Context,
+	name string,
+	args *structpb.Struct,
+) (string, v2.BatonActionStatus, *structpb.Struct, annotations.Annotations, error) {
+	var response *structpb.Struct
+	var err error
+	switch name {
+	case actionRevokeOrphanedGrants:
+		response, err = m.revokeOrphanedGrants(ctx, args.GetFields()["execute"].GetBoolValue())
+	default:
+		return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, fmt.Errorf("unknown action %q", name)
+	}
+	if err != nil {
+		return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+	}
+
+	id := fmt.Sprintf("%s:%d", name, time.Now().UnixNano())
+	status := v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE
+
+	m.mtx.Lock()
+	m.results[id] = &actionResult{status: status, response: response}
+	m.mtx.Unlock()
+
+	return id, status, response, nil, nil
+}
+
+func (m *actionManager) GetActionStatus(ctx context.Context, id string) (v2.BatonActionStatus, string, *structpb.Struct, annotations.Annotations, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	result, ok := m.results[id]
+	if !ok {
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN, "", nil, nil, fmt.Errorf("unknown action id %q", id)
+	}
+	return result.status, "", result.response, nil, nil
+}
+
+// revokeOrphanedGrants builds the REVOKE statements for every orphaned grant on the server, and runs them when
+// execute is set. Grants of protected accounts, and grants whose statement cannot be built or fails, are reported in
+// errors.
+func (m *actionManager) revokeOrphanedGrants(ctx context.Context, execute bool) (*structpb.Struct, error) {
+	l := ctxzap.Extract(ctx)
+
+	orphans, err := m.client.ListOrphanedGrants(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []interface{}{}
+	errs := []interface{}{}
+	for _, o := range orphans {
+		stmt, err := o.RevokeStatement()
+		if err == nil {
+			err = m.client.CheckStatement(stmt)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s for '%s'@'%s': %s", o.ID(), o.User, o.Host, err))
+			continue
+		}
+		statements = append(statements, stmt)
+
+		if !execute {
+			continue
+		}
+		l.Info("revoking orphaned grant", zap.String("statement", stmt))
+		_, err = m.client.ExecContext(ctx, stmt)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", stmt, err))
+		}
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"statements": statements,
+		"executed":   execute,
+		"errors":     errs,
+	})
+}
@@ -0,0 +1,127 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// accountActivity tracks the login activity of accounts for a sync, to set the last login of users and flag
+// accounts that have not logged in for longer than staleAfter.
+type accountActivity struct {
+	client     *client.Client
+	staleAfter time.Duration
+
+	mtx      sync.Mutex
+	loaded   bool
+	disabled bool
+	uptime   time.Duration
+	accounts map[string]*client.AccountActivity
+}
+
+func newAccountActivity(c *client.Client, staleAfter time.Duration) *accountActivity {
+	return &accountActivity{
+		client:     c,
+		staleAfter: staleAfter,
+	}
+}
+
+// reset discards the loaded activity so the next sync reads it again.
+func (a *accountActivity) reset() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.loaded = false
+	a.disabled = false
+	a.accounts = nil
+}
+
+// load must be called with the mutex held. When performance_schema cannot be read, activity is not reported
+// until the next reset.
+func (a *accountActivity) load(ctx context.Context) {
+	l := ctxzap.Extract(ctx)
+	a.loaded = true
+
+	accounts, err := a.client.ListAccountActivity(ctx)
+	if err != nil {
+		if client.IsAccessDenied(err) {
+			l.Warn("access to performance_schema.accounts was denied, skipping account activity", zap.Error(err))
+		} else {
+			l.Warn("unable to read account activity, skipping account activity", zap.Error(err))
+		}
+		a.disabled = true
+		return
+	}
+	a.accounts = accounts
+
+	a.uptime, err = a.client.GetUptime(ctx)
+	if err != nil {
+		l.Warn("unable to read server uptime", zap.Error(err))
+	}
+}
+
+// userActivity is the combined activity of the accounts of a user.
+type userActivity struct {
+	currentConnections int64
+	totalConnections   int64
+	failedLogins       int64
+	lastLogin          *time.Time
+	stale              bool
+}
+
+// forUser returns the activity of user on the given hosts, or nil when activity is not available.
+func (a *accountActivity) forUser(ctx context.Context, user string, hosts []string) *userActivity {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if !a.loaded {
+		a.load(ctx)
+	}
+	if a.disabled {
+		return nil
+	}
+
+	ret := &userActivity{}
+	for _, host := range hosts {
+		acct, ok := a.accounts[(&client.AccountActivity{User: user, Host: host}).ID()]
+		if !ok {
+			continue
+		}
+		ret.currentConnections += acct.CurrentConnections
+		ret.totalConnections += acct.TotalConnections
+		ret.failedLogins += acct.FailedLogins
+		if acct.LastConnection != nil && (ret.lastLogin == nil || acct.LastConnection.After(*ret.lastLogin)) {
+			ret.lastLogin = acct.LastConnection
+		}
+	}
+	ret.stale = isStale(ret, a.uptime, a.staleAfter, time.Now())
+
+	return ret
+}
+
+// isStale reports whether an account has not logged in for longer than staleAfter. performance_schema only
+// counts connections since the server started, so an account that connected since then is only stale when the
+// general log shows its last login after the start and longer than staleAfter ago. An account that has not
+// connected since the start is stale when the server or its last logged connection is older than staleAfter.
+func isStale(ua *userActivity, uptime time.Duration, staleAfter time.Duration, now time.Time) bool {
+	if staleAfter <= 0 || ua.currentConnections > 0 {
+		return false
+	}
+
+	if ua.totalConnections > 0 {
+		if ua.lastLogin == nil {
+			return false
+		}
+		idle := now.Sub(*ua.lastLogin)
+		return idle > staleAfter && idle <= uptime
+	}
+
+	if ua.lastLogin != nil && now.Sub(*ua.lastLogin) > staleAfter {
+		return true
+	}
+	return uptime > staleAfter
+}
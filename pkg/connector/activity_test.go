@@ -0,0 +1,41 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isStale(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name   string
+		ua     userActivity
+		uptime time.Duration
+		want   bool
+	}{
+		{"connected now", userActivity{currentConnections: 1, lastLogin: ago(400 * day)}, 400 * day, false},
+		{"never connected, long uptime", userActivity{}, 120 * day, true},
+		{"never connected, recent restart", userActivity{}, 10 * day, false},
+		{"logged in before a recent restart", userActivity{lastLogin: ago(200 * day)}, 10 * day, true},
+		{"recent logged connection", userActivity{totalConnections: 3, lastLogin: ago(5 * day)}, 120 * day, false},
+		{"old logged connection since start", userActivity{totalConnections: 3, lastLogin: ago(100 * day)}, 120 * day, true},
+		{"general log older than the connections", userActivity{totalConnections: 3, lastLogin: ago(200 * day)}, 120 * day, false},
+		{"connected without a log", userActivity{totalConnections: 3}, 400 * day, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ua := tt.ua
+			require.Equal(t, tt.want, isStale(&ua, tt.uptime, 90*day, now))
+		})
+	}
+
+	require.False(t, isStale(&userActivity{}, 400*day, 0, now))
+}
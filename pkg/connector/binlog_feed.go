@@ -0,0 +1,128 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	binlogFeedID = "mysql_binlog_privilege_changes"
+	// binlogSourceServer reads the binary log from the server instead of from files.
+	binlogSourceServer = "server"
+)
+
+// binlogReadFunc reads up to limit statements from a binary log starting at from.
+type binlogReadFunc func(ctx context.Context, from client.BinlogPosition, limit int) ([]*client.BinlogStatement, client.BinlogPosition, error)
+
+// binlogFeed turns the account management and privilege statements in the binary log into resource change
+// events for the users, roles and objects they affect, so changes made outside of Baton are picked up before
+// the next full sync. The cursor is the binary log position after the last statement read.
+type binlogFeed struct {
+	resources *eventResources
+	read      binlogReadFunc
+	start     client.BinlogPosition
+}
+
+// newBinlogFeed returns a feed reading the server's binary log, or the binary log files in a directory.
+func newBinlogFeed(c *client.Client, resources *eventResources, source string, start client.BinlogPosition) *binlogFeed {
+	read := c.ListBinlogStatements
+	if source != binlogSourceServer {
+		dir := &client.BinlogDir{Path: source}
+		read = func(_ context.Context, from client.BinlogPosition, limit int) ([]*client.BinlogStatement, client.BinlogPosition, error) {
+			return dir.ReadStatements(from, limit)
+		}
+	}
+
+	return &binlogFeed{
+		resources: resources,
+		read:      read,
+		start:     start,
+	}
+}
+
+func (f *binlogFeed) EventFeedMetadata(ctx context.Context) *v2.EventFeedMetadata {
+	return &v2.EventFeedMetadata{
+		Id:                  binlogFeedID,
+		SupportedEventTypes: []v2.EventType{v2.EventType_EVENT_TYPE_RESOURCE_CHANGE},
+	}
+}
+
+func (f *binlogFeed) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	from := f.start
+	if pToken.Cursor != "" {
+		var err error
+		from, err = client.ParseBinlogPosition(pToken.Cursor)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	limit := pToken.Size
+	if limit <= 0 {
+		limit = defaultEventPageSize
+	}
+
+	stmts, next, err := f.read(ctx, from, limit)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var events []*v2.Event
+	for _, stmt := range stmts {
+		if earliestEvent != nil && stmt.Timestamp.Before(earliestEvent.AsTime()) {
+			continue
+		}
+
+		parsed, ok := client.ParseAccountStatement(stmt.Statement, stmt.Database)
+		if !ok {
+			continue
+		}
+
+		changes, err := f.resources.changes(ctx, parsed)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		for i, change := range changes {
+			events = append(events, &v2.Event{
+				Id:         fmt.Sprintf("%s:%s:%d", binlogFeedID, stmt.Position, i),
+				OccurredAt: timestamppb.New(stmt.Timestamp),
+				Event: &v2.Event_ResourceChangeEvent{
+					ResourceChangeEvent: change,
+				},
+			})
+		}
+	}
+
+	return events, &pagination.StreamState{
+		Cursor:  next.String(),
+		HasMore: next != from,
+	}, nil, nil
+}
+
+// validateBinlogSource checks the binlog-source option, which is "server" or a directory of binary log files.
+func validateBinlogSource(source string) error {
+	if source == "" || source == binlogSourceServer {
+		return nil
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("unable to read binary logs: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("binlog-source must be %q or a directory of binary log files", binlogSourceServer)
+	}
+	return nil
+}
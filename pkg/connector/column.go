@@ -71,7 +71,13 @@ func (s *columnSyncer) Grant(ctx context.Context, principal *v2.Resource, entitl
 	}
 
 	parts := strings.Split(entitlement.Id, ":")
-	privilege := parts[1]
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid entitlement ID: %s", entitlement.Id)
+	}
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeColumn.Id, parts[1])
+	if err != nil {
+		return nil, err
+	}
 
 	columnParts := strings.Split(parts[3], ".")
 	tableName := fmt.Sprintf("%s.%s", columnParts[0], columnParts[1])
@@ -79,9 +85,9 @@ func (s *columnSyncer) Grant(ctx context.Context, principal *v2.Resource, entitl
 
 	user := strings.Split(principal.Id.Resource, ":")[1]
 
-	err := s.client.GrantColumnPrivilege(ctx, tableName, columnName, user, privilege)
+	err = s.client.GrantColumnPrivilege(ctx, tableName, columnName, user, privilege.keyword)
 	if err != nil {
-		return nil, fmt.Errorf("failed to grant %s on %s to %s: %w", privilege, entitlement.Id, principal.Id.Resource, err)
+		return nil, fmt.Errorf("failed to grant %s on %s to %s: %w", privilege.keyword, entitlement.Id, principal.Id.Resource, err)
 	}
 
 	return nil, nil
@@ -92,7 +98,10 @@ func (s *columnSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations
 	if len(parts) != 4 {
 		return nil, fmt.Errorf("invalid entitlement ID: %s", grant.Entitlement.Id)
 	}
-	privilege := parts[1]
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeColumn.Id, parts[1])
+	if err != nil {
+		return nil, err
+	}
 	columnID := parts[3]
 
 	idParts := strings.Split(columnID, ".")
@@ -108,9 +117,9 @@ func (s *columnSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations
 	}
 	user := userParts[1]
 
-	err := s.client.RevokeColumnPrivilege(ctx, table, column, user, privilege)
+	err = s.client.RevokeColumnPrivilege(ctx, table, column, user, privilege.keyword)
 	if err != nil {
-		return nil, fmt.Errorf("failed to revoke %s on %s.%s from %s: %w", privilege, table, column, user, err)
+		return nil, fmt.Errorf("failed to revoke %s on %s.%s from %s: %w", privilege.keyword, table, column, user, err)
 	}
 
 	return nil, nil
@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Column entitlements hold the privileges granted on some columns of a table whose columns aren't expanded into
+// resources, like SELECT (ssn) ON db.people. They are kept apart from the table's privilege entitlements, so a
+// column grant doesn't look like a grant on the whole table, and their grants list the columns.
+const columnsSuffix = "_columns"
+
+// columnPrivileges are the privileges MySQL grants on columns.
+var columnPrivileges = []string{"select", "insert", "update", "references"}
+
+// columnEntitlementID returns the column entitlement ID of a privilege, like select_columns for select.
+func columnEntitlementID(priv string) string {
+	return priv + columnsSuffix
+}
+
+// columnPrivilege returns the privilege of a column entitlement ID, or false when the ID isn't one.
+func columnPrivilege(entitlementPriv string) (string, bool) {
+	priv, ok := strings.CutSuffix(entitlementPriv, columnsSuffix)
+	if !ok {
+		return "", false
+	}
+	for _, p := range columnPrivileges {
+		if p == priv {
+			return priv, true
+		}
+	}
+	return "", false
+}
+
+// tableName returns the db.table name of a table resource.
+func tableName(resource *v2.Resource) string {
+	return strings.TrimPrefix(resource.Id.Resource, fmt.Sprintf("%s:", resource.Id.ResourceType))
+}
+
+// columnEntitlements returns the column entitlements of a table.
+func columnEntitlements(resource *v2.Resource, c *client.Client) []*v2.Entitlement {
+	grantable := []*v2.ResourceType{resourceTypeUser}
+	if c.Capabilities().Roles {
+		grantable = append(grantable, resourceTypeRole)
+	}
+
+	name := tableName(resource)
+	ret := make([]*v2.Entitlement, 0, len(columnPrivileges))
+	for _, priv := range columnPrivileges {
+		keyword := strings.ToUpper(priv)
+		ret = append(ret, &v2.Entitlement{
+			Resource:    resource,
+			Id:          fmt.Sprintf("entitlement:%s:%s", columnEntitlementID(priv), resource.Id.Resource),
+			DisplayName: fmt.Sprintf("%s (columns) %s", keyword, name),
+			Description: fmt.Sprintf("Enable use of %s on some columns of the %s table", keyword, name),
+			GrantableTo: grantable,
+			Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+			Slug:        fmt.Sprintf("%s columns", priv),
+		})
+	}
+	return ret
+}
+
+// columnsAnnotation returns an annotation listing the columns of a column grant.
+func columnsAnnotation(columns []string) (annotations.Annotations, error) {
+	sorted := append([]string{}, columns...)
+	sort.Strings(sorted)
+
+	values := make([]interface{}, 0, len(sorted))
+	for _, col := range sorted {
+		values = append(values, col)
+	}
+	st, err := structpb.NewStruct(map[string]interface{}{"columns": values})
+	if err != nil {
+		return nil, err
+	}
+	var annos annotations.Annotations
+	annos.Update(st)
+	return annos, nil
+}
@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func Test_columnPrivilege(t *testing.T) {
+	priv, ok := columnPrivilege("select_columns")
+	require.True(t, ok)
+	require.Equal(t, "select", priv)
+
+	_, ok = columnPrivilege("select")
+	require.False(t, ok)
+	_, ok = columnPrivilege("delete_columns")
+	require.False(t, ok)
+
+	// Every column privilege resolves to a table privilege on both flavors, so grants can be provisioned.
+	for _, flavor := range []client.Flavor{client.FlavorMySQL, client.FlavorMariaDB} {
+		for _, p := range columnPrivileges {
+			priv, ok := columnPrivilege(columnEntitlementID(p))
+			require.True(t, ok)
+			_, err := privilegeCatalogs[flavor].resolve(resourceTypeTable.Id, priv)
+			require.NoError(t, err, "%s on %s", priv, flavor)
+		}
+	}
+}
+
+func Test_columnsAnnotation(t *testing.T) {
+	annos, err := columnsAnnotation([]string{"ssn", "name"})
+	require.NoError(t, err)
+
+	st := &structpb.Struct{}
+	ok, err := annos.Pick(st)
+	require.NoError(t, err)
+	require.True(t, ok)
+	var columns []string
+	for _, v := range st.GetFields()["columns"].GetListValue().GetValues() {
+		columns = append(columns, v.GetStringValue())
+	}
+	require.Equal(t, []string{"name", "ssn"}, columns)
+}
@@ -2,15 +2,21 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/conductorone/baton-mysql/pkg/client"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func titleCase(s string) string {
@@ -21,21 +27,51 @@ func titleCase(s string) string {
 
 // connectorImpl implements the ConnectorServer interface for syncing with a MySQL server.
 type connectorImpl struct {
-	client        *client.Client
-	skipDbs       map[string]struct{}
-	expandCols    map[string]struct{}
-	collapseUsers bool
+	client           *client.Client
+	serverPrivileges *serverPrivileges
+	skipDbs          map[string]struct{}
+	expandCols       map[string]struct{}
+	collapseUsers    bool
+	usage            *statementUsage
+	activity         *accountActivity
+	eventFeeds       []connectorbuilder.EventFeed
+	snapshot         bool
+	dryRun           bool
+	readOnly         bool
+	binlogSource     string
+	grantSources     *grantSources
 }
 
-// Metadata returns metadata about the connector. This currently includes the hostname for the server.
+// Metadata returns metadata about the connector. This currently includes the hostname for the server, the time
+// the sync's snapshot started when syncing from a consistent snapshot, and whether provisioning is a dry run or
+// disabled.
 func (c *connectorImpl) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	sm, err := c.client.GetServerInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	fields := make(map[string]interface{})
+	if t, ok := c.client.SnapshotTime(); ok {
+		fields["snapshot_time"] = t.Format(time.RFC3339)
+	}
+	if c.dryRun {
+		fields["dry_run"] = true
+	}
+	if c.readOnly {
+		fields["read_only"] = true
+	}
+	var profile *structpb.Struct
+	if len(fields) > 0 {
+		profile, err = structpb.NewStruct(fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &v2.ConnectorMetadata{
 		DisplayName: sm.Name,
+		Profile:     profile,
 		Description: "MySQL Connector",
 		AccountCreationSchema: &v2.ConnectorAccountCreationSchema{
 			FieldMap: map[string]*v2.ConnectorAccountCreationSchema_Field{
@@ -54,12 +90,37 @@ func (c *connectorImpl) Metadata(ctx context.Context) (*v2.ConnectorMetadata, er
 	}, nil
 }
 
-// Validate the connection to the MySQL service.
+// Validate the connection to the MySQL service. A sync starts by validating the connection, so this is also where
+// the snapshot the sync reads from is started and the grant sources skipped by the previous sync are read again.
+// The grants of the connector's account are checked against the features it uses, and a read-only connector warns
+// when its account can write.
 func (c *connectorImpl) Validate(ctx context.Context) (annotations.Annotations, error) {
 	err := c.client.ValidateConnection(ctx)
 	if err != nil {
 		return nil, err
 	}
+	c.grantSources.reset(ctx)
+
+	grants, err := c.client.ListOwnGrants(ctx)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("unable to read the connector's own grants, skipping the privilege checks", zap.Error(err))
+	} else {
+		err = c.checkGrants(ctx, grants)
+		if err != nil {
+			return nil, err
+		}
+		if c.readOnly {
+			warnWritePrivileges(ctx, grants)
+		}
+	}
+
+	if c.snapshot {
+		startedAt, err := c.client.BeginSnapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to start a consistent snapshot: %w", err)
+		}
+		ctxzap.Extract(ctx).Info("syncing from a consistent snapshot", zap.Time("snapshot_time", startedAt))
+	}
 
 	return nil, nil
 }
@@ -72,30 +133,85 @@ func (c *connectorImpl) Asset(ctx context.Context, asset *v2.AssetRef) (string,
 
 func (c *connectorImpl) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
 	syncers := []connectorbuilder.ResourceSyncer{
-		newServerSyncer(c.client),
+		newServerSyncer(c.client, c.serverPrivileges),
 		newDatabaseSyncer(c.client, c.skipDbs),
 		newTableSyncer(c.client, c.expandCols),
 		newRoutineSyncer(c.client),
-		newUserSyncer(c.client, c.skipDbs, c.expandCols, c.collapseUsers),
+		newUserSyncer(c.client, c.serverPrivileges, c.skipDbs, c.expandCols, c.collapseUsers, c.usage, c.activity, c.grantSources),
 	}
 
-	if c.client.IsVersion8() {
-		syncers = append(syncers, newRoleSyncer(c.client, c.skipDbs, c.expandCols))
+	if c.client.Capabilities().Roles {
+		syncers = append(syncers, newRoleSyncer(c.client, c.serverPrivileges, c.skipDbs, c.expandCols, c.grantSources))
 	}
 
 	if len(c.expandCols) > 0 {
 		syncers = append(syncers, newColumnSyncer(c.client, c.expandCols))
 	}
 
+	if c.readOnly {
+		for i, s := range syncers {
+			syncers[i] = newReadOnlySyncer(s)
+		}
+	}
+
 	return syncers
 }
 
-// New returns a new MySQL connector.
-func New(ctx context.Context, dsn string, skipDbs []string, expandColumns []string, collapseUsers bool) (*connectorImpl, error) {
+// EventFeeds returns the configured event feeds of privilege changes and logins.
+func (c *connectorImpl) EventFeeds(ctx context.Context) []connectorbuilder.EventFeed {
+	return c.eventFeeds
+}
+
+// New returns a new MySQL connector. A read-only connector has no custom actions.
+func New(
+	ctx context.Context,
+	dsn string,
+	skipDbs []string,
+	expandColumns []string,
+	collapseUsers bool,
+	usageStats bool,
+	activity bool,
+	staleAfter time.Duration,
+	binlogSource string,
+	binlogStart string,
+	auditLogFile string,
+	snapshot bool,
+	dryRun bool,
+	protectedAccounts []string,
+	deniedPrivileges []string,
+	readOnly bool,
+	skipGrantSources []string,
+	retry client.RetryPolicy,
+) (connectorbuilder.ConnectorBuilder, error) {
+	err := validateBinlogSource(binlogSource)
+	if err != nil {
+		return nil, err
+	}
+	guards, err := client.NewSafeguards(protectedAccounts, deniedPrivileges)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := newGrantSources(skipGrantSources)
+	if err != nil {
+		return nil, err
+	}
+	binlogStartPos, err := client.ParseBinlogPosition(binlogStart)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAuditLogFile(auditLogFile)
+	if err != nil {
+		return nil, err
+	}
+
 	c, err := client.New(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
+	c.SetDryRun(dryRun)
+	c.SetRetryPolicy(retry)
+	c.SetSafeguards(guards)
 
 	dbs := make(map[string]struct{})
 	expandCols := make(map[string]struct{})
@@ -105,10 +221,76 @@ func New(ctx context.Context, dsn string, skipDbs []string, expandColumns []stri
 	for _, table := range expandColumns {
 		expandCols[table] = struct{}{}
 	}
-	return &connectorImpl{
-		client:        c,
-		skipDbs:       dbs,
-		expandCols:    expandCols,
-		collapseUsers: collapseUsers,
-	}, nil
+	var usage *statementUsage
+	if usageStats {
+		usage = newStatementUsage(c)
+	}
+
+	var accounts *accountActivity
+	if activity {
+		accounts = newAccountActivity(c, staleAfter)
+	}
+
+	resources := newEventResources(c, collapseUsers)
+	var eventFeeds []connectorbuilder.EventFeed
+	if binlogSource != "" {
+		eventFeeds = append(eventFeeds, newBinlogFeed(c, resources, binlogSource, binlogStartPos))
+	}
+	if auditLogFile != "" {
+		eventFeeds = append(eventFeeds, newAuditLogFeed(resources, auditLogFile))
+	}
+
+	ret := &connectorImpl{
+		client:           c,
+		serverPrivileges: newServerPrivileges(c),
+		skipDbs:          dbs,
+		expandCols:       expandCols,
+		collapseUsers:    collapseUsers,
+		usage:            usage,
+		activity:         accounts,
+		eventFeeds:       eventFeeds,
+		snapshot:         snapshot,
+		dryRun:           dryRun,
+		readOnly:         readOnly,
+		binlogSource:     binlogSource,
+		grantSources:     sources,
+	}
+	if readOnly {
+		return &readOnlyConnector{ret}, nil
+	}
+	return ret, nil
+}
+
+// endSync is called when a sync completes, and logs the optional grant sources it didn't read.
+func (c *connectorImpl) endSync(ctx context.Context) {
+	c.grantSources.endSync(ctx)
+}
+
+// syncEnder is a connector that is told when a sync completes.
+type syncEnder interface {
+	endSync(ctx context.Context)
+}
+
+// connectorServer tells the connector when a sync completes. The syncer calls Cleanup once a sync is done, but
+// the connector builder handles it without passing it on.
+type connectorServer struct {
+	types.ConnectorServer
+	connector syncEnder
+}
+
+func (s *connectorServer) Cleanup(ctx context.Context, request *v2.ConnectorServiceCleanupRequest) (*v2.ConnectorServiceCleanupResponse, error) {
+	s.connector.endSync(ctx)
+	return s.ConnectorServer.Cleanup(ctx, request)
+}
+
+// NewServer returns the connector server for a connector returned by New.
+func NewServer(ctx context.Context, cb connectorbuilder.ConnectorBuilder) (types.ConnectorServer, error) {
+	srv, err := connectorbuilder.NewConnector(ctx, cb)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := cb.(syncEnder); ok {
+		return &connectorServer{ConnectorServer: srv, connector: c}, nil
+	}
+	return srv, nil
 }
@@ -35,28 +35,57 @@ func (s *databaseSyncer) List(
 		return nil, "", nil, err
 	}
 
-	var annos annotations.Annotations
-	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeTable.Id})
-	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeRoutine.Id})
-
 	var ret []*v2.Resource
 	for _, dbModel := range databases {
 		if _, ok := s.skipDbs[dbModel.Name]; ok {
 			continue
 		}
-		ret = append(ret, &v2.Resource{
-			DisplayName: dbModel.Name,
-			Id: &v2.ResourceId{
-				ResourceType: s.resourceType.Id,
-				Resource:     dbModel.ID,
-			},
-			ParentResourceId: parentResourceID,
-			Annotations:      annos,
-		})
+		ret = append(ret, s.databaseResource(dbModel, parentResourceID))
 	}
 	return ret, nextPageToken, nil, nil
 }
 
+// Get returns a single database. Skipped databases are not found.
+func (s *databaseSyncer) Get(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceId *v2.ResourceId,
+) (*v2.Resource, annotations.Annotations, error) {
+	dbModel, err := s.client.GetDatabase(ctx, resourceId)
+	if isNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := s.skipDbs[dbModel.Name]; ok {
+		return nil, nil, nil
+	}
+
+	parentResourceId, err = serverParentID(ctx, s.client, parentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.databaseResource(dbModel, parentResourceId), nil, nil
+}
+
+func (s *databaseSyncer) databaseResource(dbModel *client.DbModel, parentResourceID *v2.ResourceId) *v2.Resource {
+	var annos annotations.Annotations
+	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeTable.Id})
+	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeRoutine.Id})
+
+	return &v2.Resource{
+		DisplayName: dbModel.Name,
+		Id: &v2.ResourceId{
+			ResourceType: s.resourceType.Id,
+			Resource:     dbModel.ID,
+		},
+		ParentResourceId: parentResourceID,
+		Annotations:      annos,
+	}
+}
+
 func (s *databaseSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	entitlements, err := getEntitlementsForResource(resource, s.client)
 	if err != nil {
@@ -80,12 +109,15 @@ func newDatabaseSyncer(c *client.Client, skipDbs map[string]struct{}) *databaseS
 
 func (s *databaseSyncer) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
 	userResource := principal.Id.Resource
-	privilege, database := extractDatabasePrivilegeAndDb(entitlement.Id)
+	privilege, database, err := extractDatabasePrivilegeAndDb(privilegesFor(s.client), entitlement.Id)
+	if err != nil {
+		return nil, err
+	}
 
 	user := strings.Split(userResource, ":")
 	userStr := user[1]
 
-	err := s.client.GrantDatabasePrivilege(ctx, database, userStr, privilege)
+	err = s.client.GrantDatabasePrivilege(ctx, database, userStr, privilege.keyword)
 	if err != nil {
 		return nil, fmt.Errorf("grant failed: %w", err)
 	}
@@ -95,12 +127,15 @@ func (s *databaseSyncer) Grant(ctx context.Context, principal *v2.Resource, enti
 
 func (s *databaseSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	userResource := grant.Principal.Id.Resource
-	privilege, database := extractDatabasePrivilegeAndDb(grant.Entitlement.Id)
+	privilege, database, err := extractDatabasePrivilegeAndDb(privilegesFor(s.client), grant.Entitlement.Id)
+	if err != nil {
+		return nil, err
+	}
 
 	user := strings.Split(userResource, ":")
 	userStr := user[1]
 
-	err := s.client.RevokeDatabasePrivilege(ctx, database, userStr, privilege)
+	err = s.client.RevokeDatabasePrivilege(ctx, database, userStr, privilege.keyword)
 	if err != nil {
 		return nil, fmt.Errorf("revoke failed: %w", err)
 	}
@@ -108,15 +143,16 @@ func (s *databaseSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotatio
 	return nil, nil
 }
 
-func extractDatabasePrivilegeAndDb(entitlementID string) (string, string) {
+func extractDatabasePrivilegeAndDb(catalog *privilegeCatalog, entitlementID string) (*sqlPrivilege, string, error) {
 	parts := strings.Split(entitlementID, ":")
 	if len(parts) < 4 {
-		return "", ""
+		return nil, "", fmt.Errorf("invalid entitlement ID: %s", entitlementID)
 	}
 
-	privilegePart := parts[1]
-	database := parts[3]
-	priv := strings.ToUpper(strings.ReplaceAll(privilegePart, "_", " "))
+	privilege, err := catalog.resolve(resourceTypeDatabase.Id, parts[1])
+	if err != nil {
+		return nil, "", err
+	}
 
-	return priv, database
+	return privilege, parts[3], nil
 }
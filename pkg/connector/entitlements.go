@@ -13,6 +13,7 @@ const (
 	proxyPriv                   = "proxy"
 	roleAssignmentPriv          = "role_assignment"
 	roleAssignmentWithGrantPriv = "role_assignment_with_grant"
+	withGrantSuffix             = "_with_grant"
 )
 
 type entitlementTemplate struct {
@@ -20,23 +21,99 @@ type entitlementTemplate struct {
 	includeWithGrant bool
 	resourceTypes    []*v2.ResourceType
 	entitlement      v2.Entitlement
-	v8Only           bool
+	requires         client.Capability
+	kind             privilegeKind
+	// sqlKeyword overrides the privilege keyword derived from the ID.
+	sqlKeyword string
+	// flavor limits the template to one server flavor.
+	flavor client.Flavor
+	// optional privileges are only declared on the server when SHOW PRIVILEGES reports them.
+	optional bool
+}
+
+// withGrantTemplate returns the grantable variant of a privilege entitlement template.
+func withGrantTemplate(et *entitlementTemplate) *entitlementTemplate {
+	grantEt := &entitlementTemplate{
+		requires:   et.requires,
+		kind:       et.kind,
+		sqlKeyword: et.sqlKeyword,
+		flavor:     et.flavor,
+		optional:   et.optional,
+		ID:         et.ID + withGrantSuffix,
+		entitlement: v2.Entitlement{
+			DisplayName: et.entitlement.DisplayName,
+			Description: et.entitlement.Description,
+			GrantableTo: et.entitlement.GrantableTo,
+			Annotations: et.entitlement.Annotations,
+			Purpose:     et.entitlement.Purpose,
+		},
+	}
+	grantEt.entitlement.DisplayName = "Grant " + grantEt.entitlement.DisplayName
+
+	return grantEt
+}
+
+// renamedEntitlements maps entitlement privilege IDs that were renamed to the IDs they were released under. The
+// old IDs are still declared and granted alongside the new ones, so grants and reviews that refer to them keep
+// working.
+var renamedEntitlements = map[string]string{
+	// NDB_STORED_USER was released as nbd_stored_user.
+	"ndb_stored_user": "nbd_stored_user",
+}
+
+// oldEntitlementID returns the ID a renamed entitlement privilege ID was released under, keeping its _with_grant
+// suffix.
+func oldEntitlementID(priv string) (string, bool) {
+	base := strings.TrimSuffix(priv, withGrantSuffix)
+	old, ok := renamedEntitlements[base]
+	if !ok {
+		return "", false
+	}
+	return old + strings.TrimPrefix(priv, base), true
+}
+
+// oldEntitlementTemplate returns a template declaring a renamed entitlement under the ID it was released under.
+func oldEntitlementTemplate(et *entitlementTemplate, id string) *entitlementTemplate {
+	return &entitlementTemplate{
+		requires:   et.requires,
+		kind:       et.kind,
+		sqlKeyword: et.sqlKeyword,
+		flavor:     et.flavor,
+		optional:   et.optional,
+		ID:         id,
+		entitlement: v2.Entitlement{
+			DisplayName: et.entitlement.DisplayName + " (deprecated)",
+			Description: fmt.Sprintf("%s. Deprecated, use %s", et.entitlement.Description, et.ID),
+			GrantableTo: et.entitlement.GrantableTo,
+			Annotations: et.entitlement.Annotations,
+			Purpose:     et.entitlement.Purpose,
+		},
+	}
 }
 
 func getEntitlementsForResource(resource *v2.Resource, c *client.Client) ([]*v2.Entitlement, error) {
-	tmpls, ok := entitlementsByResourceType[resource.Id.ResourceType]
-	if !ok || len(tmpls) == 0 {
+	return entitlementsFromTemplates(resource, c, entitlementTemplatesFor(c, resource.Id.ResourceType))
+}
+
+// entitlementTemplatesFor returns the entitlement templates of a resource type for the server's flavor.
+func entitlementTemplatesFor(c *client.Client, resourceTypeID string) []*entitlementTemplate {
+	return entitlementsByFlavor[c.Capabilities().Flavor][resourceTypeID]
+}
+
+func entitlementsFromTemplates(resource *v2.Resource, c *client.Client, tmpls []*entitlementTemplate) ([]*v2.Entitlement, error) {
+	if len(tmpls) == 0 {
 		return nil, nil
 	}
 
+	caps := c.Capabilities()
 	grantable := []*v2.ResourceType{resourceTypeUser}
-	if c.IsVersion8() {
+	if caps.Roles {
 		grantable = append(grantable, resourceTypeRole)
 	}
 
 	var ret []*v2.Entitlement
 	for _, t := range tmpls {
-		if !c.IsVersion8() && t.v8Only {
+		if !caps.Has(t.requires) {
 			continue
 		}
 		dName := getEntitlementDisplayName(t, resource)
@@ -141,14 +218,35 @@ func getEntitlementDescription(e *entitlementTemplate, resource *v2.Resource) st
 	return e.entitlement.Description
 }
 
-var entitlementsByResourceType map[string][]*entitlementTemplate
+// entitlementsByFlavor holds the entitlement templates of each resource type for each server flavor.
+var entitlementsByFlavor map[client.Flavor]map[string][]*entitlementTemplate
 
-//nolint:gochecknoinits // Init for creating all entitlement objects
-func init() {
-	entitlementsByResourceType = make(map[string][]*entitlementTemplate)
+// expandEntitlementTemplates lists the templates, including _with_grant variants, by resource type.
+func expandEntitlementTemplates(allEntitlements map[string]*entitlementTemplate) map[string][]*entitlementTemplate {
+	entitlementsByResourceType := make(map[string][]*entitlementTemplate)
 	for _, rt := range allResourceTypes {
 		entitlementsByResourceType[rt.Id] = []*entitlementTemplate{}
 	}
+
+	for ID, et := range allEntitlements {
+		for _, rt := range et.resourceTypes {
+			newEt := et
+			newEt.ID = ID
+
+			entitlementsByResourceType[rt.Id] = append(entitlementsByResourceType[rt.Id], newEt)
+
+			if et.includeWithGrant {
+				grantEt := withGrantTemplate(newEt)
+				entitlementsByResourceType[rt.Id] = append(entitlementsByResourceType[rt.Id], grantEt)
+			}
+		}
+	}
+
+	return entitlementsByResourceType
+}
+
+//nolint:gochecknoinits // Init for creating all entitlement objects
+func init() {
 	// This is a map of all the entitlements available in MySQL 8
 	// Source: https://dev.mysql.com/doc/refman/8.0/en/grant.html
 	allEntitlements := map[string]*entitlementTemplate{
@@ -180,7 +278,8 @@ func init() {
 			},
 		},
 		"create_role": {
-			v8Only:        true,
+			requires:      client.CapabilityRoles,
+			flavor:        client.FlavorMySQL,
 			resourceTypes: globalScope,
 			entitlement: v2.Entitlement{
 				DisplayName: "Create role",
@@ -253,7 +352,8 @@ func init() {
 			},
 		},
 		"drop_role": {
-			v8Only:        true,
+			requires:      client.CapabilityRoles,
+			flavor:        client.FlavorMySQL,
 			resourceTypes: globalScope,
 			entitlement: v2.Entitlement{
 				DisplayName: "Drop role",
@@ -289,8 +389,10 @@ func init() {
 				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
 			},
 		},
+		// MySQL keeps the grant option of column privileges on the table, so there is no column-level grant.
 		"grant": {
-			resourceTypes: append(globalDatabaseTableColumnScope, resourceTypeRoutine),
+			resourceTypes: append(globalDatabaseTableScope, resourceTypeRoutine),
+			sqlKeyword:    client.GrantOptionPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Grant",
 				Description: "Enable privileges to be granted to or removed from other accounts",
@@ -446,8 +548,9 @@ func init() {
 		},
 		"application_password_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Application password admin",
 				Description: "Enable dual password administration",
@@ -457,8 +560,9 @@ func init() {
 		},
 		"audit_abort_exempt": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Audit abort exempt",
 				Description: "Allow queries blocked by audit log filter",
@@ -468,8 +572,9 @@ func init() {
 		},
 		"audit_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Audit admin",
 				Description: "Enable audit log configuration",
@@ -479,8 +584,9 @@ func init() {
 		},
 		"authentication_policy_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Authentication policy admin",
 				Description: "Enable authentication policy administration.",
@@ -490,8 +596,9 @@ func init() {
 		},
 		"backup_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Backup admin",
 				Description: "Enable backup administration",
@@ -501,8 +608,9 @@ func init() {
 		},
 		"binlog_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Binlog admin",
 				Description: "Enable binary log control",
@@ -512,8 +620,9 @@ func init() {
 		},
 		"binlog_encryption_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Binlog encryption admin",
 				Description: "Enable activation and deactivation of binary log encryption",
@@ -523,8 +632,9 @@ func init() {
 		},
 		"clone_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Clone admin",
 				Description: "Enable clone administration",
@@ -534,8 +644,9 @@ func init() {
 		},
 		"connection_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Connection admin",
 				Description: "Enable connection limit/restriction control",
@@ -545,8 +656,9 @@ func init() {
 		},
 		"encryption_key_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Encryption key admin",
 				Description: "Enable InnoDB key rotation",
@@ -556,8 +668,9 @@ func init() {
 		},
 		"firewall_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Firewall admin",
 				Description: "Enable firewall rule administration, any user",
@@ -567,8 +680,9 @@ func init() {
 		},
 		"firewall_exempt": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Firewall exempt",
 				Description: "Exempt user from firewall restrictions",
@@ -578,8 +692,9 @@ func init() {
 		},
 		"firewall_user": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Firewall user",
 				Description: "Enable firewall rule administration, self",
@@ -589,8 +704,9 @@ func init() {
 		},
 		"flush_optimizer_costs": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Flush optimizer costs",
 				Description: "Enable optimizer cost reloading",
@@ -600,8 +716,9 @@ func init() {
 		},
 		"flush_status": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Flush status",
 				Description: "Enable status indicator flushing",
@@ -611,8 +728,9 @@ func init() {
 		},
 		"flush_tables": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Flush tables",
 				Description: "Enable table flushing",
@@ -622,8 +740,9 @@ func init() {
 		},
 		"flush_user_resources": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Flush user resources",
 				Description: "Enable user-resource flushing",
@@ -633,8 +752,9 @@ func init() {
 		},
 		"group_replication_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Group replication admin",
 				Description: "Enable Group Replication control",
@@ -644,8 +764,9 @@ func init() {
 		},
 		"group_replication_stream": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Group replication stream",
 				Description: "Allows a user account to be used for establishing Group Replication's group communication connections.",
@@ -655,8 +776,9 @@ func init() {
 		},
 		"innodb_redo_log_enable": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "InnoDB redo log enable",
 				Description: "Enable or disable redo logging",
@@ -666,8 +788,9 @@ func init() {
 		},
 		"innodb_redo_log_archive": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "InnoDB redo log archive",
 				Description: "Enable redo log archiving administration",
@@ -675,10 +798,11 @@ func init() {
 				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
 			},
 		},
-		"nbd_stored_user": {
+		"ndb_stored_user": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "NDB stored user",
 				Description: "Enable sharing of user or role between SQL nodes (NDB Cluster)",
@@ -688,8 +812,9 @@ func init() {
 		},
 		"passwordless_user_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Passwordless user admin",
 				Description: "Enable passwordless user account administration",
@@ -699,8 +824,9 @@ func init() {
 		},
 		"persist_ro_variables_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Persist RO variables admin",
 				Description: "Enable persisting read-only system variables",
@@ -710,8 +836,9 @@ func init() {
 		},
 		"replication_applier": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Replication applier",
 				Description: "Act as the PRIVILEGE_CHECKS_USER for a replication channel",
@@ -721,8 +848,9 @@ func init() {
 		},
 		"replication_slave_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Replication slave admin",
 				Description: "Enable regular replication control",
@@ -732,8 +860,9 @@ func init() {
 		},
 		"resource_group_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Resource group admin",
 				Description: "Enable resource group administration",
@@ -743,8 +872,9 @@ func init() {
 		},
 		"resource_group_user": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Resource group user",
 				Description: "Enable resource group administration",
@@ -753,9 +883,10 @@ func init() {
 			},
 		},
 		"role_admin": {
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			resourceTypes:    globalScope,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Role admin",
 				Description: "Enable roles to be granted or revoked, use of WITH ADMIN OPTION",
@@ -765,8 +896,9 @@ func init() {
 		},
 		"sensitive_variables_observer": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Sensitive variables observer",
 				Description: "Enables connections to the network interface that permits only administrative connections",
@@ -776,8 +908,9 @@ func init() {
 		},
 		"service_connection_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Service connection admin",
 				Description: "Enables connections to the network interface that permits only administrative connections",
@@ -787,8 +920,9 @@ func init() {
 		},
 		"session_variables_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Session variables admin",
 				Description: "Enable setting restricted session system variables",
@@ -798,8 +932,9 @@ func init() {
 		},
 		"set_user_id": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Set user ID",
 				Description: "Enable setting non-self DEFINER values",
@@ -809,8 +944,9 @@ func init() {
 		},
 		"show_routine": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Show routine",
 				Description: "Enable access to stored routine definitions",
@@ -820,8 +956,9 @@ func init() {
 		},
 		"skip_query_rewrite": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Skip query rewrite",
 				Description: "Do not rewrite queries executed by this user",
@@ -831,8 +968,9 @@ func init() {
 		},
 		"system_user": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "System user",
 				Description: "Designate account as system account",
@@ -842,8 +980,9 @@ func init() {
 		},
 		"system_variables_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "System variables admin",
 				Description: "Enable modifying or persisting global system variables",
@@ -853,8 +992,9 @@ func init() {
 		},
 		"table_encryption_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Table encryption admin",
 				Description: "Enable overriding default encryption settings",
@@ -864,8 +1004,9 @@ func init() {
 		},
 		"version_token_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "Version token admin",
 				Description: "Enable use of Version Tokens functions",
@@ -875,8 +1016,9 @@ func init() {
 		},
 		"xa_recover_admin": {
 			resourceTypes:    globalScope,
-			v8Only:           true,
+			requires:         client.CapabilityGlobalGrants,
 			includeWithGrant: true,
+			kind:             dynamicPrivilege,
 			entitlement: v2.Entitlement{
 				DisplayName: "XA recover admin",
 				Description: "Enable XA RECOVER execution",
@@ -885,7 +1027,8 @@ func init() {
 			},
 		},
 		"role_assignment": {
-			v8Only:           true,
+			requires:         client.CapabilityRoles,
+			kind:             accountPrivilege,
 			resourceTypes:    []*v2.ResourceType{resourceTypeUser, resourceTypeRole},
 			includeWithGrant: true,
 			entitlement: v2.Entitlement{
@@ -897,28 +1040,29 @@ func init() {
 		},
 	}
 
+	// MariaDB has no dynamic privileges. Its own server privileges, and the templates it models differently,
+	// replace the MySQL ones.
+	mysql := make(map[string]*entitlementTemplate)
+	mariaDB := make(map[string]*entitlementTemplate)
 	for ID, et := range allEntitlements {
-		for _, rt := range et.resourceTypes {
-			newEt := et
-			newEt.ID = ID
+		if et.flavor != client.FlavorMariaDB {
+			mysql[ID] = et
+		}
+		if et.flavor != client.FlavorMySQL && et.kind != dynamicPrivilege {
+			mariaDB[ID] = et
+		}
+	}
+	for ID, et := range mariaDBEntitlements() {
+		mariaDB[ID] = et
+	}
 
-			entitlementsByResourceType[rt.Id] = append(entitlementsByResourceType[rt.Id], newEt)
+	entitlementsByFlavor = map[client.Flavor]map[string][]*entitlementTemplate{
+		client.FlavorMySQL:   expandEntitlementTemplates(mysql),
+		client.FlavorMariaDB: expandEntitlementTemplates(mariaDB),
+	}
 
-			if et.includeWithGrant {
-				grantEt := &entitlementTemplate{
-					v8Only: et.v8Only,
-					ID:     newEt.ID + "_with_grant",
-					entitlement: v2.Entitlement{
-						DisplayName: newEt.entitlement.DisplayName,
-						Description: newEt.entitlement.Description,
-						GrantableTo: newEt.entitlement.GrantableTo,
-						Annotations: newEt.entitlement.Annotations,
-						Purpose:     newEt.entitlement.Purpose,
-					},
-				}
-				grantEt.entitlement.DisplayName = "Grant " + grantEt.entitlement.DisplayName
-				entitlementsByResourceType[rt.Id] = append(entitlementsByResourceType[rt.Id], grantEt)
-			}
-		}
+	privilegeCatalogs = make(map[client.Flavor]*privilegeCatalog)
+	for flavor, byResourceType := range entitlementsByFlavor {
+		privilegeCatalogs[flavor] = newPrivilegeCatalog(byResourceType)
 	}
 }
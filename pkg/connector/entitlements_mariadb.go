@@ -0,0 +1,102 @@
+package connector
+
+import (
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+// mariaDBEntitlements returns the MariaDB templates that are added to, or replace, the shared templates.
+// Source: https://mariadb.com/kb/en/grant/#global-privileges
+func mariaDBEntitlements() map[string]*entitlementTemplate {
+	serverPrivilege := func(keyword, displayName, description string) *entitlementTemplate {
+		return &entitlementTemplate{
+			resourceTypes: globalScope,
+			flavor:        client.FlavorMariaDB,
+			optional:      true,
+			sqlKeyword:    keyword,
+			entitlement: v2.Entitlement{
+				DisplayName: displayName,
+				Description: description,
+				Annotations: nil,
+				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+			},
+		}
+	}
+
+	return map[string]*entitlementTemplate{
+		"binlog_admin": serverPrivilege(
+			"BINLOG ADMIN",
+			"Binlog admin",
+			"Enable PURGE BINARY LOGS and setting binary log system variables",
+		),
+		"binlog_monitor": serverPrivilege(
+			"BINLOG MONITOR",
+			"Binlog monitor",
+			"Enable SHOW BINLOG STATUS and SHOW BINARY LOGS",
+		),
+		"binlog_replay": serverPrivilege(
+			"BINLOG REPLAY",
+			"Binlog replay",
+			"Enable replaying binary log events with BINLOG",
+		),
+		"connection_admin": serverPrivilege(
+			"CONNECTION ADMIN",
+			"Connection admin",
+			"Enable killing other connections and connecting past max_connections",
+		),
+		"delete_history": serverPrivilege(
+			"DELETE HISTORY",
+			"Delete history",
+			"Enable deleting historical rows from system-versioned tables",
+		),
+		"federated_admin": serverPrivilege(
+			"FEDERATED ADMIN",
+			"Federated admin",
+			"Enable use of CREATE SERVER, ALTER SERVER and DROP SERVER",
+		),
+		"read_only_admin": serverPrivilege(
+			"READ_ONLY ADMIN",
+			"Read only admin",
+			"Enable writes when read_only is set",
+		),
+		"replication_master_admin": serverPrivilege(
+			"REPLICATION MASTER ADMIN",
+			"Replication master admin",
+			"Enable SHOW REPLICA HOSTS and administering the primary",
+		),
+		"replication_slave_admin": serverPrivilege(
+			"REPLICATION SLAVE ADMIN",
+			"Replication slave admin",
+			"Enable START SLAVE, STOP SLAVE and CHANGE MASTER",
+		),
+		"set_user": serverPrivilege(
+			"SET USER",
+			"Set user",
+			"Enable setting the DEFINER of views, triggers and routines to other accounts",
+		),
+		"show_create_routine": serverPrivilege(
+			"SHOW CREATE ROUTINE",
+			"Show create routine",
+			"Enable SHOW CREATE for routines owned by other accounts",
+		),
+		"slave_monitor": serverPrivilege(
+			"SLAVE MONITOR",
+			"Slave monitor",
+			"Enable SHOW SLAVE STATUS and SHOW RELAYLOG EVENTS",
+		),
+		// MariaDB accounts cannot be granted like roles, so membership is only offered on roles.
+		"role_assignment": {
+			requires:         client.CapabilityRoles,
+			kind:             accountPrivilege,
+			flavor:           client.FlavorMariaDB,
+			resourceTypes:    []*v2.ResourceType{resourceTypeRole},
+			includeWithGrant: true,
+			entitlement: v2.Entitlement{
+				DisplayName: "Role Member",
+				Description: "Enables SET ROLE",
+				Annotations: nil,
+				Purpose:     v2.Entitlement_PURPOSE_VALUE_ASSIGNMENT,
+			},
+		},
+	}
+}
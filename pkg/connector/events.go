@@ -0,0 +1,136 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// defaultEventPageSize is the number of records an event feed reads per page when the request does not set a size.
+const defaultEventPageSize = 100
+
+// eventResources resolves the accounts and objects named in events to the resources the connector syncs.
+type eventResources struct {
+	client        *client.Client
+	collapseUsers bool
+
+	mtx              sync.Mutex
+	serverResourceID *v2.ResourceId
+}
+
+func newEventResources(c *client.Client, collapseUsers bool) *eventResources {
+	return &eventResources{
+		client:        c,
+		collapseUsers: collapseUsers,
+	}
+}
+
+// serverID returns the ID of the server resource, which is the parent of users, roles and databases.
+func (r *eventResources) serverID(ctx context.Context) (*v2.ResourceId, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.serverResourceID == nil {
+		server, err := r.client.GetServerInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.serverResourceID = &v2.ResourceId{ResourceType: resourceTypeServer.Id, Resource: server.ID}
+	}
+
+	return r.serverResourceID, nil
+}
+
+// changes returns a change event for each resource a statement affects: the accounts whose grants or
+// attributes changed, the roles granted or revoked and the object privileges were granted on.
+func (r *eventResources) changes(ctx context.Context, stmt *client.AccountStatement) ([]*v2.ResourceChangeEvent, error) {
+	serverID, err := r.serverID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*v2.ResourceChangeEvent
+	seen := make(map[string]struct{})
+	add := func(id *v2.ResourceId, parent *v2.ResourceId) {
+		key := id.ResourceType + "/" + id.Resource
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		ret = append(ret, &v2.ResourceChangeEvent{ResourceId: id, ParentResourceId: parent})
+	}
+
+	for _, a := range stmt.Accounts {
+		add(r.accountResourceID(ctx, a), serverID)
+	}
+
+	roleHost := "%"
+	if r.client.Capabilities().Flavor == client.FlavorMariaDB {
+		roleHost = ""
+	}
+	for _, r := range stmt.Roles {
+		if r.Host == "" {
+			r.Host = roleHost
+		}
+		add(&v2.ResourceId{ResourceType: resourceTypeRole.Id, Resource: fmt.Sprintf("%s:%s@%s", client.RoleType, r.User, r.Host)}, serverID)
+	}
+
+	switch {
+	case stmt.Privileges == nil || stmt.Database == "":
+	case stmt.Database == "*":
+		add(serverID, nil)
+	case stmt.Object == "*":
+		add(&v2.ResourceId{ResourceType: resourceTypeDatabase.Id, Resource: fmt.Sprintf("%s:%s", client.DatabaseType, stmt.Database)}, serverID)
+	default:
+		databaseID := &v2.ResourceId{ResourceType: resourceTypeDatabase.Id, Resource: fmt.Sprintf("%s:%s", client.DatabaseType, stmt.Database)}
+		objectID := &v2.ResourceId{ResourceType: resourceTypeTable.Id, Resource: fmt.Sprintf("%s:%s.%s", client.TableType, stmt.Database, stmt.Object)}
+		if stmt.ObjectType == "FUNCTION" || stmt.ObjectType == "PROCEDURE" {
+			objectID = &v2.ResourceId{ResourceType: resourceTypeRoutine.Id, Resource: fmt.Sprintf("%s:%s.%s", client.RoutineType, stmt.Database, stmt.Object)}
+		}
+		add(objectID, databaseID)
+	}
+
+	return ret, nil
+}
+
+// accountResourceID returns the user or role resource of an account. An account without a host is '%', or a
+// MariaDB role. An account that no longer exists, like one that was dropped, is reported as a user.
+func (r *eventResources) accountResourceID(ctx context.Context, a client.AccountName) *v2.ResourceId {
+	hosts := []string{a.Host}
+	if a.Host == "" {
+		hosts = []string{"%"}
+		if r.client.Capabilities().Flavor == client.FlavorMariaDB {
+			hosts = []string{"", "%"}
+		}
+	}
+
+	var u *client.User
+	for _, host := range hosts {
+		var err error
+		u, err = r.client.GetUser(ctx, a.User, host)
+		if err == nil {
+			break
+		}
+		ctxzap.Extract(ctx).Debug("unable to look up account", zap.Error(err), zap.String("user", a.User), zap.String("host", host))
+	}
+	if u == nil {
+		u = &client.User{UserType: client.UserType, User: a.User, Host: hosts[len(hosts)-1]}
+	}
+
+	if u.UserType == client.UserType && r.collapseUsers {
+		if collapsed, err := r.client.GetCollapsedUser(ctx, a.User); err == nil {
+			u.Host = collapsed.Host
+		}
+	}
+
+	rt := resourceTypeUser
+	if u.UserType == client.RoleType {
+		rt = resourceTypeRole
+	}
+	return &v2.ResourceId{ResourceType: rt.Id, Resource: u.GetID()}
+}
@@ -0,0 +1,71 @@
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// securityFindings holds the audit data of the server, read once per sync so listing users doesn't query the
+// server for each one.
+type securityFindings struct {
+	client *client.Client
+
+	mtx    sync.Mutex
+	loaded bool
+	data   *client.AuditData
+}
+
+func newSecurityFindings(c *client.Client) *securityFindings {
+	return &securityFindings{
+		client: c,
+	}
+}
+
+// reset discards the loaded audit data so the next sync reads it again.
+func (f *securityFindings) reset() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.loaded = false
+	f.data = nil
+}
+
+// forUser returns the finding types of a user's hosts. When the audit data can't be read, a warning is logged and
+// users have no findings until the next reset.
+func (f *securityFindings) forUser(ctx context.Context, user string, hosts []string) []interface{} {
+	f.mtx.Lock()
+	if !f.loaded {
+		f.loaded = true
+		data, err := f.client.LoadAuditData(ctx)
+		if err != nil {
+			ctxzap.Extract(ctx).Warn("unable to read audit data, skipping security findings", zap.Error(err))
+		}
+		f.data = data
+	}
+	data := f.data
+	f.mtx.Unlock()
+
+	return findingTypes(ctx, data, user, hosts)
+}
+
+// findingTypes returns the distinct finding types of a user's hosts, or nil without audit data.
+func findingTypes(ctx context.Context, data *client.AuditData, user string, hosts []string) []interface{} {
+	if data == nil {
+		return nil
+	}
+
+	var findings []*client.Finding
+	for _, host := range hosts {
+		findings = append(findings, data.AuditUser(ctx, user, host)...)
+	}
+
+	var ret []interface{}
+	for _, t := range client.FindingTypes(findings) {
+		ret = append(ret, t)
+	}
+	return ret
+}
@@ -0,0 +1,188 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Grant sources are the grant tables the grants of users and roles are read from. A sync fails when it can't
+// read a required source, and continues without an optional one.
+const (
+	grantSourceUser     = "user"
+	grantSourceDatabase = "database"
+	grantSourceTable    = "table"
+	grantSourceDynamic  = "dynamic"
+	grantSourceColumn   = "column"
+	grantSourceRoutine  = "routine"
+	grantSourceProxy    = "proxy"
+	grantSourceRole     = "role"
+)
+
+// requiredGrantSources are the grant sources every sync reads, and the tables they are read from.
+var requiredGrantSources = map[string]string{
+	grantSourceUser:     "mysql.user",
+	grantSourceDatabase: "mysql.db",
+	grantSourceTable:    "mysql.tables_priv",
+}
+
+// optionalGrantSources are the grant sources that can be turned off, or skipped when they can't be read, and the
+// tables they are read from.
+var optionalGrantSources = map[string]string{
+	grantSourceDynamic: "mysql.global_grants or mysql.global_priv",
+	grantSourceColumn:  "mysql.columns_priv",
+	grantSourceRoutine: "mysql.procs_priv",
+	grantSourceProxy:   "mysql.proxies_priv",
+	grantSourceRole:    "mysql.role_edges or mysql.roles_mapping",
+}
+
+// grantSources tracks the optional grant sources a sync doesn't read: those turned off, and those the server
+// denied access to during the current sync.
+type grantSources struct {
+	disabled map[string]struct{}
+
+	mtx     sync.Mutex
+	skipped map[string]string
+}
+
+func newGrantSources(skip []string) (*grantSources, error) {
+	g := &grantSources{
+		disabled: make(map[string]struct{}),
+		skipped:  make(map[string]string),
+	}
+	for _, s := range skip {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if table, ok := requiredGrantSources[s]; ok {
+			return nil, fmt.Errorf("%s is a required grant source and can't be skipped: the sync reads it from %s", s, table)
+		}
+		if _, ok := optionalGrantSources[s]; !ok {
+			return nil, fmt.Errorf("%s is not an optional grant source: must be one of %s", s, strings.Join(sortedKeys(optionalGrantSources), ", "))
+		}
+		g.disabled[s] = struct{}{}
+	}
+	return g, nil
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// enabled reports whether the source is read. Required sources always are.
+func (g *grantSources) enabled(source string) bool {
+	if _, ok := g.disabled[source]; ok {
+		return false
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	_, ok := g.skipped[source]
+	return !ok
+}
+
+// skip reports whether an error reading a source can be ignored: the source is optional and the server denied
+// access to it. The source is then skipped for the rest of the sync, and a warning is logged the first time.
+func (g *grantSources) skip(ctx context.Context, source string, err error) bool {
+	table, ok := optionalGrantSources[source]
+	if !ok || !client.IsAccessDenied(err) {
+		return false
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if _, ok := g.skipped[source]; ok {
+		return true
+	}
+	g.skipped[source] = err.Error()
+
+	ctxzap.Extract(ctx).Warn(
+		"access to an optional grant source was denied, skipping it for the rest of the sync",
+		zap.String("source", source),
+		zap.String("table", table),
+		zap.Error(err),
+		zap.Strings("skipped_sources", sortedKeys(g.skipped)),
+	)
+	return true
+}
+
+// summary returns the sources the current sync didn't read and why, or nil when every source was read.
+func (g *grantSources) summary() map[string]string {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if len(g.disabled) == 0 && len(g.skipped) == 0 {
+		return nil
+	}
+	ret := make(map[string]string, len(g.disabled)+len(g.skipped))
+	for s := range g.disabled {
+		ret[s] = "turned off"
+	}
+	for s, reason := range g.skipped {
+		ret[s] = reason
+	}
+	return ret
+}
+
+// reset forgets the sources skipped by the previous sync, and logs the sources that are turned off.
+func (g *grantSources) reset(ctx context.Context) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	g.skipped = make(map[string]string)
+
+	if len(g.disabled) == 0 {
+		return
+	}
+	disabled := make([]string, 0, len(g.disabled))
+	for s := range g.disabled {
+		disabled = append(disabled, s)
+	}
+	sort.Strings(disabled)
+	ctxzap.Extract(ctx).Info("skipping optional grant sources", zap.Strings("sources", disabled))
+}
+
+// endSync logs the sources the sync that just completed didn't read and why, when access to any was denied.
+func (g *grantSources) endSync(ctx context.Context) {
+	summary := g.summary()
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if len(g.skipped) > 0 {
+		ctxzap.Extract(ctx).Warn("the sync skipped optional grant sources", zap.Any("skipped_sources", summary))
+	}
+}
+
+// annotations returns an annotation listing the sources that weren't read and why, or nil when every source was.
+func (g *grantSources) annotations() (annotations.Annotations, error) {
+	summary := g.summary()
+	if summary == nil {
+		return nil, nil
+	}
+
+	skipped := make(map[string]interface{}, len(summary))
+	for s, reason := range summary {
+		skipped[s] = reason
+	}
+	st, err := structpb.NewStruct(map[string]interface{}{"skipped_grant_sources": skipped})
+	if err != nil {
+		return nil, err
+	}
+	var annos annotations.Annotations
+	annos.Update(st)
+	return annos, nil
+}
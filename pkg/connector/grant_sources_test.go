@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func Test_newGrantSources(t *testing.T) {
+	g, err := newGrantSources([]string{" Proxy", "role", ""})
+	require.NoError(t, err)
+	require.False(t, g.enabled(grantSourceProxy))
+	require.False(t, g.enabled(grantSourceRole))
+	require.True(t, g.enabled(grantSourceColumn))
+	require.True(t, g.enabled(grantSourceTable))
+
+	_, err = newGrantSources([]string{"table"})
+	require.ErrorContains(t, err, "required grant source")
+	_, err = newGrantSources([]string{"proxies"})
+	require.ErrorContains(t, err, "not an optional grant source")
+}
+
+func Test_grantSources_skip(t *testing.T) {
+	ctx := context.Background()
+	denied := &mysql.MySQLError{Number: 1142, Message: "SELECT command denied to user 'baton'@'%' for table 'role_edges'"}
+
+	g, err := newGrantSources(nil)
+	require.NoError(t, err)
+
+	require.Nil(t, g.summary())
+
+	require.False(t, g.skip(ctx, grantSourceTable, denied))
+	require.False(t, g.skip(ctx, grantSourceRole, errors.New("connection reset")))
+	require.True(t, g.enabled(grantSourceRole))
+
+	require.True(t, g.skip(ctx, grantSourceRole, denied))
+	require.False(t, g.enabled(grantSourceRole))
+	require.True(t, g.skip(ctx, grantSourceRole, denied))
+
+	require.Equal(t, map[string]string{grantSourceRole: denied.Error()}, g.summary())
+
+	// The grant pages read while a source is skipped say so.
+	annos, err := g.annotations()
+	require.NoError(t, err)
+	st := &structpb.Struct{}
+	ok, err := annos.Pick(st)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, denied.Error(), st.Fields["skipped_grant_sources"].GetStructValue().Fields[grantSourceRole].GetStringValue())
+
+	g.endSync(ctx)
+	g.reset(ctx)
+	require.True(t, g.enabled(grantSourceRole))
+	require.Nil(t, g.summary())
+	annos, err = g.annotations()
+	require.NoError(t, err)
+	require.Nil(t, annos)
+
+	off, err := newGrantSources([]string{grantSourceProxy})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{grantSourceProxy: "turned off"}, off.summary())
+}
+
+// endSyncRecorder counts the syncs a connector is told have completed, and the Cleanup calls that reach the SDK.
+type endSyncRecorder struct {
+	types.ConnectorServer
+	ended   int
+	cleanup int
+}
+
+func (r *endSyncRecorder) endSync(context.Context) {
+	r.ended++
+}
+
+func (r *endSyncRecorder) Cleanup(context.Context, *v2.ConnectorServiceCleanupRequest) (*v2.ConnectorServiceCleanupResponse, error) {
+	r.cleanup++
+	return &v2.ConnectorServiceCleanupResponse{}, nil
+}
+
+func Test_connectorServer_Cleanup(t *testing.T) {
+	r := &endSyncRecorder{}
+	s := &connectorServer{ConnectorServer: r, connector: r}
+	_, err := s.Cleanup(context.Background(), &v2.ConnectorServiceCleanupRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, r.ended)
+	require.Equal(t, 1, r.cleanup)
+}
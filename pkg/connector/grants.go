@@ -3,30 +3,24 @@ package connector
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/conductorone/baton-mysql/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
 )
 
-func grantsForUserOrRole(
-	ctx context.Context,
-	c *client.Client,
-	resource *v2.Resource,
-	skipDbs map[string]struct{},
-	expandCols map[string]struct{},
-	collapseUsers bool,
-) ([]*v2.Grant, error) {
-	var ret []*v2.Grant
-	grantMap := make(map[string]struct{})
-
+// principalHosts splits a user or role resource ID into the user name and its hosts.
+func principalHosts(resource *v2.Resource, collapseUsers bool) (string, []string, error) {
 	parts := strings.Split(strings.TrimPrefix(resource.Id.Resource, fmt.Sprintf("%s:", resource.Id.ResourceType)), "@")
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("malformed principal ID")
+		return "", nil, fmt.Errorf("malformed principal ID")
 	}
-	user := parts[0]
 
 	hosts := []string{parts[1]}
 	// If we are collapsing users, we will want to split the host portion of the ID to inspect each real user's grants
@@ -34,50 +28,160 @@ func grantsForUserOrRole(
 		hosts = strings.Split(parts[1], ",")
 	}
 
-	var err error
-	for _, host := range hosts {
-		err = listGlobalGrants(ctx, resource.ParentResourceId, user, host, grantMap, c)
-		if err != nil {
-			return nil, err
-		}
+	return parts[0], hosts, nil
+}
 
-		err = listDatabaseGrants(ctx, user, host, grantMap, skipDbs, c)
-		if err != nil {
-			return nil, err
-		}
+// Grant phases, in the order a principal's grants are listed. A page holds the grants of one phase for one host
+// of the principal, and a phase with more grant rows than fit in a page is split across pages, so principals with
+// thousands of column grants do not have to fit in a single response. Global privileges are bounded by the
+// privileges the server knows, so the global phase is never split.
+const (
+	grantPhaseGlobal   = "global"
+	grantPhaseDatabase = "database"
+	grantPhaseTable    = "table"
+	grantPhaseColumn   = "column"
+	grantPhaseRoutine  = "routine"
+	grantPhaseProxy    = "proxy"
+	grantPhaseRole     = "role"
+)
 
-		err = listTableGrants(ctx, user, host, grantMap, skipDbs, c)
-		if err != nil {
-			return nil, err
+var grantPhases = []string{
+	grantPhaseGlobal,
+	grantPhaseDatabase,
+	grantPhaseTable,
+	grantPhaseColumn,
+	grantPhaseRoutine,
+	grantPhaseProxy,
+	grantPhaseRole,
+}
+
+// maxGrantPageSize is the number of grant rows read per page when the request does not set a smaller size.
+const maxGrantPageSize = 500
+
+// nextGrantPhase returns the phase and host listed after the given ones, moving on to the next host of a collapsed
+// user after the last phase. It returns false after the last phase of the last host.
+func nextGrantPhase(hosts []string, phase string, host string) (string, string, bool) {
+	for i, p := range grantPhases {
+		if p == phase && i+1 < len(grantPhases) {
+			return grantPhases[i+1], host, true
+		}
+	}
+	for i, h := range hosts {
+		if h == host && i+1 < len(hosts) {
+			return grantPhases[0], hosts[i+1], true
 		}
+	}
+	return "", "", false
+}
+
+// grantsForUserOrRole returns a page of the grants of a user or role. The page token holds the phase, the host
+// and the key of the last grant row read in the phase. The same grant can be listed by more than one phase or
+// host, like a table privilege that is also granted on a column, and is merged by its ID when the sync is stored.
+func grantsForUserOrRole(
+	ctx context.Context,
+	c *client.Client,
+	serverPrivs *serverPrivileges,
+	resource *v2.Resource,
+	skipDbs map[string]struct{},
+	expandCols map[string]struct{},
+	collapseUsers bool,
+	sources *grantSources,
+	orphans *orphanedGrants,
+	pToken *pagination.Token,
+) ([]*v2.Grant, string, error) {
+	user, hosts, err := principalHosts(resource, collapseUsers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bag := &pagination.Bag{}
+	err = bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", err
+	}
+	if bag.Current() == nil {
+		bag.Push(pagination.PageState{ResourceTypeID: grantPhases[0], ResourceID: hosts[0]})
+	}
 
-		err = listColumnGrants(ctx, user, host, grantMap, skipDbs, expandCols, c)
+	pageSize := pToken.Size
+	if pageSize <= 0 || pageSize > maxGrantPageSize {
+		pageSize = maxGrantPageSize
+	}
+
+	var page []string
+	var columns map[string][]string
+	// Phases without grants are skipped instead of returning empty pages.
+	for len(page) == 0 && bag.Current() != nil {
+		phase, host := bag.ResourceTypeID(), bag.ResourceID()
+		grantPage := &client.GrantPage{After: bag.PageToken(), Size: pageSize}
+
+		grantMap := make(map[string]struct{})
+		columns = make(map[string][]string)
+		next, err := listPhaseGrants(
+			ctx,
+			phase,
+			resource.ParentResourceId,
+			user,
+			host,
+			grantPage,
+			grantMap,
+			columns,
+			serverPrivs,
+			skipDbs,
+			expandCols,
+			sources,
+			orphans,
+			c,
+		)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
-		err = listProxyGrants(ctx, user, host, grantMap, c)
-		if err != nil {
-			return nil, err
+		page = make([]string, 0, len(grantMap))
+		for id := range grantMap {
+			page = append(page, id)
 		}
+		sort.Strings(page)
 
-		if c.IsVersion8() {
-			err = listRoleGrants(ctx, user, host, grantMap, c)
+		if next != "" {
+			err = bag.Next(next)
 			if err != nil {
-				return nil, err
+				return nil, "", err
 			}
+			continue
 		}
+		bag.Pop()
+		if nextPhase, nextHost, ok := nextGrantPhase(hosts, phase, host); ok {
+			bag.Push(pagination.PageState{ResourceTypeID: nextPhase, ResourceID: nextHost})
+		}
+	}
+	if bag.Current() == nil {
+		orphans.done(user, hosts)
+	}
+
+	nextPageToken, err := bag.Marshal()
+	if err != nil {
+		return nil, "", err
 	}
 
-	for privResource := range grantMap {
+	var ret []*v2.Grant
+	for _, privResource := range page {
 		privParts := strings.SplitN(privResource, ":", 2)
 		if len(privParts) != 2 {
-			return nil, fmt.Errorf("malformed priv resource id")
+			return nil, "", fmt.Errorf("malformed priv resource id")
 		}
 
 		resourceParts := strings.SplitN(privParts[1], ":", 2)
 		if len(resourceParts) != 2 {
-			return nil, fmt.Errorf("malformed resource ID")
+			return nil, "", fmt.Errorf("malformed resource ID")
+		}
+
+		var annos annotations.Annotations
+		if cols, ok := columns[privResource]; ok {
+			annos, err = columnsAnnotation(cols)
+			if err != nil {
+				return nil, "", err
+			}
 		}
 
 		entitlementID := fmt.Sprintf("entitlement:%s", privResource)
@@ -95,158 +199,339 @@ func grantsForUserOrRole(
 				Id: resource.Id,
 			},
 			Id:          fmt.Sprintf("grant:%s:%s", entitlementID, resource.Id.Resource),
-			Annotations: nil,
+			Annotations: annos,
 		})
 	}
 
-	return ret, nil
+	return ret, nextPageToken, nil
 }
 
-// listGlobalgrants returns a map keyed by entitlement ID for granted global privileges.
-func listGlobalGrants(
+// listPhaseGrants adds a page of the grants of one phase for user@host to grantMap, keyed by entitlement ID, and
+// the columns of column entitlement grants to columns. It returns the key to read the phase's next page after,
+// which is empty on its last page. Phases of optional grant sources that are turned off or denied are skipped.
+func listPhaseGrants(
 	ctx context.Context,
-	resourceID *v2.ResourceId,
+	phase string,
+	serverID *v2.ResourceId,
 	user, host string,
+	page *client.GrantPage,
 	grantMap map[string]struct{},
+	columns map[string][]string,
+	serverPrivs *serverPrivileges,
+	skipDbs map[string]struct{},
+	expandCols map[string]struct{},
+	sources *grantSources,
+	orphans *orphanedGrants,
 	c *client.Client,
-) error {
-	u, err := c.GetUser(ctx, user, host)
+) (string, error) {
+	var source string
+	var next string
+	var err error
+	switch phase {
+	case grantPhaseGlobal:
+		return "", listGlobalGrants(ctx, serverID, user, host, grantMap, serverPrivs, sources, c)
+	case grantPhaseDatabase:
+		return listDatabaseGrants(ctx, user, host, page, grantMap, skipDbs, c)
+	case grantPhaseTable:
+		return listTableGrants(ctx, user, host, page, grantMap, skipDbs, orphans, c)
+	case grantPhaseColumn:
+		source = grantSourceColumn
+		if sources.enabled(source) {
+			next, err = listColumnGrants(ctx, user, host, page, grantMap, columns, skipDbs, expandCols, orphans, c)
+		}
+	case grantPhaseRoutine:
+		source = grantSourceRoutine
+		if sources.enabled(source) {
+			next, err = listRoutineGrants(ctx, user, host, page, grantMap, skipDbs, orphans, c)
+		}
+	case grantPhaseProxy:
+		source = grantSourceProxy
+		if sources.enabled(source) {
+			next, err = listProxyGrants(ctx, user, host, page, grantMap, c)
+		}
+	case grantPhaseRole:
+		source = grantSourceRole
+		if c.Capabilities().Roles && sources.enabled(source) {
+			next, err = listRoleGrants(ctx, user, host, page, grantMap, c)
+		}
+	default:
+		return "", fmt.Errorf("invalid grant page token: unknown phase %q", phase)
+	}
+
+	if err != nil && sources.skip(ctx, source, err) {
+		return "", nil
+	}
+	return next, err
+}
+
+// orphanedGrants remembers the orphaned grants of the principals whose grants are being listed, so they are read
+// once per principal instead of once for each phase and page.
+type orphanedGrants struct {
+	client *client.Client
+
+	mtx sync.Mutex
+	ids map[string]map[string]struct{}
+}
+
+func newOrphanedGrants(c *client.Client) *orphanedGrants {
+	return &orphanedGrants{
+		client: c,
+		ids:    make(map[string]map[string]struct{}),
+	}
+}
+
+// forAccount returns the IDs of the objects user@host holds orphaned grants on.
+func (o *orphanedGrants) forAccount(ctx context.Context, user, host string) map[string]struct{} {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	key := fmt.Sprintf("%s@%s", user, host)
+	ids, ok := o.ids[key]
+	if !ok {
+		ids = orphanedGrantIDs(ctx, o.client, user, host)
+		o.ids[key] = ids
+	}
+	return ids
+}
+
+// done forgets the orphaned grants of a principal once all of its grants are listed.
+func (o *orphanedGrants) done(user string, hosts []string) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	for _, host := range hosts {
+		delete(o.ids, fmt.Sprintf("%s@%s", user, host))
+	}
+}
+
+// orphanedGrantIDs returns the objects user@host holds grants on that no longer exist. Those grants are reported
+// as security findings of the user instead of grants on resources that are never listed. Failures are logged and
+// leave the grants as they are.
+func orphanedGrantIDs(ctx context.Context, c *client.Client, user, host string) map[string]struct{} {
+	ret, err := c.OrphanedGrantIDs(ctx, user, host)
 	if err != nil {
-		ctxzap.Extract(ctx).Error(
-			"unable to fetch to user for global grant. Ignoring grants",
+		ctxzap.Extract(ctx).Warn(
+			"unable to check for orphaned grants",
 			zap.Error(err),
 			zap.String("user", user),
 			zap.String("host", host),
 		)
 		return nil
 	}
+	return ret
+}
+
+// listGlobalgrants returns a map keyed by entitlement ID for granted global privileges. Privileges the server
+// did not declare as entitlements are skipped.
+func listGlobalGrants(
+	ctx context.Context,
+	resourceID *v2.ResourceId,
+	user, host string,
+	grantMap map[string]struct{},
+	serverPrivs *serverPrivileges,
+	sources *grantSources,
+	c *client.Client,
+) error {
+	u, err := c.GetUser(ctx, user, host)
+	if err != nil {
+		return fmt.Errorf("unable to read the global privileges of %s@%s from mysql.user: %w", user, host, err)
+	}
 
-	if c.IsVersion8() {
+	catalog := privilegesFor(c)
+	if caps := c.Capabilities(); (caps.GlobalGrants || caps.GlobalPriv) && sources.enabled(grantSourceDynamic) {
 		globalGrants, err := c.ListGlobalGrants(ctx, u.User, u.Host)
-		if err != nil {
+		if err != nil && !sources.skip(ctx, grantSourceDynamic, err) {
 			return err
 		}
 
-		var entitlementID string
 		for _, g := range globalGrants {
+			priv := catalog.entitlementID(g.Priv)
 			if g.WithGrant == "Y" {
-				entitlementID = fmt.Sprintf("%s:%s", strings.ToLower(g.Priv)+"_with_grant", resourceID.Resource)
-			} else {
-				entitlementID = fmt.Sprintf("%s:%s", strings.ToLower(g.Priv), resourceID.Resource)
+				priv += withGrantSuffix
 			}
-			grantMap[entitlementID] = struct{}{}
+			addGlobalGrant(ctx, resourceID, priv, grantMap, serverPrivs)
 		}
 	}
 
 	userPrivs := u.GetPrivs(ctx)
 	for priv := range userPrivs {
-		privKey := fmt.Sprintf("%s:%s", priv, resourceID.Resource)
-		grantMap[privKey] = struct{}{}
+		addGlobalGrant(ctx, resourceID, catalog.entitlementID(priv), grantMap, serverPrivs)
 	}
 
 	return nil
 }
 
-// listDatabaseGrants returns a map keyed by entitlement ID for granted global privileges.
+func addGlobalGrant(ctx context.Context, resourceID *v2.ResourceId, priv string, grantMap map[string]struct{}, serverPrivs *serverPrivileges) {
+	if !serverPrivs.isDeclared(ctx, priv) {
+		ctxzap.Extract(ctx).Debug(
+			"skipping grant for undeclared global privilege",
+			zap.String("privilege", priv),
+		)
+		return
+	}
+
+	grantMap[fmt.Sprintf("%s:%s", priv, resourceID.Resource)] = struct{}{}
+	if old, ok := oldEntitlementID(priv); ok {
+		grantMap[fmt.Sprintf("%s:%s", old, resourceID.Resource)] = struct{}{}
+	}
+}
+
+// listDatabaseGrants adds a page of granted database privileges to grantMap, keyed by entitlement ID.
 func listDatabaseGrants(
 	ctx context.Context,
 	user, host string,
+	page *client.GrantPage,
 	grantMap map[string]struct{},
 	skipDbs map[string]struct{},
 	c *client.Client,
-) error {
-	dbGrants, err := c.ListDatabaseGrants(ctx, user, host)
+) (string, error) {
+	dbGrants, next, err := c.ListDatabaseGrants(ctx, user, host, page)
 	if err != nil {
-		return err
+		return "", err
 	}
 
+	catalog := privilegesFor(c)
 	var entitlementID string
 	for _, g := range dbGrants {
 		if _, ok := skipDbs[g.Database]; ok {
 			continue
 		}
 		for priv := range g.GetPrivs(ctx) {
-			entitlementID = fmt.Sprintf("%s:%s", strings.ToLower(priv), g.Id)
+			entitlementID = fmt.Sprintf("%s:%s", catalog.entitlementID(priv), g.Id)
 			grantMap[entitlementID] = struct{}{}
 		}
 	}
 
-	return nil
+	return next, nil
 }
 
-// listDatabaseGrants returns a map keyed by entitlement ID for granted table privileges.
+// listTableGrants adds a page of granted table privileges to grantMap, keyed by entitlement ID.
 func listTableGrants(
 	ctx context.Context,
 	user, host string,
+	page *client.GrantPage,
 	grantMap map[string]struct{},
 	skipDbs map[string]struct{},
+	orphaned *orphanedGrants,
 	c *client.Client,
-) error {
-	tableGrants, err := c.ListTableGrants(ctx, user, host)
+) (string, error) {
+	tableGrants, next, err := c.ListTableGrants(ctx, user, host, page)
 	if err != nil {
-		return err
+		return "", err
 	}
 
+	orphans := orphaned.forAccount(ctx, user, host)
+	catalog := privilegesFor(c)
 	var entitlementID string
 	for _, g := range tableGrants {
 		if _, ok := skipDbs[g.Database]; ok {
 			continue
 		}
+		if _, ok := orphans[g.Id]; ok {
+			continue
+		}
 		for priv := range g.GetPrivs(ctx) {
-			entitlementID = fmt.Sprintf("%s:%s", strings.ToLower(priv), g.Id)
+			entitlementID = fmt.Sprintf("%s:%s", catalog.entitlementID(priv), g.Id)
 			grantMap[entitlementID] = struct{}{}
 		}
 	}
 
-	return nil
+	return next, nil
 }
 
-// listColumnGrants returns a map keyed by entitlement ID for granted column privileges.
+// listColumnGrants adds a page of granted column privileges to grantMap, keyed by entitlement ID. Privileges on
+// the columns of expanded tables are granted on the column resources, and the others on the table's column
+// entitlements, with their columns added to columns.
 func listColumnGrants(
 	ctx context.Context,
 	user, host string,
+	page *client.GrantPage,
 	grantMap map[string]struct{},
+	columns map[string][]string,
 	skipDbs map[string]struct{},
 	expandCols map[string]struct{},
+	orphaned *orphanedGrants,
 	c *client.Client,
-) error {
-	columnGrants, err := c.ListColumnGrants(ctx, user, host)
+) (string, error) {
+	columnGrants, next, err := c.ListColumnGrants(ctx, user, host, page)
 	if err != nil {
-		return err
+		return "", err
 	}
 
+	orphans := orphaned.forAccount(ctx, user, host)
+	catalog := privilegesFor(c)
 	var entitlementID string
 	for _, g := range columnGrants {
 		if _, ok := skipDbs[g.Database]; ok {
 			continue
 		}
+		if _, ok := orphans[g.Id]; ok {
+			continue
+		}
 
-		// grantID defaults to the table for columns. If the column's table is expanded, set the grantID to the column
-		grantID := g.TableID()
 		if _, ok := expandCols[fmt.Sprintf("%s.%s", g.Database, g.Table)]; ok {
-			grantID = g.Id
+			for priv := range g.GetPrivs(ctx) {
+				entitlementID = fmt.Sprintf("%s:%s", catalog.entitlementID(priv), g.Id)
+				grantMap[entitlementID] = struct{}{}
+			}
+			continue
 		}
 
 		for priv := range g.GetPrivs(ctx) {
-			entitlementID = fmt.Sprintf("%s:%s", strings.ToLower(priv), grantID)
+			entitlementID = fmt.Sprintf("%s:%s", columnEntitlementID(catalog.entitlementID(priv)), g.TableID())
 			grantMap[entitlementID] = struct{}{}
+			columns[entitlementID] = append(columns[entitlementID], g.Column)
 		}
 	}
 
-	return nil
+	return next, nil
 }
 
-// listProxyGrants returns a map keyed by entitlement ID for granted proxy privileges.
-func listProxyGrants(ctx context.Context, user, host string, grantMap map[string]struct{}, c *client.Client) error {
-	proxyGrants, err := c.ListProxyGrants(ctx, user, host)
+// listRoutineGrants adds a page of granted routine privileges to grantMap, keyed by entitlement ID.
+func listRoutineGrants(
+	ctx context.Context,
+	user, host string,
+	page *client.GrantPage,
+	grantMap map[string]struct{},
+	skipDbs map[string]struct{},
+	orphaned *orphanedGrants,
+	c *client.Client,
+) (string, error) {
+	routineGrants, next, err := c.ListRoutineGrants(ctx, user, host, page)
 	if err != nil {
-		ctxzap.Extract(ctx).Error(
-			"unable to fetch to proxy grants. ignoring",
-			zap.Error(err),
-			zap.String("user", user),
-			zap.String("host", host),
-		)
-		return nil
+		return "", err
+	}
+
+	orphans := orphaned.forAccount(ctx, user, host)
+	catalog := privilegesFor(c)
+	var entitlementID string
+	for _, g := range routineGrants {
+		if _, ok := skipDbs[g.Database]; ok {
+			continue
+		}
+		if _, ok := orphans[g.Id]; ok {
+			continue
+		}
+		for priv := range g.GetPrivs(ctx) {
+			entitlementID = fmt.Sprintf("%s:%s", catalog.entitlementID(priv), g.Id)
+			grantMap[entitlementID] = struct{}{}
+		}
+	}
+
+	return next, nil
+}
+
+// listProxyGrants adds a page of granted proxy privileges to grantMap, keyed by entitlement ID.
+func listProxyGrants(
+	ctx context.Context,
+	user, host string,
+	page *client.GrantPage,
+	grantMap map[string]struct{},
+	c *client.Client,
+) (string, error) {
+	proxyGrants, next, err := c.ListProxyGrants(ctx, user, host, page)
+	if err != nil {
+		return "", err
 	}
 
 	for _, g := range proxyGrants {
@@ -256,14 +541,20 @@ func listProxyGrants(ctx context.Context, user, host string, grantMap map[string
 		}
 	}
 
-	return nil
+	return next, nil
 }
 
-// listRoleGrants returns a map keyed by entitlement ID for granted role edges.
-func listRoleGrants(ctx context.Context, user, host string, grantMap map[string]struct{}, c *client.Client) error {
-	roleGrants, err := c.ListRoleGrants(ctx, user, host)
+// listRoleGrants adds a page of granted role edges to grantMap, keyed by entitlement ID.
+func listRoleGrants(
+	ctx context.Context,
+	user, host string,
+	page *client.GrantPage,
+	grantMap map[string]struct{},
+	c *client.Client,
+) (string, error) {
+	roleGrants, next, err := c.ListRoleGrants(ctx, user, host, page)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	for _, g := range roleGrants {
@@ -273,5 +564,5 @@ func listRoleGrants(ctx context.Context, user, host string, grantMap map[string]
 		}
 	}
 
-	return nil
+	return next, nil
 }
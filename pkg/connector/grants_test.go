@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextGrantPhase(t *testing.T) {
+	hosts := []string{"%", "10.0.%"}
+
+	// Every phase of every host is visited once, in order.
+	var visited []string
+	phase, host, ok := grantPhases[0], hosts[0], true
+	for ok {
+		visited = append(visited, phase+"@"+host)
+		phase, host, ok = nextGrantPhase(hosts, phase, host)
+	}
+	require.Len(t, visited, len(grantPhases)*len(hosts))
+	require.Equal(t, "global@%", visited[0])
+	require.Equal(t, "role@%", visited[len(grantPhases)-1])
+	require.Equal(t, "global@10.0.%", visited[len(grantPhases)])
+	require.Equal(t, "role@10.0.%", visited[len(visited)-1])
+
+	_, _, ok = nextGrantPhase(hosts, "unknown", "unknown")
+	require.False(t, ok)
+}
+
+func Test_principalHosts(t *testing.T) {
+	resource := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "user:app@10.0.%,localhost"}}
+
+	user, hosts, err := principalHosts(resource, false)
+	require.NoError(t, err)
+	require.Equal(t, "app", user)
+	require.Equal(t, []string{"10.0.%,localhost"}, hosts)
+
+	user, hosts, err = principalHosts(resource, true)
+	require.NoError(t, err)
+	require.Equal(t, "app", user)
+	require.Equal(t, []string{"10.0.%", "localhost"}, hosts)
+
+	_, _, err = principalHosts(&v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "user:app"}}, false)
+	require.Error(t, err)
+}
@@ -1,10 +1,14 @@
 package connector
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"unicode"
 
+	"github.com/conductorone/baton-mysql/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/crypto"
 )
@@ -53,3 +57,32 @@ func generateCredentials(credentialOptions *v2.CredentialOptions) (string, error
 
 	return "", errors.New("failed to generate a valid password after 20 attempts")
 }
+
+// isNotFound reports whether a lookup failed because the object does not exist.
+func isNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+// serverParentID returns parentResourceID, or the ID of the server resource when a targeted sync does not pass
+// the parent of a user, role or database.
+func serverParentID(ctx context.Context, c *client.Client, parentResourceID *v2.ResourceId) (*v2.ResourceId, error) {
+	if parentResourceID != nil {
+		return parentResourceID, nil
+	}
+
+	server, err := c.GetServerInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v2.ResourceId{ResourceType: resourceTypeServer.Id, Resource: server.ID}, nil
+}
+
+// databaseParentID returns parentResourceID, or the ID of the database containing a table or routine.
+func databaseParentID(database string, parentResourceID *v2.ResourceId) *v2.ResourceId {
+	if parentResourceID != nil {
+		return parentResourceID
+	}
+
+	return &v2.ResourceId{ResourceType: resourceTypeDatabase.Id, Resource: fmt.Sprintf("%s:%s", client.DatabaseType, database)}
+}
@@ -0,0 +1,310 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// privilegeKind classifies how MySQL models a privilege.
+type privilegeKind int
+
+const (
+	// staticPrivilege is built into the server and stored in the *_priv columns of the grant tables.
+	staticPrivilege privilegeKind = iota
+	// dynamicPrivilege is registered by the server or a plugin at runtime and stored in mysql.global_grants.
+	dynamicPrivilege
+	// accountPrivilege is held on another account rather than on an object, like role membership.
+	accountPrivilege
+)
+
+// privilegeDef maps an entitlement privilege ID to the keyword used in GRANT and REVOKE statements.
+type privilegeDef struct {
+	entitlementID string
+	keyword       string
+	kind          privilegeKind
+}
+
+// sqlPrivilege is a privilege resolved from an entitlement for a single GRANT or REVOKE.
+type sqlPrivilege struct {
+	*privilegeDef
+	withGrantOption bool
+}
+
+// privilegeCatalog translates between entitlement IDs and MySQL privilege names in both directions.
+type privilegeCatalog struct {
+	// levels holds the entitlement IDs, including _with_grant variants, that are valid for each resource type.
+	levels map[string]map[string]*privilegeDef
+	// byName indexes each privilege by its upper-cased keyword and entitlement ID.
+	byName map[string]*privilegeDef
+}
+
+// privilegeCatalogs holds the privilege catalog of each server flavor.
+var privilegeCatalogs map[client.Flavor]*privilegeCatalog
+
+// privilegesFor returns the privilege catalog for the server's flavor.
+func privilegesFor(c *client.Client) *privilegeCatalog {
+	return privilegeCatalogs[c.Capabilities().Flavor]
+}
+
+func newPrivilegeCatalog(byResourceType map[string][]*entitlementTemplate) *privilegeCatalog {
+	pc := &privilegeCatalog{
+		levels: make(map[string]map[string]*privilegeDef),
+		byName: make(map[string]*privilegeDef),
+	}
+
+	for rt, tmpls := range byResourceType {
+		pc.levels[rt] = make(map[string]*privilegeDef)
+		for _, t := range tmpls {
+			pc.levels[rt][t.ID] = pc.add(strings.TrimSuffix(t.ID, withGrantSuffix), t.kind, t.sqlKeyword)
+		}
+	}
+
+	return pc
+}
+
+// add registers a privilege, deriving its keyword from the ID unless one is given. Static privilege keywords
+// use spaces (CREATE TEMPORARY TABLES), while dynamic privilege keywords keep underscores (BACKUP_ADMIN).
+func (pc *privilegeCatalog) add(id string, kind privilegeKind, keyword string) *privilegeDef {
+	if def, ok := pc.byName[strings.ToUpper(id)]; ok {
+		return def
+	}
+
+	if keyword == "" {
+		keyword = strings.ToUpper(id)
+		if kind == staticPrivilege {
+			keyword = strings.ReplaceAll(keyword, "_", " ")
+		}
+	}
+
+	def := &privilegeDef{
+		entitlementID: id,
+		keyword:       keyword,
+		kind:          kind,
+	}
+	pc.byName[strings.ToUpper(id)] = def
+	pc.byName[keyword] = def
+
+	return def
+}
+
+// resolve returns the SQL privilege for an entitlement privilege ID on the given resource type.
+func (pc *privilegeCatalog) resolve(resourceTypeID string, entitlementPriv string) (*sqlPrivilege, error) {
+	def, ok := pc.levels[resourceTypeID][entitlementPriv]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a valid privilege for a %s", entitlementPriv, resourceTypeID)
+	}
+
+	return &sqlPrivilege{
+		privilegeDef:    def,
+		withGrantOption: strings.HasSuffix(entitlementPriv, withGrantSuffix),
+	}, nil
+}
+
+// entitlementID returns the entitlement privilege ID for a privilege name as MySQL reports it, such as
+// "Create View" from mysql.tables_priv or "BACKUP_ADMIN" from mysql.global_grants.
+func (pc *privilegeCatalog) entitlementID(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if def, ok := pc.byName[name]; ok {
+		return def.entitlementID
+	}
+
+	return strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+}
+
+// serverPrivileges is the set of global privileges declared on the server resource. The built-in catalog is
+// merged with SHOW PRIVILEGES and the dynamic privileges granted in mysql.global_grants, so entitlements only
+// exist for privileges the server actually knows about, including ones registered by plugins and components
+// and the MariaDB privileges that only exist in some releases.
+type serverPrivileges struct {
+	client *client.Client
+
+	mtx       sync.Mutex
+	loaded    bool
+	templates []*entitlementTemplate
+	declared  map[string]*privilegeDef
+}
+
+func newServerPrivileges(c *client.Client) *serverPrivileges {
+	return &serverPrivileges{
+		client: c,
+	}
+}
+
+// refresh discovers the server's privileges again. The server syncer calls it once per sync.
+func (sp *serverPrivileges) refresh(ctx context.Context) []*entitlementTemplate {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+
+	sp.load(ctx)
+	return sp.templates
+}
+
+// entitlementTemplates returns the server-scoped entitlement templates, discovering them if needed.
+func (sp *serverPrivileges) entitlementTemplates(ctx context.Context) []*entitlementTemplate {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+
+	if !sp.loaded {
+		sp.load(ctx)
+	}
+	return sp.templates
+}
+
+// resolve returns the SQL privilege for a server entitlement privilege ID.
+func (sp *serverPrivileges) resolve(ctx context.Context, entitlementPriv string) (*sqlPrivilege, error) {
+	sp.entitlementTemplates(ctx)
+
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+
+	def, ok := sp.declared[entitlementPriv]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a privilege declared by the server", entitlementPriv)
+	}
+
+	return &sqlPrivilege{
+		privilegeDef:    def,
+		withGrantOption: strings.HasSuffix(entitlementPriv, withGrantSuffix),
+	}, nil
+}
+
+// isDeclared reports whether the server resource has an entitlement for the privilege ID.
+func (sp *serverPrivileges) isDeclared(ctx context.Context, entitlementPriv string) bool {
+	_, err := sp.resolve(ctx, entitlementPriv)
+	return err == nil
+}
+
+// load must be called with the mutex held. Discovery failures fall back to the built-in catalog.
+func (sp *serverPrivileges) load(ctx context.Context) {
+	l := ctxzap.Extract(ctx)
+
+	discovered := make(map[string]*client.PrivilegeModel)
+	discoveryFailed := false
+
+	serverPrivs, err := sp.client.ListPrivileges(ctx)
+	if err != nil {
+		l.Warn("unable to list server privileges, using built-in privileges", zap.Error(err))
+		discoveryFailed = true
+	}
+	for _, p := range serverPrivs {
+		discovered[strings.ToUpper(strings.TrimSpace(p.Name))] = p
+	}
+
+	if sp.client.Capabilities().GlobalGrants {
+		granted, err := sp.client.ListGlobalGrantPrivileges(ctx)
+		if err != nil {
+			l.Warn("unable to list granted dynamic privileges", zap.Error(err))
+		}
+		for _, name := range granted {
+			key := strings.ToUpper(strings.TrimSpace(name))
+			if _, ok := discovered[key]; !ok {
+				discovered[key] = &client.PrivilegeModel{Name: name, Context: "Server Admin"}
+			}
+		}
+	}
+
+	sp.templates = nil
+	sp.declared = make(map[string]*privilegeDef)
+
+	catalog := privilegesFor(sp.client)
+	for _, t := range entitlementTemplatesFor(sp.client, resourceTypeServer.Id) {
+		p, err := catalog.resolve(resourceTypeServer.Id, t.ID)
+		if err != nil {
+			continue
+		}
+		_, ok := discovered[p.keyword]
+		if !ok && (p.kind == dynamicPrivilege || t.optional) && !discoveryFailed {
+			continue
+		}
+		sp.templates = append(sp.templates, t)
+		sp.declared[t.ID] = p.privilegeDef
+
+		if id, ok := oldEntitlementID(t.ID); ok {
+			sp.templates = append(sp.templates, oldEntitlementTemplate(t, id))
+			sp.declared[id] = p.privilegeDef
+		}
+	}
+
+	keys := make([]string, 0, len(discovered))
+	for key := range discovered {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p := discovered[key]
+		if _, ok := catalog.byName[key]; ok || key == "" || key == "USAGE" || !p.IsGlobal() {
+			continue
+		}
+
+		t := discoveredPrivilegeTemplate(p)
+		if _, ok := sp.declared[t.ID]; ok {
+			continue
+		}
+		def := &privilegeDef{
+			entitlementID: t.ID,
+			keyword:       t.sqlKeyword,
+			kind:          t.kind,
+		}
+		sp.templates = append(sp.templates, t)
+		sp.declared[t.ID] = def
+
+		if t.includeWithGrant {
+			grantEt := withGrantTemplate(t)
+			sp.templates = append(sp.templates, grantEt)
+			sp.declared[grantEt.ID] = def
+		}
+	}
+
+	sp.loaded = true
+}
+
+// discoveredPrivilegeTemplate builds a server entitlement template for a privilege missing from the built-in
+// catalog. MySQL reports dynamic privileges in upper case (TELEMETRY_LOG_ADMIN) and static ones as words.
+func discoveredPrivilegeTemplate(p *client.PrivilegeModel) *entitlementTemplate {
+	name := strings.TrimSpace(p.Name)
+
+	kind := staticPrivilege
+	if name == strings.ToUpper(name) {
+		kind = dynamicPrivilege
+	}
+
+	displayName := strings.ReplaceAll(strings.ToLower(name), "_", " ")
+	displayName = strings.ToUpper(displayName[:1]) + displayName[1:]
+
+	description := strings.TrimSpace(p.Comment)
+	if description == "" {
+		description = fmt.Sprintf("Enable the %s privilege", strings.ToUpper(name))
+	}
+
+	return &entitlementTemplate{
+		ID:               strings.ToLower(strings.ReplaceAll(name, " ", "_")),
+		resourceTypes:    globalScope,
+		includeWithGrant: kind == dynamicPrivilege,
+		kind:             kind,
+		sqlKeyword:       strings.ToUpper(name),
+		entitlement: v2.Entitlement{
+			DisplayName: displayName,
+			Description: description,
+			Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+		},
+	}
+}
+
+// entitlementPrivilege returns the privilege portion of an entitlement ID.
+func entitlementPrivilege(entitlementID string) string {
+	parts := strings.SplitN(entitlementID, ":", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+
+	return parts[1]
+}
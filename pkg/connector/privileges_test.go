@@ -0,0 +1,189 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_privilegeCatalog_resolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		resourceType  string
+		priv          string
+		wantKeyword   string
+		wantKind      privilegeKind
+		wantWithGrant bool
+		wantErr       bool
+	}{
+		{
+			name:         "static privilege uses spaces",
+			resourceType: resourceTypeServer.Id,
+			priv:         "create_temporary_tables",
+			wantKeyword:  "CREATE TEMPORARY TABLES",
+			wantKind:     staticPrivilege,
+		},
+		{
+			name:         "dynamic privilege keeps underscores",
+			resourceType: resourceTypeServer.Id,
+			priv:         "backup_admin",
+			wantKeyword:  "BACKUP_ADMIN",
+			wantKind:     dynamicPrivilege,
+		},
+		{
+			name:          "grantable dynamic privilege",
+			resourceType:  resourceTypeServer.Id,
+			priv:          "backup_admin_with_grant",
+			wantKeyword:   "BACKUP_ADMIN",
+			wantKind:      dynamicPrivilege,
+			wantWithGrant: true,
+		},
+		{
+			name:         "grant option on a table",
+			resourceType: resourceTypeTable.Id,
+			priv:         "grant",
+			wantKeyword:  "GRANT OPTION",
+			wantKind:     staticPrivilege,
+		},
+		{
+			name:         "routine privilege",
+			resourceType: resourceTypeRoutine.Id,
+			priv:         "alter_routine",
+			wantKeyword:  "ALTER ROUTINE",
+			wantKind:     staticPrivilege,
+		},
+		{
+			name:         "role membership",
+			resourceType: resourceTypeRole.Id,
+			priv:         "role_assignment",
+			wantKeyword:  "ROLE_ASSIGNMENT",
+			wantKind:     accountPrivilege,
+		},
+		{
+			name:         "dynamic privilege on a database",
+			resourceType: resourceTypeDatabase.Id,
+			priv:         "backup_admin",
+			wantErr:      true,
+		},
+		{
+			name:         "grant option on a column",
+			resourceType: resourceTypeColumn.Id,
+			priv:         "grant",
+			wantErr:      true,
+		},
+		{
+			name:         "global privilege on a column",
+			resourceType: resourceTypeColumn.Id,
+			priv:         "create_user",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := privilegeCatalogs[client.FlavorMySQL].resolve(tt.resourceType, tt.priv)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKeyword, got.keyword)
+			require.Equal(t, tt.wantKind, got.kind)
+			require.Equal(t, tt.wantWithGrant, got.withGrantOption)
+		})
+	}
+}
+
+func Test_privilegeCatalog_entitlementID(t *testing.T) {
+	tests := map[string]string{
+		"Create View":       "create_view",
+		"Create_View":       "create_view",
+		"Alter Routine":     "alter_routine",
+		"Grant":             "grant",
+		"GRANT OPTION":      "grant",
+		"show_databases":    "show_databases",
+		"BACKUP_ADMIN":      "backup_admin",
+		"NDB_STORED_USER":   "ndb_stored_user",
+		"TELEMETRY_LOG_ADM": "telemetry_log_adm",
+	}
+	for in, want := range tests {
+		require.Equal(t, want, privilegeCatalogs[client.FlavorMySQL].entitlementID(in), in)
+	}
+}
+
+func Test_entitlementPrivilege(t *testing.T) {
+	require.Equal(t, "create_view", entitlementPrivilege("entitlement:create_view:database:dev"))
+	require.Equal(t, "backup_admin_with_grant", entitlementPrivilege("entitlement:backup_admin_with_grant:server:db1"))
+	require.Equal(t, "", entitlementPrivilege("create_view"))
+}
+
+func Test_discoveredPrivilegeTemplate(t *testing.T) {
+	dynamic := discoveredPrivilegeTemplate(&client.PrivilegeModel{Name: "TELEMETRY_LOG_ADMIN", Context: "Server Admin"})
+	require.Equal(t, "telemetry_log_admin", dynamic.ID)
+	require.Equal(t, "TELEMETRY_LOG_ADMIN", dynamic.sqlKeyword)
+	require.Equal(t, dynamicPrivilege, dynamic.kind)
+	require.True(t, dynamic.includeWithGrant)
+	require.Equal(t, "Telemetry log admin", dynamic.entitlement.DisplayName)
+
+	static := discoveredPrivilegeTemplate(&client.PrivilegeModel{Name: "Slave monitor", Context: "Server Admin", Comment: "To use SHOW SLAVE STATUS"})
+	require.Equal(t, "slave_monitor", static.ID)
+	require.Equal(t, "SLAVE MONITOR", static.sqlKeyword)
+	require.Equal(t, staticPrivilege, static.kind)
+	require.False(t, static.includeWithGrant)
+	require.Equal(t, "To use SHOW SLAVE STATUS", static.entitlement.Description)
+}
+
+func Test_privilegeCatalog_mariaDB(t *testing.T) {
+	catalog := privilegeCatalogs[client.FlavorMariaDB]
+
+	p, err := catalog.resolve(resourceTypeServer.Id, "binlog_admin")
+	require.NoError(t, err)
+	require.Equal(t, "BINLOG ADMIN", p.keyword)
+	require.Equal(t, staticPrivilege, p.kind)
+
+	p, err = catalog.resolve(resourceTypeServer.Id, "read_only_admin")
+	require.NoError(t, err)
+	require.Equal(t, "READ_ONLY ADMIN", p.keyword)
+
+	_, err = catalog.resolve(resourceTypeServer.Id, "binlog_admin_with_grant")
+	require.Error(t, err)
+	_, err = catalog.resolve(resourceTypeServer.Id, "backup_admin")
+	require.Error(t, err)
+	_, err = catalog.resolve(resourceTypeServer.Id, "create_role")
+	require.Error(t, err)
+	_, err = catalog.resolve(resourceTypeUser.Id, "role_assignment")
+	require.Error(t, err)
+
+	p, err = catalog.resolve(resourceTypeRole.Id, "role_assignment_with_grant")
+	require.NoError(t, err)
+	require.True(t, p.withGrantOption)
+
+	require.Equal(t, "slave_monitor", catalog.entitlementID("SLAVE MONITOR"))
+	require.Equal(t, "read_only_admin", catalog.entitlementID("READ_ONLY ADMIN"))
+	require.Equal(t, "create_temporary_tables", catalog.entitlementID("Create temporary tables"))
+}
+
+func Test_oldEntitlementID(t *testing.T) {
+	old, ok := oldEntitlementID("ndb_stored_user")
+	require.True(t, ok)
+	require.Equal(t, "nbd_stored_user", old)
+
+	old, ok = oldEntitlementID("ndb_stored_user_with_grant")
+	require.True(t, ok)
+	require.Equal(t, "nbd_stored_user_with_grant", old)
+
+	_, ok = oldEntitlementID("backup_admin")
+	require.False(t, ok)
+
+	for _, tmpl := range entitlementsByFlavor[client.FlavorMySQL][resourceTypeServer.Id] {
+		if tmpl.ID != "ndb_stored_user" {
+			continue
+		}
+		alias := oldEntitlementTemplate(tmpl, "nbd_stored_user")
+		require.Equal(t, "nbd_stored_user", alias.ID)
+		require.Equal(t, dynamicPrivilege, alias.kind)
+		require.Equal(t, "NDB stored user (deprecated)", alias.entitlement.DisplayName)
+		return
+	}
+	t.Fatal("ndb_stored_user is not a server entitlement")
+}
@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// readOnlySyncer hides the Grant, Revoke, Delete and CreateAccount methods of a resource syncer, so the connector
+// doesn't advertise provisioning capabilities for its resource type.
+type readOnlySyncer struct {
+	connectorbuilder.ResourceSyncer
+}
+
+// readOnlyTargetedSyncer is a readOnlySyncer that keeps the Get method of a syncer, so its resource type still
+// supports targeted sync.
+type readOnlyTargetedSyncer struct {
+	connectorbuilder.ResourceTargetedSyncer
+}
+
+// newReadOnlySyncer hides the provisioning methods of a resource syncer, and keeps Get when it has one.
+func newReadOnlySyncer(s connectorbuilder.ResourceSyncer) connectorbuilder.ResourceSyncer {
+	if t, ok := s.(connectorbuilder.ResourceTargetedSyncer); ok {
+		return &readOnlyTargetedSyncer{t}
+	}
+	return &readOnlySyncer{s}
+}
+
+// readOnlyBuilder is the connector without its action manager.
+type readOnlyBuilder interface {
+	connectorbuilder.ConnectorBuilder
+	connectorbuilder.EventProviderV2
+	syncEnder
+}
+
+// readOnlyConnector hides RegisterActionManager, so a read-only connector offers no custom actions, whose only
+// purpose is revoking grants.
+type readOnlyConnector struct {
+	readOnlyBuilder
+}
+
+// writePrivileges are the privileges that change data, schemas, accounts or privileges. A read-only connector needs
+// none of them.
+var writePrivileges = map[string]struct{}{
+	"ALL":            {},
+	"ALL PRIVILEGES": {},
+	"INSERT":         {},
+	"UPDATE":         {},
+	"DELETE":         {},
+	"DELETE HISTORY": {},
+	"CREATE":         {},
+	"DROP":           {},
+	"ALTER":          {},
+	"INDEX":          {},
+	"CREATE USER":    {},
+	"CREATE ROLE":    {},
+	"DROP ROLE":      {},
+	"SUPER":          {},
+	"ROLE_ADMIN":     {},
+	"SET USER":       {},
+	"SET_USER_ID":    {},
+	"GRANT OPTION":   {},
+}
+
+// unneededWritePrivileges returns the write privileges in the connector's own grants, like INSERT ON shop.*.
+func unneededWritePrivileges(grants []*client.AccountStatement) []string {
+	var ret []string
+	for _, g := range grants {
+		level := fmt.Sprintf("%s.%s", g.Database, g.Object)
+		for _, p := range g.Privileges {
+			if _, ok := writePrivileges[strings.ToUpper(p)]; ok {
+				ret = append(ret, fmt.Sprintf("%s ON %s", p, level))
+			}
+		}
+		if g.GrantOption && len(g.Privileges) > 0 {
+			ret = append(ret, fmt.Sprintf("%s ON %s", client.GrantOptionPrivilege, level))
+		}
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// warnWritePrivileges logs a warning when the account the connector connects as holds write privileges, which a
+// read-only connector never uses.
+func warnWritePrivileges(ctx context.Context, grants []*client.AccountStatement) {
+	l := ctxzap.Extract(ctx)
+
+	privs := unneededWritePrivileges(grants)
+	if len(privs) == 0 {
+		return
+	}
+	l.Warn(
+		"the connector is read-only, but its account holds write privileges it doesn't need",
+		zap.Strings("privileges", privs),
+	)
+}
@@ -0,0 +1,77 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readOnlySyncer(t *testing.T) {
+	server := newServerSyncer(nil, nil)
+	user := newUserSyncer(nil, nil, nil, nil, false, nil, nil, nil)
+
+	var s connectorbuilder.ResourceSyncer = server
+	_, ok := s.(connectorbuilder.ResourceProvisioner)
+	require.True(t, ok)
+	s = user
+	_, ok = s.(connectorbuilder.AccountManager)
+	require.True(t, ok)
+	_, ok = s.(connectorbuilder.ResourceDeleter)
+	require.True(t, ok)
+
+	_, ok = s.(connectorbuilder.ResourceTargetedSyncer)
+	require.True(t, ok)
+
+	// Targeted sync is kept for the syncers that support it.
+	_, ok = newReadOnlySyncer(server).(connectorbuilder.ResourceTargetedSyncer)
+	require.False(t, ok)
+	_, ok = newReadOnlySyncer(user).(connectorbuilder.ResourceTargetedSyncer)
+	require.True(t, ok)
+
+	for _, s := range []connectorbuilder.ResourceSyncer{newReadOnlySyncer(server), newReadOnlySyncer(user)} {
+		_, ok = s.(connectorbuilder.ResourceProvisioner)
+		require.False(t, ok)
+		_, ok = s.(connectorbuilder.ResourceProvisionerV2)
+		require.False(t, ok)
+		_, ok = s.(connectorbuilder.ResourceDeleter)
+		require.False(t, ok)
+		_, ok = s.(connectorbuilder.AccountManager)
+		require.False(t, ok)
+	}
+
+	var c interface{} = &connectorImpl{}
+	_, ok = c.(connectorbuilder.RegisterActionManager)
+	require.True(t, ok)
+	c = &readOnlyConnector{&connectorImpl{}}
+	_, ok = c.(connectorbuilder.RegisterActionManager)
+	require.False(t, ok)
+	_, ok = c.(connectorbuilder.EventProviderV2)
+	require.True(t, ok)
+}
+
+func Test_unneededWritePrivileges(t *testing.T) {
+	var grants []*client.AccountStatement
+	for _, stmt := range []string{
+		"GRANT USAGE ON *.* TO `baton`@`%`",
+		"GRANT SELECT, CREATE USER ON *.* TO `baton`@`%` WITH GRANT OPTION",
+		"GRANT SELECT (`Host`, `User`) ON `mysql`.`db` TO `baton`@`%`",
+		"GRANT SELECT, INSERT ON `shop`.* TO `baton`@`%`",
+		"GRANT `reader`@`%` TO `baton`@`%` WITH ADMIN OPTION",
+	} {
+		g, ok := client.ParseAccountStatement(stmt, "")
+		require.True(t, ok, stmt)
+		grants = append(grants, g)
+	}
+
+	require.Equal(t, []string{
+		"CREATE USER ON *.*",
+		"GRANT OPTION ON *.*",
+		"INSERT ON shop.*",
+	}, unneededWritePrivileges(grants))
+
+	readOnly, ok := client.ParseAccountStatement("GRANT SELECT, SHOW VIEW ON *.* TO `baton`@`%`", "")
+	require.True(t, ok)
+	require.Empty(t, unneededWritePrivileges([]*client.AccountStatement{readOnly}))
+}
@@ -14,8 +14,11 @@ import (
 type roleSyncer struct {
 	resourceType *v2.ResourceType
 	client       *client.Client
+	privileges   *serverPrivileges
 	skipDbs      map[string]struct{}
 	expandCols   map[string]struct{}
+	sources      *grantSources
+	orphans      *orphanedGrants
 }
 
 func (s *roleSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -38,22 +41,59 @@ func (s *roleSyncer) List(
 
 	var ret []*v2.Resource
 	for _, u := range users {
-		var annos annotations.Annotations
-
-		ret = append(ret, &v2.Resource{
-			DisplayName: fmt.Sprintf("%s@%s", u.User, u.Host),
-			Id: &v2.ResourceId{
-				ResourceType: s.resourceType.Id,
-				Resource:     u.GetID(),
-			},
-			Annotations:      annos,
-			ParentResourceId: parentResourceID,
-		})
+		ret = append(ret, s.roleResource(u, parentResourceID))
 	}
 
 	return ret, nextPageToken, nil, nil
 }
 
+// Get returns a single role.
+func (s *roleSyncer) Get(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceId *v2.ResourceId,
+) (*v2.Resource, annotations.Annotations, error) {
+	role, hosts, err := principalHosts(&v2.Resource{Id: resourceId}, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.client.GetUser(ctx, role, hosts[0])
+	if isNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.UserType != client.RoleType {
+		return nil, nil, nil
+	}
+
+	parentResourceId, err = serverParentID(ctx, s.client, parentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.roleResource(u, parentResourceId), nil, nil
+}
+
+func (s *roleSyncer) roleResource(u *client.User, parentResourceID *v2.ResourceId) *v2.Resource {
+	// MariaDB roles have no host.
+	displayName := fmt.Sprintf("%s@%s", u.User, u.Host)
+	if u.Host == "" {
+		displayName = u.User
+	}
+
+	return &v2.Resource{
+		DisplayName: displayName,
+		Id: &v2.ResourceId{
+			ResourceType: s.resourceType.Id,
+			Resource:     u.GetID(),
+		},
+		ParentResourceId: parentResourceID,
+	}
+}
+
 func (s *roleSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	entitlements, err := getEntitlementsForResource(resource, s.client)
 	if err != nil {
@@ -64,20 +104,34 @@ func (s *roleSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pT
 }
 
 func (s *roleSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	grants, err := grantsForUserOrRole(ctx, s.client, resource, s.skipDbs, s.expandCols, false)
+	grants, nextPageToken, err := grantsForUserOrRole(ctx, s.client, s.privileges, resource, s.skipDbs, s.expandCols, false, s.sources, s.orphans, pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	annos, err := s.sources.annotations()
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	return grants, "", nil, nil
+	return grants, nextPageToken, annos, nil
 }
 
-func newRoleSyncer(c *client.Client, skipDbs map[string]struct{}, expandCols map[string]struct{}) *roleSyncer {
+func newRoleSyncer(
+	c *client.Client,
+	privileges *serverPrivileges,
+	skipDbs map[string]struct{},
+	expandCols map[string]struct{},
+	sources *grantSources,
+) *roleSyncer {
 	return &roleSyncer{
 		resourceType: resourceTypeRole,
 		client:       c,
+		privileges:   privileges,
 		skipDbs:      skipDbs,
 		expandCols:   expandCols,
+		sources:      sources,
+		orphans:      newOrphanedGrants(c),
 	}
 }
 
@@ -93,6 +147,10 @@ func (s *roleSyncer) Grant(ctx context.Context, principal *v2.Resource, entitlem
 	privilege := parts[1]
 	roleName := parts[3]
 
+	if _, err := privilegesFor(s.client).resolve(resourceTypeRole.Id, privilege); err != nil {
+		return nil, err
+	}
+
 	userSplit := strings.Split(principal.Id.Resource, ":")
 	if len(userSplit) != 2 {
 		return nil, fmt.Errorf("invalid principal ID: %s", principal.Id.Resource)
@@ -104,6 +162,13 @@ func (s *roleSyncer) Grant(ctx context.Context, principal *v2.Resource, entitlem
 		return nil, fmt.Errorf("failed to grant %s on role %s to user %s: %w", privilege, roleName, user, err)
 	}
 
+	if privilege == roleAssignmentPriv || privilege == roleAssignmentWithGrantPriv {
+		err = s.updateDefaultRole(ctx, roleName, user, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return nil, nil
 }
 
@@ -115,6 +180,10 @@ func (s *roleSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.A
 	privilege := parts[1]
 	roleName := parts[3]
 
+	if _, err := privilegesFor(s.client).resolve(resourceTypeRole.Id, privilege); err != nil {
+		return nil, err
+	}
+
 	userSplit := strings.Split(grant.Principal.Id.Resource, ":")
 	if len(userSplit) != 2 {
 		return nil, fmt.Errorf("invalid principal ID: %s", grant.Principal.Id.Resource)
@@ -126,5 +195,43 @@ func (s *roleSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.A
 		return nil, fmt.Errorf("failed to revoke %s on role %s from user %s: %w", privilege, roleName, user, err)
 	}
 
+	if privilege == roleAssignmentPriv || privilege == roleAssignmentWithGrantPriv {
+		err = s.updateDefaultRole(ctx, roleName, user, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return nil, nil
 }
+
+// updateDefaultRole keeps the MariaDB default role in step with role membership. MariaDB only activates a role at
+// login when it is the user's default role, so a user granted their first role gets it as the default, and the
+// default is cleared when that role is revoked. MySQL default roles are left alone.
+func (s *roleSyncer) updateDefaultRole(ctx context.Context, roleName string, user string, granted bool) error {
+	if s.client.Capabilities().Flavor != client.FlavorMariaDB {
+		return nil
+	}
+
+	userParts := strings.Split(user, "@")
+	if len(userParts) != 2 {
+		return fmt.Errorf("invalid user format: %s", user)
+	}
+	u, err := s.client.GetUser(ctx, userParts[0], userParts[1])
+	if err != nil {
+		return err
+	}
+
+	role := strings.TrimSuffix(roleName, "@")
+	switch {
+	case granted && u.DefaultRole == "":
+		err = s.client.SetDefaultRole(ctx, roleName, user)
+	case !granted && u.DefaultRole == role:
+		err = s.client.SetDefaultRole(ctx, "", user)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update default role of user %s: %w", user, err)
+	}
+
+	return nil
+}
@@ -36,19 +36,40 @@ func (s *routineSyncer) List(
 
 	var ret []*v2.Resource
 	for _, routineModel := range routines {
-		ret = append(ret, &v2.Resource{
-			DisplayName: fmt.Sprintf("%s.%s", routineModel.Database, routineModel.Name),
-			Id: &v2.ResourceId{
-				ResourceType: s.resourceType.Id,
-				Resource:     routineModel.ID,
-			},
-			ParentResourceId: parentResourceID,
-		})
+		ret = append(ret, s.routineResource(routineModel, parentResourceID))
 	}
 
 	return ret, nextPageToken, nil, nil
 }
 
+// Get returns a single stored procedure or function.
+func (s *routineSyncer) Get(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceId *v2.ResourceId,
+) (*v2.Resource, annotations.Annotations, error) {
+	routineModel, err := s.client.GetRoutine(ctx, resourceId)
+	if isNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.routineResource(routineModel, databaseParentID(routineModel.Database, parentResourceId)), nil, nil
+}
+
+func (s *routineSyncer) routineResource(routineModel *client.RoutineModel, parentResourceID *v2.ResourceId) *v2.Resource {
+	return &v2.Resource{
+		DisplayName: fmt.Sprintf("%s.%s", routineModel.Database, routineModel.Name),
+		Id: &v2.ResourceId{
+			ResourceType: s.resourceType.Id,
+			Resource:     routineModel.ID,
+		},
+		ParentResourceId: parentResourceID,
+	}
+}
+
 func (s *routineSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	entitlements, err := getEntitlementsForResource(resource, s.client)
 	if err != nil {
@@ -86,14 +107,9 @@ func (s *routineSyncer) Grant(ctx context.Context, principal *v2.Resource, entit
 		return nil, fmt.Errorf("unsupported resource kind in entitlement ID: %s", entitlement.Id)
 	}
 
-	var privilege string
-	switch strings.ToLower(rawPrivilege) {
-	case "execute":
-		privilege = "EXECUTE"
-	case "alter_routine":
-		privilege = "ALTER ROUTINE"
-	default:
-		return nil, fmt.Errorf("unsupported privilege for routine: %s", rawPrivilege)
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeRoutine.Id, rawPrivilege)
+	if err != nil {
+		return nil, err
 	}
 
 	schemaRoutine := strings.Split(fullRoutineName, ".")
@@ -109,9 +125,9 @@ func (s *routineSyncer) Grant(ctx context.Context, principal *v2.Resource, entit
 	}
 	user := userSplit[1]
 
-	err := s.client.GrantRoutinePrivilege(ctx, privilege, schema, routineName, user)
+	err = s.client.GrantRoutinePrivilege(ctx, privilege.keyword, schema, routineName, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to grant %s on %s.%s to %s: %w", privilege, schema, routineName, user, err)
+		return nil, fmt.Errorf("failed to grant %s on %s.%s to %s: %w", privilege.keyword, schema, routineName, user, err)
 	}
 
 	return nil, nil
@@ -130,14 +146,9 @@ func (s *routineSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotation
 		return nil, fmt.Errorf("unsupported resource kind in entitlement ID: %s", grant.Entitlement.Id)
 	}
 
-	var privilege string
-	switch strings.ToLower(rawPrivilege) {
-	case "execute":
-		privilege = "EXECUTE"
-	case "alter_routine":
-		privilege = "ALTER ROUTINE"
-	default:
-		return nil, fmt.Errorf("unsupported privilege for routine: %s", rawPrivilege)
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeRoutine.Id, rawPrivilege)
+	if err != nil {
+		return nil, err
 	}
 
 	schemaRoutine := strings.Split(fullRoutineName, ".")
@@ -153,9 +164,9 @@ func (s *routineSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotation
 	}
 	user := userSplit[1]
 
-	err := s.client.RevokeRoutinePrivilege(ctx, privilege, schema, routineName, user)
+	err = s.client.RevokeRoutinePrivilege(ctx, privilege.keyword, schema, routineName, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to revoke %s on %s.%s from %s: %w", privilege, schema, routineName, user, err)
+		return nil, fmt.Errorf("failed to revoke %s on %s.%s from %s: %w", privilege.keyword, schema, routineName, user, err)
 	}
 
 	return nil, nil
@@ -14,6 +14,7 @@ import (
 type serverSyncer struct {
 	resourceType *v2.ResourceType
 	client       *client.Client
+	privileges   *serverPrivileges
 }
 
 func (s *serverSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -34,7 +35,7 @@ func (s *serverSyncer) List(
 	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeDatabase.Id})
 	annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeUser.Id})
 
-	if s.client.IsVersion8() {
+	if s.client.Capabilities().Roles {
 		annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeRole.Id})
 	}
 
@@ -51,7 +52,7 @@ func (s *serverSyncer) List(
 }
 
 func (s *serverSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	entitlements, err := getEntitlementsForResource(resource, s.client)
+	entitlements, err := entitlementsFromTemplates(resource, s.client, s.privileges.refresh(ctx))
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -63,20 +64,24 @@ func (s *serverSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newServerSyncer(c *client.Client) *serverSyncer {
+func newServerSyncer(c *client.Client, privileges *serverPrivileges) *serverSyncer {
 	return &serverSyncer{
 		resourceType: resourceTypeServer,
 		client:       c,
+		privileges:   privileges,
 	}
 }
 
 func (s *serverSyncer) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
 	userResource := principal.Id.Resource
-	privilege := extractServerPrivilege(entitlement.Id)
+	privilege, err := s.privileges.resolve(ctx, entitlementPrivilege(entitlement.Id))
+	if err != nil {
+		return nil, err
+	}
 
 	user := strings.Split(userResource, ":")
 	userStr := user[1]
-	err := s.client.GrantServerPrivilege(ctx, userStr, privilege)
+	err = s.client.GrantServerPrivilege(ctx, userStr, privilege.keyword, privilege.withGrantOption)
 	if err != nil {
 		return nil, fmt.Errorf("grant failed: %w", err)
 	}
@@ -86,29 +91,17 @@ func (s *serverSyncer) Grant(ctx context.Context, principal *v2.Resource, entitl
 
 func (s *serverSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	userResource := grant.Principal.Id.Resource
-	privilege := extractServerPrivilege(grant.Entitlement.Id)
+	privilege, err := s.privileges.resolve(ctx, entitlementPrivilege(grant.Entitlement.Id))
+	if err != nil {
+		return nil, err
+	}
 
 	user := strings.Split(userResource, ":")
 	userStr := user[1]
-	err := s.client.RevokeServerPrivilege(ctx, userStr, privilege)
+	err = s.client.RevokeServerPrivilege(ctx, userStr, privilege.keyword, privilege.withGrantOption)
 	if err != nil {
 		return nil, fmt.Errorf("revoke failed: %w", err)
 	}
 
 	return nil, nil
 }
-
-func extractServerPrivilege(entitlementID string) string {
-	parts := strings.Split(entitlementID, ":")
-	if len(parts) < 3 {
-		return ""
-	}
-
-	privilegePart := parts[1]
-	priv := strings.ToUpper(strings.ReplaceAll(privilegePart, "_WITH_GRANT", ""))
-
-	if strings.Contains(privilegePart, "_with_grant") {
-		return priv + " WITH GRANT OPTION"
-	}
-	return priv
-}
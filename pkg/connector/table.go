@@ -35,24 +35,45 @@ func (s *tableSyncer) List(
 		return nil, "", nil, err
 	}
 
+	var ret []*v2.Resource
+	for _, tableModel := range tables {
+		ret = append(ret, s.tableResource(tableModel, parentResourceID))
+	}
+	return ret, nextPageToken, nil, nil
+}
+
+// Get returns a single table or view.
+func (s *tableSyncer) Get(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceId *v2.ResourceId,
+) (*v2.Resource, annotations.Annotations, error) {
+	tableModel, err := s.client.GetTable(ctx, resourceId)
+	if isNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.tableResource(tableModel, databaseParentID(tableModel.Database, parentResourceId)), nil, nil
+}
+
+func (s *tableSyncer) tableResource(tableModel *client.TableModel, parentResourceID *v2.ResourceId) *v2.Resource {
 	var annos annotations.Annotations
 	if len(s.expandCols) > 0 {
 		annos.Append(&v2.ChildResourceType{ResourceTypeId: resourceTypeColumn.Id})
 	}
 
-	var ret []*v2.Resource
-	for _, tableModel := range tables {
-		ret = append(ret, &v2.Resource{
-			DisplayName: fmt.Sprintf("%s.%s", tableModel.Database, tableModel.Name),
-			Id: &v2.ResourceId{
-				ResourceType: s.resourceType.Id,
-				Resource:     tableModel.ID,
-			},
-			Annotations:      annos,
-			ParentResourceId: parentResourceID,
-		})
+	return &v2.Resource{
+		DisplayName: fmt.Sprintf("%s.%s", tableModel.Database, tableModel.Name),
+		Id: &v2.ResourceId{
+			ResourceType: s.resourceType.Id,
+			Resource:     tableModel.ID,
+		},
+		Annotations:      annos,
+		ParentResourceId: parentResourceID,
 	}
-	return ret, nextPageToken, nil, nil
 }
 
 func (s *tableSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
@@ -60,6 +81,10 @@ func (s *tableSyncer) Entitlements(ctx context.Context, resource *v2.Resource, p
 	if err != nil {
 		return nil, "", nil, err
 	}
+	// The column privileges of expanded tables are entitlements of the column resources instead.
+	if _, ok := s.expandCols[tableName(resource)]; !ok {
+		entitlements = append(entitlements, columnEntitlements(resource, s.client)...)
+	}
 
 	return entitlements, "", nil, nil
 }
@@ -85,7 +110,14 @@ func (s *tableSyncer) Grant(ctx context.Context, principal *v2.Resource, entitle
 	if len(parts) != 4 {
 		return nil, fmt.Errorf("invalid entitlement ID: %s", entitlement.Id)
 	}
-	privilege := parts[1]
+	priv, columns := parts[1], false
+	if p, ok := columnPrivilege(priv); ok {
+		priv, columns = p, true
+	}
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeTable.Id, priv)
+	if err != nil {
+		return nil, err
+	}
 	tableID := parts[3]
 
 	userName := strings.Split(principal.Id.Resource, ":")
@@ -93,9 +125,13 @@ func (s *tableSyncer) Grant(ctx context.Context, principal *v2.Resource, entitle
 		return nil, fmt.Errorf("invalid principal ID: %s", principal.Id.Resource)
 	}
 
-	err := s.client.GrantTablePrivilege(ctx, tableID, userName[1], privilege)
+	if columns {
+		return nil, s.grantColumns(ctx, entitlement, tableID, userName[1], privilege)
+	}
+
+	err = s.client.GrantTablePrivilege(ctx, tableID, userName[1], privilege.keyword)
 	if err != nil {
-		return nil, fmt.Errorf("failed to grant %s on %s to %s: %w", privilege, tableID, principal.Id.Resource, err)
+		return nil, fmt.Errorf("failed to grant %s on %s to %s: %w", privilege.keyword, tableID, principal.Id.Resource, err)
 	}
 
 	return nil, nil
@@ -106,7 +142,14 @@ func (s *tableSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.
 	if len(parts) != 4 {
 		return nil, fmt.Errorf("invalid entitlement ID: %s", grant.Entitlement.Id)
 	}
-	privilege := parts[1]
+	priv, columns := parts[1], false
+	if p, ok := columnPrivilege(priv); ok {
+		priv, columns = p, true
+	}
+	privilege, err := privilegesFor(s.client).resolve(resourceTypeTable.Id, priv)
+	if err != nil {
+		return nil, err
+	}
 	tableID := parts[3]
 
 	userName := strings.Split(grant.Principal.Id.Resource, ":")
@@ -114,10 +157,62 @@ func (s *tableSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.
 		return nil, fmt.Errorf("invalid principal ID: %s", grant.Principal.Id.Resource)
 	}
 
-	err := s.client.RevokeTablePrivilege(ctx, tableID, userName[1], privilege)
+	if columns {
+		return nil, s.revokeColumns(ctx, tableID, userName[1], privilege)
+	}
+
+	err = s.client.RevokeTablePrivilege(ctx, tableID, userName[1], privilege.keyword)
 	if err != nil {
-		return nil, fmt.Errorf("failed to revoke %s on %s from %s: %w", privilege, tableID, grant.Principal.Id.Resource, err)
+		return nil, fmt.Errorf("failed to revoke %s on %s from %s: %w", privilege.keyword, tableID, grant.Principal.Id.Resource, err)
 	}
 
 	return nil, nil
 }
+
+// grantColumns grants a privilege on the columns of a table its column entitlement stands for: those that accounts
+// hold the privilege on in mysql.columns_priv.
+func (s *tableSyncer) grantColumns(ctx context.Context, entitlement *v2.Entitlement, table string, user string, privilege *sqlPrivilege) error {
+	columns, err := s.client.ListGrantedColumns(ctx, table, privilege.keyword)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("granting %s needs a column of %s that %s is granted on, and no account holds it on any", entitlement.Id, table, privilege.keyword)
+	}
+
+	for _, column := range columns {
+		err = s.client.GrantColumnPrivilege(ctx, table, column, user, privilege.keyword)
+		if err != nil {
+			return fmt.Errorf("failed to grant %s (%s) on %s to %s: %w", privilege.keyword, column, table, user, err)
+		}
+	}
+	return nil
+}
+
+// revokeColumns revokes a privilege from every column of a table it is granted on.
+func (s *tableSyncer) revokeColumns(ctx context.Context, table string, user string, privilege *sqlPrivilege) error {
+	userHost := strings.Split(user, "@")
+	if len(userHost) != 2 {
+		return fmt.Errorf("invalid user format: %s", user)
+	}
+	grants, _, err := s.client.ListColumnGrants(ctx, userHost[0], userHost[1], nil)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range grants {
+		if fmt.Sprintf("%s.%s", g.Database, g.Table) != table {
+			continue
+		}
+		for priv := range g.GetPrivs(ctx) {
+			if !strings.EqualFold(priv, privilege.keyword) {
+				continue
+			}
+			err = s.client.RevokeColumnPrivilege(ctx, table, g.Column, user, privilege.keyword)
+			if err != nil {
+				return fmt.Errorf("failed to revoke %s (%s) on %s from %s: %w", privilege.keyword, g.Column, table, user, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// privilegeStatements maps privileges to the performance_schema statement classes that exercise them. Privileges
+// that are not listed, like SELECT on a view or REFERENCES, cannot be told apart from the statement statistics.
+var privilegeStatements = map[string][]string{
+	"alter":              {"statement/sql/alter_table", "statement/sql/alter_db", "statement/sql/rename_table"},
+	"alter_routine":      {"statement/sql/alter_procedure", "statement/sql/alter_function", "statement/sql/drop_procedure", "statement/sql/drop_function"},
+	"create":             {"statement/sql/create_table", "statement/sql/create_db"},
+	"create_role":        {"statement/sql/create_role"},
+	"create_routine":     {"statement/sql/create_procedure", "statement/sql/create_spfunction"},
+	"create_tablespace":  {"statement/sql/alter_tablespace"},
+	"create_user":        {"statement/sql/create_user", "statement/sql/drop_user", "statement/sql/rename_user", "statement/sql/alter_user"},
+	"create_view":        {"statement/sql/create_view"},
+	"delete":             {"statement/sql/delete", "statement/sql/delete_multi"},
+	"drop":               {"statement/sql/drop_table", "statement/sql/drop_db", "statement/sql/drop_view", "statement/sql/truncate"},
+	"drop_role":          {"statement/sql/drop_role"},
+	"event":              {"statement/sql/create_event", "statement/sql/alter_event", "statement/sql/drop_event"},
+	"execute":            {"statement/sql/call_procedure"},
+	"file":               {"statement/sql/load"},
+	"grant":              {"statement/sql/grant", "statement/sql/revoke", "statement/sql/grant_roles", "statement/sql/revoke_roles"},
+	"index":              {"statement/sql/create_index", "statement/sql/drop_index"},
+	"insert":             {"statement/sql/insert", "statement/sql/insert_select", "statement/sql/replace", "statement/sql/replace_select"},
+	"lock_tables":        {"statement/sql/lock_tables"},
+	"process":            {"statement/sql/show_processlist"},
+	"reload":             {"statement/sql/flush", "statement/sql/reset"},
+	"replication_client": {"statement/sql/show_binlogs", "statement/sql/show_master_status", "statement/sql/show_slave_status"},
+	"replication_slave":  {"statement/com/Binlog Dump", "statement/com/Binlog Dump GTID"},
+	"select":             {"statement/sql/select"},
+	"show_databases":     {"statement/sql/show_databases"},
+	"shutdown":           {"statement/sql/shutdown"},
+	"trigger":            {"statement/sql/create_trigger", "statement/sql/drop_trigger"},
+	"update":             {"statement/sql/update", "statement/sql/update_multi"},
+}
+
+// statementUsage annotates grants with how often the grantee ran the statements that use each privilege, from
+// performance_schema.events_statements_summary_by_account_by_event_name. The statistics are per account and
+// statement class, so they show whether a privilege is exercised at all, not on which object.
+type statementUsage struct {
+	client *client.Client
+
+	mtx      sync.Mutex
+	loaded   bool
+	disabled bool
+	since    time.Time
+	counts   map[string]map[string]int64
+}
+
+func newStatementUsage(c *client.Client) *statementUsage {
+	return &statementUsage{
+		client: c,
+	}
+}
+
+// reset discards the loaded statistics so the next sync reads them again.
+func (u *statementUsage) reset() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.loaded = false
+	u.disabled = false
+	u.counts = nil
+}
+
+// load must be called with the mutex held. When performance_schema cannot be read, usage annotations are
+// disabled until the next reset.
+func (u *statementUsage) load(ctx context.Context) {
+	l := ctxzap.Extract(ctx)
+	u.loaded = true
+
+	usage, err := u.client.ListStatementUsage(ctx)
+	if err != nil {
+		if client.IsAccessDenied(err) {
+			l.Warn("access to performance_schema statement statistics was denied, skipping usage annotations", zap.Error(err))
+		} else {
+			l.Warn("unable to read performance_schema statement statistics, skipping usage annotations", zap.Error(err))
+		}
+		u.disabled = true
+		return
+	}
+
+	u.since = time.Time{}
+	if uptime, err := u.client.GetUptime(ctx); err == nil {
+		u.since = time.Now().Add(-uptime).UTC().Truncate(time.Second)
+	}
+
+	u.counts = make(map[string]map[string]int64)
+	for _, s := range usage {
+		account := fmt.Sprintf("%s@%s", s.User, s.Host)
+		if u.counts[account] == nil {
+			u.counts[account] = make(map[string]int64)
+		}
+		u.counts[account][s.EventName] += s.Count
+	}
+}
+
+// annotate adds usage metadata to the grants of user on the given hosts. Grants of privileges that no statement
+// class exercises since the statistics started are flagged as unused.
+func (u *statementUsage) annotate(ctx context.Context, user string, hosts []string, grants []*v2.Grant) error {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if !u.loaded {
+		u.load(ctx)
+	}
+	if u.disabled {
+		return nil
+	}
+
+	for _, g := range grants {
+		priv := strings.TrimSuffix(entitlementPrivilege(g.GetEntitlement().GetId()), withGrantSuffix)
+		events, ok := privilegeStatements[priv]
+		if !ok {
+			continue
+		}
+
+		var count int64
+		for _, host := range hosts {
+			for _, e := range events {
+				count += u.counts[fmt.Sprintf("%s@%s", user, host)][e]
+			}
+		}
+
+		statementClasses := make([]interface{}, 0, len(events))
+		for _, e := range events {
+			statementClasses = append(statementClasses, e)
+		}
+		metadata := map[string]interface{}{
+			"statement_count":   count,
+			"statement_classes": statementClasses,
+			"unused":            count == 0,
+		}
+		if !u.since.IsZero() {
+			metadata["observed_since"] = u.since.Format(time.RFC3339)
+		}
+
+		st, err := structpb.NewStruct(metadata)
+		if err != nil {
+			return err
+		}
+
+		annos := annotations.Annotations(g.Annotations)
+		annos.Update(&v2.GrantMetadata{Metadata: st})
+		g.Annotations = annos
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_statementUsage_annotate(t *testing.T) {
+	u := &statementUsage{
+		loaded: true,
+		since:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		counts: map[string]map[string]int64{
+			"app@10.0.0.1": {"statement/sql/select": 5},
+			"app@%":        {"statement/sql/select": 2, "statement/sql/update_multi": 1},
+		},
+	}
+
+	grant := func(priv string) *v2.Grant {
+		return &v2.Grant{Entitlement: &v2.Entitlement{Id: "entitlement:" + priv + ":table:db.t"}}
+	}
+	selectGrant := grant("select")
+	updateGrant := grant("update_with_grant")
+	dropGrant := grant("drop")
+	showViewGrant := grant("show_view")
+
+	err := u.annotate(context.Background(), "app", []string{"10.0.0.1", "%"}, []*v2.Grant{selectGrant, updateGrant, dropGrant, showViewGrant})
+	require.NoError(t, err)
+
+	metadata := func(g *v2.Grant) map[string]interface{} {
+		md := &v2.GrantMetadata{}
+		annos := annotations.Annotations(g.Annotations)
+		ok, err := annos.Pick(md)
+		require.NoError(t, err)
+		require.True(t, ok)
+		return md.Metadata.AsMap()
+	}
+
+	md := metadata(selectGrant)
+	require.Equal(t, float64(7), md["statement_count"])
+	require.Equal(t, false, md["unused"])
+	require.Equal(t, "2024-01-02T03:04:05Z", md["observed_since"])
+
+	require.Equal(t, float64(1), metadata(updateGrant)["statement_count"])
+
+	md = metadata(dropGrant)
+	require.Equal(t, float64(0), md["statement_count"])
+	require.Equal(t, true, md["unused"])
+
+	require.Empty(t, showViewGrant.Annotations)
+}
+
+func Test_statementUsage_disabled(t *testing.T) {
+	u := &statementUsage{loaded: true, disabled: true}
+	g := &v2.Grant{Entitlement: &v2.Entitlement{Id: "entitlement:drop:table:db.t"}}
+
+	require.NoError(t, u.annotate(context.Background(), "app", []string{"%"}, []*v2.Grant{g}))
+	require.Empty(t, g.Annotations)
+}
@@ -11,14 +11,22 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 type userSyncer struct {
 	resourceType  *v2.ResourceType
 	client        *client.Client
+	privileges    *serverPrivileges
 	skipDbs       map[string]struct{}
 	expandCols    map[string]struct{}
 	collapseUsers bool
+	usage         *statementUsage
+	activity      *accountActivity
+	findings      *securityFindings
+	orphans       *orphanedGrants
+	sources       *grantSources
 }
 
 func (s *userSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -34,6 +42,16 @@ func (s *userSyncer) List(
 		return nil, "", nil, nil
 	}
 
+	if pToken.Token == "" {
+		if s.usage != nil {
+			s.usage.reset()
+		}
+		if s.activity != nil {
+			s.activity.reset()
+		}
+		s.findings.reset()
+	}
+
 	users, nextPageToken, err := s.client.ListUsers(ctx, s.resourceType.Id, &client.Pager{Token: pToken.Token, Size: pToken.Size}, s.collapseUsers)
 	if err != nil {
 		return nil, "", nil, err
@@ -41,37 +59,146 @@ func (s *userSyncer) List(
 
 	var ret []*v2.Resource
 	for _, u := range users {
-		var annos annotations.Annotations
-
-		ut, err := rs.NewUserTrait(
-			rs.WithUserProfile(map[string]interface{}{
-				"user":       u.User,
-				"host":       u.Host,
-				"first_name": fmt.Sprintf("%s@%s", u.User, u.Host),
-				"user_id":    fmt.Sprintf("%s@%s", u.User, u.Host),
-			}),
-			rs.WithUserLogin(u.User),
-			rs.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
-		)
+		findings := s.findings.forUser(ctx, u.User, s.userHosts(u))
+		r, err := s.userResource(ctx, u, parentResourceID, findings)
 		if err != nil {
 			return nil, "", nil, err
 		}
-		annos.Update(ut)
-
-		ret = append(ret, &v2.Resource{
-			DisplayName: fmt.Sprintf("%s@%s", u.User, u.Host),
-			Id: &v2.ResourceId{
-				ResourceType: s.resourceType.Id,
-				Resource:     u.GetID(),
-			},
-			Annotations:      annos,
-			ParentResourceId: parentResourceID,
-		})
+		ret = append(ret, r)
 	}
 
 	return ret, nextPageToken, nil, nil
 }
 
+// Get returns a single user, so the user can be refreshed after a provisioning action without a full sync.
+func (s *userSyncer) Get(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceId *v2.ResourceId,
+) (*v2.Resource, annotations.Annotations, error) {
+	user, hosts, err := principalHosts(&v2.Resource{Id: resourceId}, s.collapseUsers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var u *client.User
+	if s.collapseUsers {
+		u, err = s.client.GetCollapsedUser(ctx, user)
+	} else {
+		u, err = s.client.GetUser(ctx, user, hosts[0])
+	}
+	if isNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if u.UserType != client.UserType {
+		return nil, nil, nil
+	}
+
+	parentResourceId, err = serverParentID(ctx, s.client, parentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := s.client.LoadUserAuditData(ctx, u.User)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("unable to read audit data, skipping security findings", zap.Error(err))
+	}
+
+	r, err := s.userResource(ctx, u, parentResourceId, findingTypes(ctx, data, u.User, s.userHosts(u)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, nil, nil
+}
+
+// userHosts returns the hosts of a listed user, which are several for a collapsed user.
+func (s *userSyncer) userHosts(u *client.User) []string {
+	if s.collapseUsers {
+		return strings.Split(u.Host, ",")
+	}
+	return []string{u.Host}
+}
+
+// userResource builds the resource of a listed user, with its profile, status and security finding types.
+func (s *userSyncer) userResource(
+	ctx context.Context,
+	u *client.User,
+	parentResourceID *v2.ResourceId,
+	findings []interface{},
+) (*v2.Resource, error) {
+	var annos annotations.Annotations
+
+	profile := map[string]interface{}{
+		"user":       u.User,
+		"host":       u.Host,
+		"first_name": fmt.Sprintf("%s@%s", u.User, u.Host),
+		"user_id":    fmt.Sprintf("%s@%s", u.User, u.Host),
+	}
+	if u.DefaultRole != "" {
+		profile["default_role"] = u.DefaultRole
+	}
+	if s.collapseUsers {
+		// The server tries the hosts of a collapsed user from most to least specific.
+		var precedence []interface{}
+		for _, h := range client.SortHosts(strings.Split(u.Host, ",")) {
+			precedence = append(precedence, h)
+		}
+		profile["host_precedence"] = precedence
+	}
+	if u.Plugin != "" {
+		profile["auth_plugin"] = u.Plugin
+	}
+
+	if len(findings) > 0 {
+		profile["security_findings"] = findings
+	}
+
+	status := v2.UserTrait_Status_STATUS_ENABLED
+	if u.IsLocked() {
+		status = v2.UserTrait_Status_STATUS_DISABLED
+		profile["account_locked"] = true
+	}
+
+	traitOpts := []rs.UserTraitOption{
+		rs.WithUserLogin(u.User),
+		rs.WithStatus(status),
+	}
+
+	if s.activity != nil {
+		if ua := s.activity.forUser(ctx, u.User, s.userHosts(u)); ua != nil {
+			profile["current_connections"] = ua.currentConnections
+			profile["total_connections"] = ua.totalConnections
+			profile["stale"] = ua.stale
+			if ua.failedLogins > 0 {
+				profile["failed_login_attempts"] = ua.failedLogins
+			}
+			if ua.lastLogin != nil {
+				traitOpts = append(traitOpts, rs.WithLastLogin(*ua.lastLogin))
+			}
+		}
+	}
+
+	ut, err := rs.NewUserTrait(append(traitOpts, rs.WithUserProfile(profile))...)
+	if err != nil {
+		return nil, err
+	}
+	annos.Update(ut)
+
+	return &v2.Resource{
+		DisplayName: fmt.Sprintf("%s@%s", u.User, u.Host),
+		Id: &v2.ResourceId{
+			ResourceType: s.resourceType.Id,
+			Resource:     u.GetID(),
+		},
+		Annotations:      annos,
+		ParentResourceId: parentResourceID,
+	}, nil
+}
+
 func (s *userSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	entitlements, err := getEntitlementsForResource(resource, s.client)
 	if err != nil {
@@ -82,21 +209,63 @@ func (s *userSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pT
 }
 
 func (s *userSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	grants, err := grantsForUserOrRole(ctx, s.client, resource, s.skipDbs, s.expandCols, s.collapseUsers)
+	grants, nextPageToken, err := grantsForUserOrRole(
+		ctx,
+		s.client,
+		s.privileges,
+		resource,
+		s.skipDbs,
+		s.expandCols,
+		s.collapseUsers,
+		s.sources,
+		s.orphans,
+		pToken,
+	)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if s.usage != nil {
+		user, hosts, err := principalHosts(resource, s.collapseUsers)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		err = s.usage.annotate(ctx, user, hosts, grants)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	annos, err := s.sources.annotations()
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	return grants, "", nil, nil
+	return grants, nextPageToken, annos, nil
 }
 
-func newUserSyncer(c *client.Client, skipDbs map[string]struct{}, expandCols map[string]struct{}, collapseUsers bool) *userSyncer {
+func newUserSyncer(
+	c *client.Client,
+	privileges *serverPrivileges,
+	skipDbs map[string]struct{},
+	expandCols map[string]struct{},
+	collapseUsers bool,
+	usage *statementUsage,
+	activity *accountActivity,
+	sources *grantSources,
+) *userSyncer {
 	return &userSyncer{
 		resourceType:  resourceTypeUser,
 		client:        c,
+		privileges:    privileges,
 		skipDbs:       skipDbs,
 		expandCols:    expandCols,
 		collapseUsers: collapseUsers,
+		usage:         usage,
+		activity:      activity,
+		findings:      newSecurityFindings(c),
+		orphans:       newOrphanedGrants(c),
+		sources:       sources,
 	}
 }
 
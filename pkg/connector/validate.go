@@ -0,0 +1,218 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// feature is a part of the sync and the grants it reads with. The sync fails without a required feature, and
+// skips an optional one.
+type feature struct {
+	name     string
+	optional bool
+	grants   []client.GrantRequirement
+}
+
+// selectColumns returns the requirement to select columns from a table in the mysql schema.
+func selectColumns(table string, columns ...string) client.GrantRequirement {
+	return client.GrantRequirement{Privilege: "SELECT", Database: "mysql", Table: table, Columns: columns}
+}
+
+// userColumns are the mysql.user columns read on every server.
+var userColumns = []string{
+	"Host", "User", "Select_priv", "Insert_priv", "Update_priv", "Delete_priv", "Create_priv", "Drop_priv",
+	"Reload_priv", "Shutdown_priv", "Process_priv", "References_priv", "Index_priv", "Alter_priv", "Show_db_priv",
+	"Super_priv", "Create_tmp_table_priv", "Lock_tables_priv", "Execute_priv", "Repl_slave_priv", "Repl_client_priv",
+	"Create_view_priv", "Show_view_priv", "Create_routine_priv", "Alter_routine_priv", "Create_user_priv", "Event_priv",
+	"Trigger_priv", "Create_tablespace_priv", "File_priv", "Grant_priv", "authentication_string", "plugin",
+}
+
+// dbPrivColumns are the privilege columns of mysql.db.
+var dbPrivColumns = []string{
+	"Host", "User", "Db", "Select_priv", "Insert_priv", "Update_priv", "Delete_priv", "Create_priv", "Drop_priv",
+	"Grant_priv", "References_priv", "Index_priv", "Alter_priv", "Create_tmp_table_priv", "Lock_tables_priv",
+	"Execute_priv", "Create_view_priv", "Show_view_priv", "Create_routine_priv", "Alter_routine_priv", "Event_priv",
+	"Trigger_priv",
+}
+
+// features returns the features the connector is configured to use on this server, with the grants listed in the
+// README for each. The required grant sources make up the sync, and each optional grant source that isn't turned
+// off is an optional feature.
+func (c *connectorImpl) features() []*feature {
+	caps := c.client.Capabilities()
+	mariaDB := caps.Flavor == client.FlavorMariaDB
+
+	user := append([]string{}, userColumns...)
+	switch {
+	case mariaDB:
+		user = append(user, "is_role", "default_role")
+	case caps.Roles:
+		user = append(user, "Create_role_priv", "Drop_role_priv", "account_locked")
+	default:
+		user = append(user, "account_locked")
+	}
+
+	ret := []*feature{{
+		name: "sync",
+		grants: []client.GrantRequirement{
+			selectColumns("user", user...),
+			selectColumns("db", dbPrivColumns...),
+			selectColumns("tables_priv", "Host", "User", "Db", "Table_priv", "Table_name"),
+		},
+	}}
+
+	sources := make(map[string][]client.GrantRequirement)
+	switch {
+	case caps.GlobalPriv:
+		sources[grantSourceDynamic] = []client.GrantRequirement{selectColumns("global_priv", "Host", "User", "Priv")}
+	case caps.GlobalGrants:
+		sources[grantSourceDynamic] = []client.GrantRequirement{
+			selectColumns("global_grants", "USER", "HOST", "PRIV", "WITH_GRANT_OPTION"),
+		}
+	}
+	sources[grantSourceColumn] = []client.GrantRequirement{
+		selectColumns("columns_priv", "Host", "User", "Db", "Column_name", "Column_priv", "Table_name"),
+	}
+	sources[grantSourceRoutine] = []client.GrantRequirement{
+		selectColumns("procs_priv", "Host", "User", "Db", "Routine_name", "Routine_type", "Proc_priv"),
+	}
+	sources[grantSourceProxy] = []client.GrantRequirement{
+		selectColumns("proxies_priv", "Host", "User", "Proxied_host", "Proxied_user", "With_grant"),
+	}
+	if caps.Roles {
+		if mariaDB {
+			sources[grantSourceRole] = []client.GrantRequirement{
+				selectColumns("roles_mapping", "Host", "User", "Role", "Admin_option"),
+			}
+		} else {
+			sources[grantSourceRole] = []client.GrantRequirement{
+				selectColumns("role_edges", "FROM_HOST", "FROM_USER", "TO_HOST", "TO_USER", "WITH_ADMIN_OPTION"),
+			}
+		}
+		if caps.DefaultRoles {
+			sources[grantSourceRole] = append(sources[grantSourceRole],
+				selectColumns("default_roles", "DEFAULT_ROLE_HOST", "DEFAULT_ROLE_USER"))
+		}
+	}
+	for _, source := range []string{grantSourceDynamic, grantSourceColumn, grantSourceRoutine, grantSourceProxy, grantSourceRole} {
+		grants, ok := sources[source]
+		if !ok || !c.grantSources.enabled(source) {
+			continue
+		}
+		ret = append(ret, &feature{name: fmt.Sprintf("%s grants", source), optional: true, grants: grants})
+	}
+
+	if c.usage != nil {
+		ret = append(ret, &feature{
+			name:     "usage stats",
+			optional: true,
+			grants: []client.GrantRequirement{
+				{Privilege: "SELECT", Database: "performance_schema", Table: "events_statements_summary_by_account_by_event_name"},
+			},
+		})
+	}
+
+	if c.activity != nil {
+		ret = append(ret,
+			&feature{
+				name:     "account activity",
+				optional: true,
+				grants: []client.GrantRequirement{
+					{Privilege: "SELECT", Database: "performance_schema", Table: "accounts"},
+				},
+			},
+			&feature{
+				name:     "last login",
+				optional: true,
+				grants: []client.GrantRequirement{
+					selectColumns("general_log", "user_host", "event_time", "command_type"),
+				},
+			},
+		)
+	}
+
+	if c.binlogSource == binlogSourceServer {
+		ret = append(ret, &feature{
+			name:     "binary log events",
+			optional: true,
+			grants: []client.GrantRequirement{
+				{Privilege: "REPLICATION SLAVE"},
+			},
+		})
+	}
+
+	return ret
+}
+
+// missingGrants returns the grants each feature is missing, keyed by feature name. Features that have every grant
+// they need are left out.
+func missingGrants(grants []*client.AccountStatement, features []*feature) map[string][]string {
+	ret := make(map[string][]string)
+	for _, f := range features {
+		for _, req := range f.grants {
+			if missing := client.MissingGrant(grants, req); missing != nil {
+				ret[f.name] = append(ret[f.name], missing.String())
+			}
+		}
+	}
+	return ret
+}
+
+// hasRoleGrants reports whether roles are granted to the account. SHOW GRANTS doesn't list the privileges of roles,
+// so privileges that look missing may be held through them.
+func hasRoleGrants(grants []*client.AccountStatement) bool {
+	for _, g := range grants {
+		if g.Verb == "GRANT" && len(g.Roles) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGrants logs a report of the grants missing for each feature, and which optional features will not be
+// available, and returns an error when required features are missing grants. When the account holds roles, missing
+// required grants are only logged.
+func (c *connectorImpl) checkGrants(ctx context.Context, grants []*client.AccountStatement) error {
+	l := ctxzap.Extract(ctx)
+
+	features := c.features()
+	missing := missingGrants(grants, features)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	viaRoles := hasRoleGrants(grants)
+	var required []string
+	for _, f := range features {
+		privs, ok := missing[f.name]
+		if !ok {
+			continue
+		}
+		if f.optional {
+			l.Warn(
+				"the connector's account is missing grants for an optional feature, which will not be available",
+				zap.String("feature", f.name),
+				zap.Strings("missing", privs),
+				zap.Bool("has_roles", viaRoles),
+			)
+			continue
+		}
+		l.Warn(
+			"the connector's account is missing grants the sync needs",
+			zap.String("feature", f.name),
+			zap.Strings("missing", privs),
+			zap.Bool("has_roles", viaRoles),
+		)
+		required = append(required, fmt.Sprintf("%s: %s", f.name, strings.Join(privs, ", ")))
+	}
+
+	if len(required) == 0 || viaRoles {
+		return nil
+	}
+	return fmt.Errorf("baton-mysql: the connector's account is missing grants the sync needs (%s)", strings.Join(required, "; "))
+}
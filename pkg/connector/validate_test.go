@@ -0,0 +1,54 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/conductorone/baton-mysql/pkg/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_missingGrants(t *testing.T) {
+	var grants []*client.AccountStatement
+	for _, stmt := range []string{
+		"GRANT USAGE ON *.* TO `baton`@`%`",
+		"GRANT SELECT (`Host`, `User`, `Db`) ON `mysql`.`db` TO `baton`@`%`",
+		"GRANT SELECT ON `mysql`.`user` TO `baton`@`%`",
+	} {
+		g, ok := client.ParseAccountStatement(stmt, "")
+		require.True(t, ok, stmt)
+		grants = append(grants, g)
+	}
+
+	features := []*feature{
+		{
+			name: "sync",
+			grants: []client.GrantRequirement{
+				selectColumns("user", "Host", "User"),
+				selectColumns("db", "Host", "User", "Db", "Select_priv"),
+			},
+		},
+		{
+			name:     "proxy grants",
+			optional: true,
+			grants: []client.GrantRequirement{
+				selectColumns("proxies_priv", "Host", "User"),
+			},
+		},
+		{
+			name:     "binary log events",
+			optional: true,
+			grants:   []client.GrantRequirement{{Privilege: "REPLICATION SLAVE"}},
+		},
+	}
+
+	require.Equal(t, map[string][]string{
+		"sync":              {"SELECT (Select_priv) ON mysql.db"},
+		"proxy grants":      {"SELECT (Host, User) ON mysql.proxies_priv"},
+		"binary log events": {"REPLICATION SLAVE ON *.*"},
+	}, missingGrants(grants, features))
+	require.False(t, hasRoleGrants(grants))
+
+	role, ok := client.ParseAccountStatement("GRANT `auditor`@`%` TO `baton`@`%`", "")
+	require.True(t, ok)
+	require.True(t, hasRoleGrants(append(grants, role)))
+}